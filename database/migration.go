@@ -3,17 +3,65 @@ package database
 import (
 	"fmt"
 	"project/models"
+	"project/pkg/money"
 	"project/pkg/mysql"
+	"time"
 )
 
 // Jika aplikasi berjalan maka auto migration akan berjalan
 func RunMigration() {
+	// kolom email_verified_at belum ada berarti database dibuat sebelum ada verifikasi email
+	verificationAdded := !mysql.DB.Migrator().HasColumn(&models.User{}, "email_verified_at")
+	// kolom user_id di riwayat booking belum ada berarti pemilik booking hanya tersimpan di transaction
+	eventOwnerAdded := !mysql.DB.Migrator().HasColumn(&models.TransactionEvent{}, "user_id")
+	// kolom seats_reserved belum ada berarti kuota trip hanya dikurangi saat booking lunas
+	reservationAdded := !mysql.DB.Migrator().HasColumn(&models.Transaction{}, "seats_reserved")
+
+	// email user dibuat unik. jika masih ada email ganda index tidak bisa dibuat, akun tersebut harus digabung atau
+	// diganti emailnya oleh admin terlebih dahulu
+	if mysql.DB.Migrator().HasTable(&models.User{}) && !mysql.DB.Migrator().HasIndex(&models.User{}, "Email") {
+		var duplicates []string
+		err := mysql.DB.Model(&models.User{}).Group("email").Having("COUNT(*) > 1").Pluck("email", &duplicates).Error
+		if err != nil {
+			fmt.Println(err)
+			panic("Migration failed")
+		}
+		if len(duplicates) > 0 {
+			fmt.Println("duplicate user emails:", duplicates)
+			panic("Migration failed")
+		}
+	}
+
 	// koneksi database akan melakukan auto migrasi struct/models ke dalam database mysql
 	err := mysql.DB.AutoMigrate( // panggil mysql lalu DB(pkg/mysql) lalu panggil function AutoMigrate()
 		&models.User{},
 		&models.Trip{},
 		&models.Country{},
 		&models.Transaction{},
+		&models.PasswordReset{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.Session{},
+		&models.APIKey{},
+		&models.UserIdentity{},
+		&models.AuditLog{},
+		&models.Traveler{},
+		&models.TransactionTraveler{},
+		&models.TransactionItem{},
+		&models.PromoCode{},
+		&models.ExchangeRate{},
+		&models.TaxRule{},
+		&models.Invoice{},
+		&models.InvoiceSequence{},
+		&models.PricingRule{},
+		&models.AddOn{},
+		&models.Document{},
+		&models.DocumentAccessLog{},
+		&models.Wishlist{},
+		&models.WaitlistEntry{},
+		&models.CheckIn{},
+		&models.Reschedule{},
+		&models.TransactionEvent{},
 	)
 	// jika ada error maka panggil panic
 	if err != nil {
@@ -21,5 +69,111 @@ func RunMigration() {
 		panic("Migration failed")
 	}
 
+	// dulu UpdateUser menyimpan password tanpa hash. password tersebut dihapus dan user wajib reset password
+	err = mysql.DB.Model(&models.User{}).Where("password <> '' AND password NOT LIKE ?", "$2%").Updates(map[string]interface{}{
+		"password":                "",
+		"password_reset_required": true,
+	}).Error
+	if err != nil {
+		fmt.Println(err)
+		panic("Migration failed")
+	}
+
+	// user yang mendaftar sebelum ada verifikasi email dianggap sudah terverifikasi agar tidak terblokir.
+	// admin selalu dianggap terverifikasi karena hanya bisa didaftarkan oleh admin lain
+	verified := mysql.DB.Model(&models.User{}).Where("email_verified_at IS NULL")
+	if !verificationAdded {
+		verified = verified.Where("role = ?", "admin")
+	}
+	err = verified.Update("email_verified_at", time.Now()).Error
+	if err != nil {
+		fmt.Println(err)
+		panic("Migration failed")
+	}
+
+	// dulu harga trip dan total transaksi disimpan sebagai int rupiah. nilainya dipindah ke kolom money lalu kolom lama dihapus
+	legacyAmounts := []struct {
+		model  interface{}
+		table  string
+		column string
+	}{
+		{&models.Trip{}, "trips", "price"},
+		{&models.Transaction{}, "transactions", "total"},
+		{&models.Transaction{}, "transactions", "subtotal"},
+		{&models.Transaction{}, "transactions", "discount"},
+	}
+	for _, legacy := range legacyAmounts {
+		if !mysql.DB.Migrator().HasColumn(legacy.model, legacy.column) {
+			continue
+		}
+		err = mysql.DB.Exec(fmt.Sprintf("UPDATE %s SET %s_amount = %s, %s_currency = ?", legacy.table, legacy.column, legacy.column, legacy.column), money.Settlement).Error
+		if err == nil {
+			err = mysql.DB.Migrator().DropColumn(legacy.model, legacy.column)
+		}
+		if err != nil {
+			fmt.Println(err)
+			panic("Migration failed")
+		}
+	}
+
+	// transaksi sebelum ada pajak dan biaya layanan tidak memiliki keduanya
+	err = mysql.DB.Exec("UPDATE transactions SET fees_amount = 0, fees_currency = ?, tax_amount = 0, tax_currency = ? WHERE fees_currency IS NULL OR fees_currency = ''", money.Settlement, money.Settlement).Error
+	if err != nil {
+		fmt.Println(err)
+		panic("Migration failed")
+	}
+
+	// kursi booking lunas sudah dikurangi dari kuota, booking pending lama baru menahan kursi saat lunas
+	if reservationAdded {
+		err = mysql.DB.Exec("UPDATE transactions SET seats_reserved = true WHERE status = ?", "success").Error
+		if err != nil {
+			fmt.Println(err)
+			panic("Migration failed")
+		}
+	}
+
+	// dulu reschedule mencocokkan keberangkatan dari judul dan negara trip. trip lama dikelompokkan dengan cara yang sama
+	err = mysql.DB.Exec("UPDATE trips SET group_key = LEFT(CONCAT(country_id, ':', LOWER(TRIM(title))), 255) WHERE group_key IS NULL OR group_key = ''").Error
+	if err != nil {
+		fmt.Println(err)
+		panic("Migration failed")
+	}
+
+	// dulu setiap transaction hanya punya satu invoice. unique index lama diganti idx_invoice_document agar reschedule
+	// bisa menerbitkan adjustment atau credit note
+	if mysql.DB.Migrator().HasIndex(&models.Invoice{}, "idx_invoices_transaction_id") {
+		err = mysql.DB.Migrator().DropIndex(&models.Invoice{}, "idx_invoices_transaction_id")
+	}
+	if err == nil {
+		err = mysql.DB.Model(&models.Invoice{}).Where("kind IS NULL OR kind = ''").Update("kind", models.InvoiceStandard).Error
+	}
+	if err != nil {
+		fmt.Println(err)
+		panic("Migration failed")
+	}
+
+	// booking yang dibuat sebelum ada riwayat mendapat satu event created dengan status saat ini
+	err = mysql.DB.Exec("INSERT INTO transaction_events (transaction_id, user_id, type, from_status, to_status, actor_id, impersonator_id, actor_type, detail, created_at) "+
+		"SELECT t.id, t.user_id, ?, '', t.status, t.user_id, 0, ?, ?, t.booking_date FROM transactions t "+
+		"WHERE NOT EXISTS (SELECT 1 FROM transaction_events e WHERE e.transaction_id = t.id)",
+		models.EventCreated, models.ActorUser, `{"backfilled":true}`).Error
+	if err != nil {
+		fmt.Println(err)
+		panic("Migration failed")
+	}
+
+	// riwayat lama diisi pemilik dari transaction, atau dari pembuat booking jika transaction sudah dihapus
+	if eventOwnerAdded {
+		err = mysql.DB.Exec("UPDATE transaction_events e JOIN transactions t ON t.id = e.transaction_id SET e.user_id = t.user_id WHERE e.user_id = 0").Error
+		if err == nil {
+			err = mysql.DB.Exec("UPDATE transaction_events e JOIN transaction_events c ON c.transaction_id = e.transaction_id AND c.type = ? "+
+				"SET e.user_id = c.actor_id WHERE e.user_id = 0", models.EventCreated).Error
+		}
+		if err != nil {
+			fmt.Println(err)
+			panic("Migration failed")
+		}
+	}
+
 	fmt.Println("Migration success")
 }
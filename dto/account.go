@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+type AccountDeletionResponse struct {
+	// akun dianonimkan setelah waktu ini kecuali penghapusan dibatalkan
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// isi profile.json di dalam file export
+type AccountExportProfile struct {
+	Id              int        `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Gender          string     `json:"gender"`
+	Phone           string     `json:"phone"`
+	Address         string     `json:"address"`
+	Image           string     `json:"image"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TotpEnabled     bool       `json:"totp_enabled"`
+}
+
+type AccountExportIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+type CreateAddOnRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+	Price       int    `json:"price" validate:"min=0"`
+	PerPerson   bool   `json:"per_person"`
+	Stock       int    `json:"stock" validate:"min=0"`
+	Active      *bool  `json:"active"`
+}
+
+// field yang tidak dikirim tidak diubah
+type UpdateAddOnRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+	Price       *int    `json:"price" validate:"omitempty,min=0"`
+	PerPerson   *bool   `json:"per_person"`
+	Stock       *int    `json:"stock" validate:"omitempty,min=0"`
+	Active      *bool   `json:"active"`
+}
+
+type AddOnResponse struct {
+	Id          int    `json:"id"`
+	TripId      int    `json:"trip_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       int    `json:"price"`
+	PerPerson   bool   `json:"per_person"`
+	Stock       int    `json:"stock"`
+	// nil berarti stok tidak dibatasi
+	Remaining *int `json:"remaining"`
+	Active    bool `json:"active"`
+}
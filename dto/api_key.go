@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+	// 0 berarti tidak pernah expired
+	ExpiresInDays int `json:"expires_in_days" validate:"min=0"`
+	// hanya admin yang boleh membuat api key untuk user lain
+	UserId int `json:"user_id"`
+}
+
+type APIKeyResponse struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// key lengkap hanya dikirim sekali saat dibuat
+	Key string `json:"key,omitempty"`
+}
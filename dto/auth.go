@@ -16,17 +16,19 @@ type LoginRequest struct {
 }
 
 type RegisterResponse struct {
-	Email    string `json:"email" form:"email"`
-	Password string `json:"password" form:"password"`
-	Role     string `json:"role" gorm:"type: varchar(255)"`
+	Email string `json:"email" form:"email"`
+	Role  string `json:"role" gorm:"type: varchar(255)"`
 }
 
 type LoginResponse struct {
-	Name     string `json:"name" gorm:"type: varchar(255)"`
-	Email    string `json:"email" gorm:"type: varchar(255)"`
-	Password string `json:"password" gorm:"type: varchar(255)"`
-	Token    string `json:"token" gorm:"type: varchar(255)"`
-	Role     string `json:"role" gorm:"type: varchar(255)"`
+	Name  string `json:"name" gorm:"type: varchar(255)"`
+	Email string `json:"email" gorm:"type: varchar(255)"`
+	Token string `json:"token" gorm:"type: varchar(255)"`
+	Role  string `json:"role" gorm:"type: varchar(255)"`
+	// diisi jika login butuh langkah kedua (kode 2FA) atau user wajib mengaktifkan 2FA terlebih dahulu
+	MFARequired      bool   `json:"mfa_required,omitempty"`
+	MFASetupRequired bool   `json:"mfa_setup_required,omitempty"`
+	MFAToken         string `json:"mfa_token,omitempty"`
 }
 
 type CheckAuth struct {
@@ -34,5 +36,9 @@ type CheckAuth struct {
 	Name  string `json:"name" form:"name"`
 	Email string `json:"email" form:"email"`
 	Role  string `json:"role" gorm:"type: varchar(255)"`
+	// status verifikasi email, transaksi hanya bisa dibuat jika true
+	EmailVerified bool `json:"email_verified"`
+	// diisi id admin jika token adalah token impersonation, agar frontend bisa menampilkan penanda
+	ImpersonatorId int `json:"impersonator_id,omitempty"`
 	// Password string `json:"password" form:"password"`
 }
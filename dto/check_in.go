@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// scanned_at diisi hp guide saat memindai, jika kosong dipakai waktu server
+type CheckInRequest struct {
+	Code      string     `json:"code" validate:"required,max=255"`
+	ScannedAt *time.Time `json:"scanned_at"`
+}
+
+// hasil pindaian yang disimpan hp guide saat offline lalu dikirim sekaligus
+type CheckInBatchRequest struct {
+	DeviceId string           `json:"device_id" validate:"max=64"`
+	Scans    []CheckInRequest `json:"scans" validate:"required,min=1,max=500,dive"`
+}
+
+type CheckInResult struct {
+	Code          string     `json:"code,omitempty"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message,omitempty"`
+	TransactionId int        `json:"transaction_id,omitempty"`
+	Seat          int        `json:"seat,omitempty"`
+	Reference     string     `json:"reference,omitempty"`
+	Passenger     string     `json:"passenger,omitempty"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+}
+
+type CheckInBatchResponse struct {
+	Total     int             `json:"total"`
+	CheckedIn int             `json:"checked_in"`
+	Duplicate int             `json:"duplicate"`
+	Rejected  int             `json:"rejected"`
+	Results   []CheckInResult `json:"results"`
+}
+
+type RosterPassenger struct {
+	TransactionId int        `json:"transaction_id"`
+	Seat          int        `json:"seat"`
+	Reference     string     `json:"reference"`
+	Passenger     string     `json:"passenger"`
+	BookedBy      string     `json:"booked_by"`
+	Phone         string     `json:"phone"`
+	CheckedIn     bool       `json:"checked_in"`
+	CheckedInAt   *time.Time `json:"checked_in_at"`
+	Source        string     `json:"source,omitempty"`
+}
+
+type RosterResponse struct {
+	TripId     int               `json:"trip_id"`
+	Title      string            `json:"title"`
+	DateTrip   time.Time         `json:"datetrip"`
+	Passengers int               `json:"passengers"`
+	CheckedIn  int               `json:"checked_in"`
+	Remaining  int               `json:"remaining"`
+	Roster     []RosterPassenger `json:"roster"`
+}
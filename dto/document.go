@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type DocumentResponse struct {
+	Id          int       `json:"id"`
+	UserId      int       `json:"user_id"`
+	TravelerId  int       `json:"traveler_id"`
+	Kind        string    `json:"kind"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// link download dokumen yang hanya berlaku sebentar
+type DocumentURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
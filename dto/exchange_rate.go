@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// tabel kurs dari admin, misal {"rates": {"USD": "15650.25", "SGD": "11620"}}. kurs yang tidak disebut tidak diubah
+type UpdateExchangeRatesRequest struct {
+	Rates map[string]string `json:"rates" validate:"required,min=1"`
+}
+
+type ExchangeRateResponse struct {
+	Currency  string    `json:"currency"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
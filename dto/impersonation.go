@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type ImpersonateRequest struct {
+	// alasan wajib diisi dan dicatat di audit log, misal nomor tiket support
+	Reason string `json:"reason" validate:"required,max=255"`
+	// lama token dalam menit, 0 berarti default
+	Minutes int `json:"minutes" validate:"min=0,max=60"`
+}
+
+type ImpersonateResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserId    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+}
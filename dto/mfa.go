@@ -0,0 +1,22 @@
+package dto
+
+type TotpSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qr_code"`
+}
+
+type TotpCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TotpEnableResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// token login, hanya diisi jika 2FA diaktifkan saat login (mfa_setup_required)
+	Token string `json:"token,omitempty"`
+}
+
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
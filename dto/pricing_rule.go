@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"project/models"
+	"project/pkg/money"
+	"time"
+)
+
+type CreatePricingRuleRequest struct {
+	Kind          string     `json:"kind" validate:"required,oneof=passenger_type early_bird quantity_tier peak_surcharge"`
+	Description   string     `json:"description" validate:"max=255"`
+	PassengerType string     `json:"passenger_type" validate:"omitempty,oneof=adult child senior"`
+	Percent       int        `json:"percent" validate:"min=0,max=100"`
+	Amount        int        `json:"amount" validate:"min=0"`
+	MinQuantity   int        `json:"min_quantity" validate:"min=0"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+}
+
+// field yang tidak dikirim tidak diubah, jenis aturan tidak bisa diubah
+type UpdatePricingRuleRequest struct {
+	Description   *string    `json:"description" validate:"omitempty,max=255"`
+	PassengerType *string    `json:"passenger_type" validate:"omitempty,oneof=adult child senior"`
+	Percent       *int       `json:"percent" validate:"omitempty,min=0,max=100"`
+	Amount        *int       `json:"amount" validate:"omitempty,min=0"`
+	MinQuantity   *int       `json:"min_quantity" validate:"omitempty,min=0"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+}
+
+type QuoteRequest struct {
+	CounterQty int              `json:"counter_qty" validate:"min=0"`
+	Passengers map[string]int   `json:"passengers"`
+	AddOns     []AddOnSelection `json:"add_ons"`
+	PromoCode  string           `json:"promo_code" validate:"max=32"`
+	// mata uang tampilan, misal USD. kosong berarti IDR. bisa juga lewat query ?currency=
+	Currency string `json:"currency" validate:"omitempty,len=3"`
+}
+
+type QuoteResponse struct {
+	TripId     int                      `json:"trip_id"`
+	Quantity   int                      `json:"quantity"`
+	Passengers map[string]int           `json:"passengers"`
+	Items      []models.TransactionItem `json:"items"`
+	Subtotal   money.Money              `json:"subtotal"`
+	Discount   money.Money              `json:"discount"`
+	Fees       money.Money              `json:"fees"`
+	Tax        money.Money              `json:"tax"`
+	Total      money.Money              `json:"total"`
+	PromoCode  string                   `json:"promo_code"`
+	Available  bool                     `json:"available"`
+	Display    *QuoteDisplay            `json:"display,omitempty"`
+}
+
+// harga quote dalam mata uang pilihan user, hanya untuk tampilan karena pembayaran tetap dalam IDR.
+// Subtotal, Fees, Tax dan Total dikonversi langsung, Discount adalah selisihnya agar angka yang tampil tetap cocok
+type QuoteDisplay struct {
+	Currency string        `json:"currency"`
+	Rate     string        `json:"rate"`
+	Items    []DisplayItem `json:"items"`
+	Subtotal money.Money   `json:"subtotal"`
+	Discount money.Money   `json:"discount"`
+	Fees     money.Money   `json:"fees"`
+	Tax      money.Money   `json:"tax"`
+	Total    money.Money   `json:"total"`
+}
+
+type DisplayItem struct {
+	Kind        string      `json:"kind"`
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	Quantity    int         `json:"quantity"`
+	UnitAmount  money.Money `json:"unit_amount"`
+	Amount      money.Money `json:"amount"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+type CreatePromoCodeRequest struct {
+	Code         string     `json:"code" validate:"required,alphanum,max=32"`
+	Description  string     `json:"description" validate:"max=255"`
+	DiscountType string     `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	Value        int        `json:"value" validate:"required,min=1"`
+	MaxDiscount  int        `json:"max_discount" validate:"min=0"`
+	MinSpend     int        `json:"min_spend" validate:"min=0"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   int        `json:"usage_limit" validate:"min=0"`
+	PerUserLimit int        `json:"per_user_limit" validate:"min=0"`
+	TripIds      []int      `json:"trip_ids"`
+	CountryIds   []int      `json:"country_ids"`
+	Active       *bool      `json:"active"`
+}
+
+// field yang tidak dikirim tidak diubah. kode promo tidak bisa diubah setelah dibuat
+type UpdatePromoCodeRequest struct {
+	Description  *string    `json:"description" validate:"omitempty,max=255"`
+	DiscountType *string    `json:"discount_type" validate:"omitempty,oneof=percentage fixed"`
+	Value        *int       `json:"value" validate:"omitempty,min=1"`
+	MaxDiscount  *int       `json:"max_discount" validate:"omitempty,min=0"`
+	MinSpend     *int       `json:"min_spend" validate:"omitempty,min=0"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   *int       `json:"usage_limit" validate:"omitempty,min=0"`
+	PerUserLimit *int       `json:"per_user_limit" validate:"omitempty,min=0"`
+	TripIds      *[]int     `json:"trip_ids"`
+	CountryIds   *[]int     `json:"country_ids"`
+	Active       *bool      `json:"active"`
+}
+
+type PromoCodeResponse struct {
+	Id           int        `json:"id"`
+	Code         string     `json:"code"`
+	Description  string     `json:"description"`
+	DiscountType string     `json:"discount_type"`
+	Value        int        `json:"value"`
+	MaxDiscount  int        `json:"max_discount"`
+	MinSpend     int        `json:"min_spend"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   int        `json:"usage_limit"`
+	PerUserLimit int        `json:"per_user_limit"`
+	UsedCount    int        `json:"used_count"`
+	TripIds      []int      `json:"trip_ids"`
+	CountryIds   []int      `json:"country_ids"`
+	Active       bool       `json:"active"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
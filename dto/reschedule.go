@@ -0,0 +1,6 @@
+package dto
+
+type RescheduleRequest struct {
+	TripId int    `json:"trip_id" validate:"required"`
+	Reason string `json:"reason" validate:"max=255"`
+}
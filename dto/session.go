@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type SessionResponse struct {
+	Id         int       `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// true untuk sesi yang sedang dipakai request ini
+	Current bool `json:"current"`
+	// sesi dibuat admin (support) lewat impersonation
+	Impersonated bool `json:"impersonated"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+type CreateTaxRuleRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Kind        string `json:"kind" validate:"required,oneof=tax service_fee"`
+	TripType    string `json:"trip_type" validate:"max=32"`
+	CountryId   int    `json:"country_id" validate:"min=0"`
+	RateBps     int    `json:"rate_bps" validate:"min=0,max=10000"`
+	FixedAmount int    `json:"fixed_amount" validate:"min=0"`
+	PerPerson   bool   `json:"per_person"`
+	Active      *bool  `json:"active"`
+}
+
+// field yang tidak dikirim tidak diubah
+type UpdateTaxRuleRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Kind        *string `json:"kind" validate:"omitempty,oneof=tax service_fee"`
+	TripType    *string `json:"trip_type" validate:"omitempty,max=32"`
+	CountryId   *int    `json:"country_id" validate:"omitempty,min=0"`
+	RateBps     *int    `json:"rate_bps" validate:"omitempty,min=0,max=10000"`
+	FixedAmount *int    `json:"fixed_amount" validate:"omitempty,min=0"`
+	PerPerson   *bool   `json:"per_person"`
+	Active      *bool   `json:"active"`
+}
@@ -1,13 +1,23 @@
 package dto
 
-import "project/models"
+import (
+	"project/models"
+	"project/pkg/money"
+)
 
 type CreateTransactionRequest struct {
-	CounterQty int    `json:"counter_qty" form:"counter_qty"`
-	Total      int    `json:"total" form:"total"`
-	Status     string `json:"status" form:"status"`
-	TripId     int    `json:"trip_id" form:"trip_id"`
-	UserId     int    `json:"user_id" form:"user_id"`
+	// boleh 0 jika passengers diisi, jumlahnya diambil dari passengers
+	CounterQty int `json:"counter_qty" form:"counter_qty" validate:"min=0"`
+	// total dari client diabaikan, total dihitung ulang di server
+	Total     int    `json:"total" form:"total"`
+	TripId    int    `json:"trip_id" form:"trip_id" validate:"required"`
+	UserId    int    `json:"user_id" form:"user_id"`
+	PromoCode string `json:"promo_code" form:"promo_code" validate:"max=32"`
+	// jumlah penumpang per jenis, misal {"adult": 2, "child": 1}. kosong berarti semua dewasa
+	Passengers map[string]int   `json:"passengers" form:"passengers"`
+	AddOns     []AddOnSelection `json:"add_ons"`
+	// traveler tersimpan yang ikut trip, jumlahnya tidak boleh melebihi counter_qty
+	TravelerIds []int `json:"traveler_ids" form:"traveler_ids"`
 	// Image      string `json:"image" form:"image"`
 }
 
@@ -16,13 +26,46 @@ type UpdateTransactionRequest struct {
 }
 
 type TransactionResponse struct {
-	Id          int                 `json:"id"`
-	CounterQty  int                 `json:"counter_qty"`
-	Token       string              `json:"token" gorm:"type: varchar(255)"`
-	Total       int                 `json:"total"`
-	Status      string              `json:"status"`
-	BookingDate string              `json:"booking_date"`
-	Trip        TripResponse        `json:"trip"`
-	User        models.UserResponse `json:"user"`
+	Id          int                      `json:"id"`
+	CounterQty  int                      `json:"counter_qty"`
+	Token       string                   `json:"token" gorm:"type: varchar(255)"`
+	Total       money.Money              `json:"total"`
+	Subtotal    money.Money              `json:"subtotal"`
+	Discount    money.Money              `json:"discount"`
+	Fees        money.Money              `json:"fees"`
+	Tax         money.Money              `json:"tax"`
+	PromoCode   string                   `json:"promo_code"`
+	Items       []models.TransactionItem `json:"items"`
+	Status      string                   `json:"status"`
+	BookingDate string                   `json:"booking_date"`
+	Trip        TripResponse             `json:"trip"`
+	User        models.UserResponse      `json:"user"`
+	Travelers   []TravelerResponse       `json:"travelers"`
 	// Image      string `json:"image" form:"image"`
 }
+
+// add-on yang dipilih saat booking, quantity 0 berarti sesuai jumlah penumpang (per orang) atau 1 (per booking)
+type AddOnSelection struct {
+	AddOnId  int `json:"add_on_id"`
+	Quantity int `json:"quantity"`
+}
+
+// laporan pajak dan biaya layanan dari transaksi yang berhasil dibayar, memakai nilai yang tersimpan di setiap booking
+type TransactionReport struct {
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	Transactions int64          `json:"transactions"`
+	Subtotal     money.Money    `json:"subtotal"`
+	Discount     money.Money    `json:"discount"`
+	Fees         money.Money    `json:"fees"`
+	Tax          money.Money    `json:"tax"`
+	Total        money.Money    `json:"total"`
+	Charges      []ReportCharge `json:"charges"`
+}
+
+type ReportCharge struct {
+	Kind        string      `json:"kind"`
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+}
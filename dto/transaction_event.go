@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type TransactionEventResponse struct {
+	Id             int             `json:"id"`
+	Type           string          `json:"type"`
+	FromStatus     string          `json:"from_status,omitempty"`
+	ToStatus       string          `json:"to_status,omitempty"`
+	ActorId        int             `json:"actor_id"`
+	ActorType      string          `json:"actor_type"`
+	ImpersonatorId int             `json:"impersonator_id,omitempty"`
+	Detail         json.RawMessage `json:"detail,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+type CreateTravelerRequest struct {
+	FullName       string `json:"full_name" validate:"required,max=255"`
+	Relationship   string `json:"relationship" validate:"required,oneof=self spouse child parent sibling friend other"`
+	Gender         string `json:"gender" validate:"omitempty,oneof=male female"`
+	Nationality    string `json:"nationality" validate:"max=64"`
+	DateOfBirth    string `json:"date_of_birth" validate:"omitempty,datetime=2006-01-02"`
+	DocumentType   string `json:"document_type" validate:"omitempty,oneof=passport national_id other"`
+	DocumentNumber string `json:"document_number" validate:"max=64"`
+	DocumentExpiry string `json:"document_expiry" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// field yang kosong tidak diubah
+type UpdateTravelerRequest struct {
+	FullName       string `json:"full_name" validate:"max=255"`
+	Relationship   string `json:"relationship" validate:"omitempty,oneof=self spouse child parent sibling friend other"`
+	Gender         string `json:"gender" validate:"omitempty,oneof=male female"`
+	Nationality    string `json:"nationality" validate:"max=64"`
+	DateOfBirth    string `json:"date_of_birth" validate:"omitempty,datetime=2006-01-02"`
+	DocumentType   string `json:"document_type" validate:"omitempty,oneof=passport national_id other"`
+	DocumentNumber string `json:"document_number" validate:"max=64"`
+	DocumentExpiry string `json:"document_expiry" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type TravelerResponse struct {
+	Id           int    `json:"id"`
+	FullName     string `json:"full_name"`
+	Relationship string `json:"relationship"`
+	Gender       string `json:"gender"`
+	Nationality  string `json:"nationality"`
+	DateOfBirth  string `json:"date_of_birth"`
+	DocumentType string `json:"document_type"`
+	// disamarkan kecuali 4 karakter terakhir, nomor lengkap hanya dikirim di GET /traveler/{id}
+	DocumentNumber string    `json:"document_number"`
+	DocumentExpiry string    `json:"document_expiry"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
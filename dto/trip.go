@@ -1,11 +1,16 @@
 package dto
 
-import "project/models"
+import (
+	"project/models"
+	"project/pkg/money"
+)
 
 type CreateTripRequest struct {
 	Title          string `json:"title" form:"title"`
 	CountryId      int    `json:"country_id" form:"country_id"`
 	Accomodation   string `json:"accomodation" form:"accomodation"`
+	Type           string `json:"type" form:"type"`
+	GroupKey       string `json:"group_key" form:"group_key"`
 	Transportation string `json:"transportation" form:"transportation"`
 	Eat            string `json:"eat" form:"eat"`
 	Day            int    `json:"day" form:"day"`
@@ -21,6 +26,8 @@ type UpdateTripRequest struct {
 	Title          string `json:"title" form:"title"`
 	CountryId      int    `json:"country_id" form:"country_id"`
 	Accomodation   string `json:"accomodation" form:"accomodation"`
+	Type           string `json:"type" form:"type"`
+	GroupKey       string `json:"group_key" form:"group_key"`
 	Transportation string `json:"transportation" form:"transportation"`
 	Eat            string `json:"eat" form:"eat"`
 	Day            int    `json:"day" form:"day"`
@@ -38,12 +45,14 @@ type TripResponse struct {
 	CountryId      int                    `json:"country_id"`
 	Country        models.CountryResponse `json:"country"`
 	Accomodation   string                 `json:"accomodation"`
+	Type           string                 `json:"type"`
+	GroupKey       string                 `json:"group_key"`
 	Transportation string                 `json:"transportation"`
 	Eat            string                 `json:"eat"`
 	Day            int                    `json:"day"`
 	Night          int                    `json:"night"`
 	DateTrip       string                 `json:"datetrip"`
-	Price          int                    `json:"price"`
+	Price          money.Money            `json:"price"`
 	Quota          int                    `json:"quota"`
 	Description    string                 `json:"description"`
 	Image          string                 `json:"image"`
@@ -28,4 +28,14 @@ type UserResponse struct {
 	Address string `json:"address" form:"address"`
 	Image   string `json:"image" form:"image"`
 	Role    string `json:"role" form:"role"`
+	// active, suspended, unverified, pending_deletion atau deleted
+	Status string `json:"status"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin guide user"`
 }
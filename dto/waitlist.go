@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type JoinWaitlistRequest struct {
+	Seats int `json:"seats" validate:"required,min=1,max=50"`
+}
+
+type WaitlistResponse struct {
+	Id             int          `json:"id"`
+	Trip           TripResponse `json:"trip"`
+	Seats          int          `json:"seats"`
+	Status         string       `json:"status"`
+	Position       int          `json:"position,omitempty"`
+	OfferedAt      *time.Time   `json:"offered_at"`
+	OfferExpiresAt *time.Time   `json:"offer_expires_at"`
+	TransactionId  int          `json:"transaction_id,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// antrean satu trip untuk admin
+type TripWaitlistResponse struct {
+	Id             int        `json:"id"`
+	UserId         int        `json:"user_id"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Seats          int        `json:"seats"`
+	Status         string     `json:"status"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
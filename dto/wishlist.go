@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"project/pkg/money"
+	"time"
+)
+
+type CreateWishlistRequest struct {
+	TripId          int  `json:"trip_id" validate:"required"`
+	NotifyPriceDrop bool `json:"notify_price_drop"`
+	NotifySellOut   bool `json:"notify_sell_out"`
+}
+
+// field yang tidak dikirim tidak diubah
+type UpdateWishlistRequest struct {
+	NotifyPriceDrop *bool `json:"notify_price_drop"`
+	NotifySellOut   *bool `json:"notify_sell_out"`
+}
+
+type WishlistResponse struct {
+	Trip            TripResponse `json:"trip"`
+	PriceAtAdded    money.Money  `json:"price_at_added"`
+	PriceDropped    bool         `json:"price_dropped"`
+	Available       bool         `json:"available"`
+	NotifyPriceDrop bool         `json:"notify_price_drop"`
+	NotifySellOut   bool         `json:"notify_sell_out"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
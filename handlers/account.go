@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/mail"
+	"project/repositories"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// batas ukuran satu gambar yang ikut di dalam file export
+const exportImageMaxBytes = 10 << 20
+
+var exportHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+type handlerAccount struct {
+	AccountRepository repositories.AccountRepository
+}
+
+func HandlerAccount(AccountRepository repositories.AccountRepository) *handlerAccount {
+	return &handlerAccount{AccountRepository}
+}
+
+// function ExportAccount mengirim file zip berisi semua data pribadi milik user yang login
+func (h *handlerAccount) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	archive, err := h.buildExport(userId)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.AccountRepository, r, "account.export", userId, map[string]interface{}{"bytes": len(archive)})
+
+	filename := fmt.Sprintf("dewetour-export-%d-%s.zip", userId, time.Now().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(archive)
+}
+
+// function buildExport menyusun isi zip di memori agar error masih bisa dikirim sebagai json
+func (h *handlerAccount) buildExport(userId int) ([]byte, error) {
+	user, err := h.AccountRepository.GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := h.AccountRepository.FindTransactionsByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := h.AccountRepository.FindIdentitiesByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := h.AccountRepository.FindSessionsByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := h.AccountRepository.FindAPIKeysByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	travelers, err := h.AccountRepository.FindTravelersByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := h.AccountRepository.FindDocumentsByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	wishlists, err := h.AccountRepository.FindWishlistsByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	profile := dto.AccountExportProfile{
+		Id:              user.Id,
+		Name:            user.Name,
+		Email:           user.Email,
+		Gender:          user.Gender,
+		Phone:           user.Phone,
+		Address:         user.Address,
+		Image:           user.Image,
+		Role:            user.Role,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		TotpEnabled:     user.TotpEnabled,
+	}
+
+	var exportIdentities []dto.AccountExportIdentity
+	for _, identity := range identities {
+		exportIdentities = append(exportIdentities, dto.AccountExportIdentity{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	exportTravelers := []dto.TravelerResponse{}
+	for _, traveler := range travelers {
+		exportTravelers = append(exportTravelers, convertResponseTraveler(traveler, true))
+	}
+
+	exportDocuments := []dto.DocumentResponse{}
+	for _, document := range documents {
+		exportDocuments = append(exportDocuments, convertResponseDocument(document))
+	}
+
+	exportWishlist := []dto.WishlistResponse{}
+	for _, wishlist := range wishlists {
+		exportWishlist = append(exportWishlist, convertResponseWishlist(wishlist))
+	}
+
+	var exportKeys []dto.APIKeyResponse
+	for _, key := range keys {
+		exportKeys = append(exportKeys, convertResponseAPIKey(key))
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"bookings.json", convertMultipleTransactionResponse(transactions)},
+		{"travelers.json", exportTravelers},
+		{"documents.json", exportDocuments},
+		{"wishlist.json", exportWishlist},
+		{"linked_accounts.json", exportIdentities},
+		{"sessions.json", convertSessionsResponse(sessions, "")},
+		{"api_keys.json", exportKeys},
+	}
+	for _, file := range files {
+		if err := writeZipJSON(zw, file.name, file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	// gambar yang gagal diambil dicatat di README agar user tahu, export tetap dilanjutkan
+	var missing []string
+	if user.Image != "" {
+		if err := writeZipImage(zw, "images/profile"+filepath.Ext(user.Image), user.Image); err != nil {
+			missing = append(missing, user.Image)
+		}
+	}
+	for _, t := range transactions {
+		if t.Image == "" {
+			continue
+		}
+		name := fmt.Sprintf("images/payment-%d%s", t.Id, filepath.Ext(t.Image))
+		if err := writeZipImage(zw, name, filepath.Join("uploads", filepath.Base(t.Image))); err != nil {
+			missing = append(missing, t.Image)
+		}
+	}
+
+	readme, err := zw.Create("README.txt")
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(readme, "dewetour personal data export for user %d, generated %s\n\n", user.Id, time.Now().Format(time.RFC3339))
+	fmt.Fprintln(readme, "profile.json          account profile")
+	fmt.Fprintln(readme, "bookings.json         bookings and payments")
+	fmt.Fprintln(readme, "travelers.json        saved traveler profiles")
+	fmt.Fprintln(readme, "documents.json        identity documents in the vault (download each file from the app)")
+	fmt.Fprintln(readme, "wishlist.json         saved trips and notification preferences")
+	fmt.Fprintln(readme, "linked_accounts.json  external login providers")
+	fmt.Fprintln(readme, "sessions.json         active login sessions")
+	fmt.Fprintln(readme, "api_keys.json         personal api keys (the keys themselves are never stored)")
+	fmt.Fprintln(readme, "images/               profile picture and uploaded payment proofs")
+	for _, source := range missing {
+		fmt.Fprintf(readme, "\ncould not include image: %s", source)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, data interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// function writeZipImage menyalin gambar dari url (cloudinary) atau dari folder uploads ke dalam zip
+func writeZipImage(zw *zip.Writer, name, source string) error {
+	var reader io.ReadCloser
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := exportHTTPClient.Get(source)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("image status %d", resp.StatusCode)
+		}
+		reader = resp.Body
+	} else {
+		file, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		reader = file
+	}
+	defer reader.Close()
+
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, io.LimitReader(reader, exportImageMaxBytes))
+	return err
+}
+
+// function DeleteAccount menjadwalkan penghapusan akun. data pribadi baru dianonimkan setelah masa tunggu
+// sehingga user masih bisa membatalkan, transaksi tetap disimpan untuk keperluan pembukuan
+func (h *handlerAccount) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	user, err := h.AccountRepository.GetUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// request ulang tidak memperpanjang masa tunggu
+	if user.DeletionScheduledAt != nil {
+		w.WriteHeader(http.StatusOK)
+		response := dto.SuccessResult{Code: http.StatusOK, Data: dto.AccountDeletionResponse{DeletionScheduledAt: *user.DeletionScheduledAt}}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	scheduledAt := time.Now().Add(accountDeletionGrace())
+	if err := h.AccountRepository.ScheduleDeletion(userId, &scheduledAt); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.AccountRepository, r, "account.deletion_requested", userId, map[string]interface{}{"scheduled_at": scheduledAt})
+	go sendDeletionScheduledEmail(user, scheduledAt)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: dto.AccountDeletionResponse{DeletionScheduledAt: scheduledAt}}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function CancelDeletion membatalkan penghapusan akun selama masa tunggu belum lewat
+func (h *handlerAccount) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	user, err := h.AccountRepository.GetUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if user.DeletionScheduledAt == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "no account deletion scheduled"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.AccountRepository.ScheduleDeletion(userId, nil); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.AccountRepository, r, "account.deletion_cancelled", userId, nil)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "account deletion cancelled"}
+	json.NewEncoder(w).Encode(response)
+}
+
+func sendDeletionScheduledEmail(user models.User, scheduledAt time.Time) {
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Hi %s,</h2>
+      <p>We received a request to delete your dewetour account. Your personal data will be removed on %s.</p>
+      <p>Your booking and payment records are kept for accounting, but will no longer be linked to you.</p>
+      <p>If you did not request this, log in and cancel the deletion before that date.</p>
+      </body>
+    </html>`, html.EscapeString(user.Name), scheduledAt.Format("2 January 2006 15:04 MST")),
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// function accountDeletionGrace mengambil masa tunggu penghapusan akun dari env ACCOUNT_DELETION_GRACE_DAYS (default 14 hari)
+func accountDeletionGrace() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || days < 0 {
+		days = 14
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
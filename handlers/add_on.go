@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/repositories"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type handlerAddOn struct {
+	AddOnRepository repositories.AddOnRepository
+}
+
+func HandlerAddOn(AddOnRepository repositories.AddOnRepository) *handlerAddOn {
+	return &handlerAddOn{AddOnRepository}
+}
+
+// function FindAddOns menampilkan add-on aktif yang bisa dipilih saat booking trip
+func (h *handlerAddOn) FindAddOns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	addOns, err := h.AddOnRepository.FindAddOns(tripId, true)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.AddOnResponse{}
+	for _, addOn := range addOns {
+		result = append(result, convertResponseAddOn(addOn))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerAddOn) CreateAddOn(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.CreateAddOnRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if _, err := h.AddOnRepository.GetTrip(tripId); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	addOn, err := h.AddOnRepository.CreateAddOn(models.AddOn{
+		TripId:      tripId,
+		Name:        request.Name,
+		Description: request.Description,
+		Price:       request.Price,
+		PerPerson:   request.PerPerson,
+		Stock:       request.Stock,
+		Active:      request.Active == nil || *request.Active,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseAddOn(addOn)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerAddOn) UpdateAddOn(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdateAddOnRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	addOn, err := h.AddOnRepository.GetAddOn(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "add-on not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if request.Name != nil {
+		addOn.Name = *request.Name
+	}
+	if request.Description != nil {
+		addOn.Description = *request.Description
+	}
+	if request.Price != nil {
+		addOn.Price = *request.Price
+	}
+	if request.PerPerson != nil {
+		addOn.PerPerson = *request.PerPerson
+	}
+	if request.Stock != nil {
+		addOn.Stock = *request.Stock
+	}
+	if request.Active != nil {
+		addOn.Active = *request.Active
+	}
+
+	addOn, err = h.AddOnRepository.UpdateAddOn(addOn)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseAddOn(addOn)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function DeleteAddOn menghapus add-on, booking yang sudah memilih add-on tetap menyimpan rinciannya
+func (h *handlerAddOn) DeleteAddOn(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	addOn, err := h.AddOnRepository.GetAddOn(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "add-on not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.AddOnRepository.DeleteAddOn(addOn); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseAddOn(addOn)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func convertResponseAddOn(addOn models.AddOn) dto.AddOnResponse {
+	response := dto.AddOnResponse{
+		Id:          addOn.Id,
+		TripId:      addOn.TripId,
+		Name:        addOn.Name,
+		Description: addOn.Description,
+		Price:       addOn.Price,
+		PerPerson:   addOn.PerPerson,
+		Stock:       addOn.Stock,
+		Active:      addOn.Active,
+	}
+	if addOn.Stock > 0 {
+		remaining := addOn.Stock - addOn.Sold
+		if remaining < 0 {
+			remaining = 0
+		}
+		response.Remaining = &remaining
+	}
+	return response
+}
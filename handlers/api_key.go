@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/apikey"
+	"project/pkg/policy"
+	"project/repositories"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerAPIKey struct {
+	APIKeyRepository repositories.APIKeyRepository
+}
+
+func HandlerAPIKey(APIKeyRepository repositories.APIKeyRepository) *handlerAPIKey {
+	return &handlerAPIKey{APIKeyRepository}
+}
+
+// function FindAPIKeys menampilkan api key milik user yang login. admin bisa melihat milik user lain dengan ?user_id=
+func (h *handlerAPIKey) FindAPIKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	subject := policy.FromRequest(r)
+	userId := subject.Id
+	if value := r.URL.Query().Get("user_id"); value != "" {
+		userId, _ = strconv.Atoi(value)
+	}
+
+	if err := policy.Authorize(subject, policy.APIKeyManageAny, userId); err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	keys, err := h.APIKeyRepository.FindAPIKeysByUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.APIKeyResponse{}
+	for _, key := range keys {
+		result = append(result, convertResponseAPIKey(key))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function CreateAPIKey membuat api key baru. key lengkap hanya ditampilkan di response ini
+func (h *handlerAPIKey) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.CreateAPIKeyRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	for _, scope := range request.Scopes {
+		if !policy.ValidScope(scope) {
+			w.WriteHeader(http.StatusBadRequest)
+			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "unknown scope: " + scope}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+	}
+
+	subject := policy.FromRequest(r)
+	userId := subject.Id
+	if request.UserId != 0 {
+		userId = request.UserId
+	}
+
+	if err := policy.Authorize(subject, policy.APIKeyManageAny, userId); err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if _, err := h.APIKeyRepository.GetUser(userId); err != nil {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	newKey := models.APIKey{
+		UserId:  userId,
+		Name:    request.Name,
+		Prefix:  prefix,
+		KeyHash: hash,
+		Scopes:  strings.Join(request.Scopes, ","),
+	}
+	if request.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, request.ExpiresInDays)
+		newKey.ExpiresAt = &expiresAt
+	}
+
+	data, err := h.APIKeyRepository.CreateAPIKey(newKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	keyResponse := convertResponseAPIKey(data)
+	keyResponse.Key = key
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: keyResponse}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function RevokeAPIKey mencabut api key milik user (admin boleh mencabut milik user lain)
+func (h *handlerAPIKey) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	key, err := h.APIKeyRepository.GetAPIKey(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if err := policy.Authorize(policy.FromRequest(r), policy.APIKeyManageAny, key.UserId); err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if err := h.APIKeyRepository.RevokeAPIKey(key.Id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseAPIKey(key)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func convertResponseAPIKey(k models.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		Id:         k.Id,
+		UserId:     k.UserId,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     strings.Split(k.Scopes, ","),
+		LastUsedAt: k.LastUsedAt,
+		LastUsedIP: k.LastUsedIP,
+		ExpiresAt:  k.ExpiresAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
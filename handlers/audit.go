@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"project/models"
+	"project/pkg/middleware"
+	"project/pkg/policy"
+)
+
+// auditWriter dipenuhi oleh repository yang memiliki method CreateAuditLog
+type auditWriter interface {
+	CreateAuditLog(log models.AuditLog) error
+}
+
+// function audit mencatat aksi ke audit log. kegagalan mencatat hanya dilog agar tidak menggagalkan request
+func audit(repo auditWriter, r *http.Request, action string, subjectUserId int, detail interface{}) {
+	subject := policy.FromRequest(r)
+	entry := models.AuditLog{
+		ActorId:        subject.Id,
+		ImpersonatorId: subject.ImpersonatorId,
+		SubjectUserId:  subjectUserId,
+		Action:         action,
+		IP:             middleware.ClientIP(r),
+	}
+	if detail != nil {
+		if b, err := json.Marshal(detail); err == nil {
+			entry.Detail = string(b)
+		}
+	}
+
+	if err := repo.CreateAuditLog(entry); err != nil {
+		log.Println("audit log:", err)
+	}
+}
@@ -3,27 +3,41 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"os"
 	dto "project/dto"
 	"project/models"
 	"project/pkg/bcrypt"
 	jwtToken "project/pkg/jwt"
+	"project/pkg/loginguard"
+	"project/pkg/mail"
+	"project/pkg/middleware"
+	"project/pkg/passwordpolicy"
+	"project/pkg/policy"
+	"project/pkg/ratelimit"
 	"project/repositories"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
 )
 
 // Handle struct
 type handlerAuth struct {
 	AuthRepository repositories.AuthRepository
+	LoginGuard     *loginguard.Guard
 }
 
 // Handle Authentication
-func HandlerAuth(AuthRepository repositories.AuthRepository) *handlerAuth {
-	return &handlerAuth{AuthRepository}
+func HandlerAuth(AuthRepository repositories.AuthRepository, LoginGuard *loginguard.Guard) *handlerAuth {
+	return &handlerAuth{AuthRepository, LoginGuard}
 }
 
 // membuat struct function Register
@@ -51,6 +65,22 @@ func (h *handlerAuth) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// password harus memenuhi policy (panjang, password bocor, tidak memakai nama/email)
+	if err := passwordpolicy.Validate(request.Password, request.Name, request.Email); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// email harus unik
+	if _, err := h.AuthRepository.Login(request.Email); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "email already registered"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	// Hashing password request.Password(registerRequest) dengan method HashingPassword
 	password, err := bcrypt.HashingPassword(request.Password)
 	if err != nil {
@@ -80,8 +110,12 @@ func (h *handlerAuth) Register(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
 		json.NewEncoder(w).Encode(response)
+		return
 	}
 
+	// akun baru belum terverifikasi, kirim link verifikasi ke email user
+	go sendVerificationEmail(userData)
+
 	// jika tidak ada error maka panggil SuccesResult dan data akan di isi dengan func convertresponseRegister
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseRegister(userData)}
@@ -91,8 +125,7 @@ func (h *handlerAuth) Register(w http.ResponseWriter, r *http.Request) {
 // function convertResponseRegister
 func convertResponseRegister(u models.User) dto.RegisterResponse {
 	return dto.RegisterResponse{
-		Email:    u.Email,
-		Password: u.Password,
+		Email: u.Email,
 	}
 }
 
@@ -111,60 +144,98 @@ func (h *handlerAuth) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// jika tidak ada error struct user akan diisi dengan request
-	user := models.User{
-		Email:    request.Email,
-		Password: request.Password,
+	// akun dan ip yang terlalu sering gagal login harus menunggu (backoff) atau sedang dikunci
+	accountKey := loginguard.AccountKey(strings.ToLower(strings.TrimSpace(request.Email)))
+	ipKey := loginguard.IPKey(middleware.ClientIP(r))
+	for _, check := range []struct {
+		policy loginguard.Policy
+		key    string
+	}{{loginguard.IPPolicy, ipKey}, {loginguard.AccountPolicy, accountKey}} {
+		if err := h.LoginGuard.Check(check.policy, check.key); err != nil {
+			writeLoginBlocked(w, err)
+			return
+		}
 	}
 
-	// panggil Register lalu user.Email akan digunakan sebagai parameter
-	user, err := h.AuthRepository.Login(user.Email)
+	// panggil Login lalu request.Email akan digunakan sebagai parameter
+	user, err := h.AuthRepository.Login(request.Email)
+
+	// check password dengan method CheckPasswordHash. par request.Password dan user.Password akan di cek.
+	// email yang tidak terdaftar mendapat pesan yang sama agar email user tidak bisa ditebak
+	if err != nil || !bcrypt.CheckPasswordHash(request.Password, user.Password) {
+		h.loginFailed(ipKey, accountKey, user)
 
-	// jika ada error maka panggil ErrorResult
-	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "salah email atau password"}
 		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	// check password dengan method CheckPasswordHash. par request.Password dan user.Password akan di cek
-	isValid := bcrypt.CheckPasswordHash(request.Password, user.Password)
+	// login berhasil, catatan gagal akun dihapus
+	if err := h.LoginGuard.Reset(accountKey); err != nil {
+		log.Println(err)
+	}
 
-	// jika tidak valid maka panggil ErrorResult
-	if !isValid {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "salah email atau password"}
+	h.completeLogin(w, r, user)
+}
+
+// function completeLogin dipanggil setelah user terbukti pemilik akun (password benar atau login lewat OIDC).
+// jika 2FA aktif atau wajib untuk role user maka yang dikirim hanya mfa_token, selain itu token login
+func (h *handlerAuth) completeLogin(w http.ResponseWriter, r *http.Request, user models.User) {
+	if message := accountLoginBlocked(user); message != "" {
+		w.WriteHeader(http.StatusForbidden)
+		response := dto.ErrorResult{Code: http.StatusForbidden, Message: message}
 		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	// membuat data yang akan disimpan di jwt dan claim akan digunakan untuk generate token
-	claims := jwt.MapClaims{}
-
-	claims["id"] = user.Id // buat key id valuenya user.Id
-	claims["role"] = user.Role
-	claims["email"] = user.Email
-	claims["password"] = user.Password
-	claims["exp"] = time.Now().Add(time.Hour * 2).Unix() // mak token 2 jam
+	// user dengan 2FA aktif, atau role yang wajib 2FA, harus melewati langkah kedua sebelum mendapat token login
+	if user.TotpEnabled || mfaRequired(user.Role) {
+		purpose := jwtToken.PurposeMFALogin
+		if !user.TotpEnabled {
+			purpose = jwtToken.PurposeMFAEnroll
+		}
+
+		mfaToken, err := generateMFAToken(user, purpose)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		loginResponse := dto.LoginResponse{
+			Name:             user.Name,
+			Email:            user.Email,
+			Role:             user.Role,
+			MFARequired:      user.TotpEnabled,
+			MFASetupRequired: !user.TotpEnabled,
+			MFAToken:         mfaToken,
+		}
+
+		w.WriteHeader(http.StatusOK)
+		response := dto.SuccessResult{Code: http.StatusOK, Data: loginResponse}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
 
-	// panggil method GenerateToken(agar dibuatkan token) dan claim akan dijadikan parameter
-	token, err := jwtToken.GenerateToken(&claims)
+	// panggil method generateLoginToken(agar dibuatkan token)
+	token, err := h.generateLoginToken(user, r)
 
 	// jika ada error / tidak ada token maka err
 	if err != nil {
-		log.Println(err)
-		fmt.Println("Unauthorize")
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
 		return
 	}
 
 	// jika tidak ada error struct LoginResponse akan di isi data request user
 	loginResponse := dto.LoginResponse{
-		Name:     user.Name,
-		Email:    user.Email,
-		Password: user.Password,
-		Token:    token,
-		Role:     user.Role,
+		Name:  user.Name,
+		Email: user.Email,
+		Token: token,
+		Role:  user.Role,
 	}
 
 	// dan login loginResponse akan dijadikan value dari data
@@ -173,7 +244,130 @@ func (h *handlerAuth) Login(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// membuat fungsi yang digunakan untuk mendaftarkan user baru
+// function accountLoginBlocked mengembalikan alasan akun tidak boleh login (disuspend admin atau wajib reset password)
+func accountLoginBlocked(user models.User) string {
+	if user.SuspendedAt != nil {
+		return "account suspended"
+	}
+	if user.PasswordResetRequired {
+		return "password reset required, please use the link sent to your email"
+	}
+	return ""
+}
+
+// function loginFailed mencatat gagal login untuk ip dan akun, lalu mengirim email pemberitahuan jika akun baru saja dikunci
+func (h *handlerAuth) loginFailed(ipKey string, accountKey string, user models.User) {
+	if _, err := h.LoginGuard.Fail(loginguard.IPPolicy, ipKey); err != nil {
+		log.Println(err)
+	}
+
+	locked, err := h.LoginGuard.Fail(loginguard.AccountPolicy, accountKey)
+	if err != nil {
+		log.Println(err)
+	}
+	if locked && user.Id != 0 {
+		go sendLockoutEmail(user)
+	}
+}
+
+func writeLoginBlocked(w http.ResponseWriter, err error) {
+	blocked, ok := loginguard.IsBlocked(err)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+	response := dto.ErrorResult{Code: http.StatusTooManyRequests, Message: blocked.Error()}
+	json.NewEncoder(w).Encode(response)
+}
+
+func sendLockoutEmail(user models.User) {
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your account has been temporarily locked",
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Hi %s,</h2>
+      <p>We locked your dewetour account for %d minutes after too many failed login attempts.</p>
+      <p>If this was not you, we recommend resetting your password once the lock expires.</p>
+      </body>
+    </html>`, html.EscapeString(user.Name), int(loginguard.AccountPolicy.LockFor.Minutes())),
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// function UnlockUser dipakai admin untuk membuka kunci login sebuah akun
+func (h *handlerAuth) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	user, err := h.AuthRepository.Getuser(id)
+	if err != nil {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
+
+	if err := h.LoginGuard.Reset(loginguard.AccountKey(strings.ToLower(user.Email))); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.AuthRepository, r, "user.unlocked", user.Id, nil)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "account unlocked"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// masa berlaku token login dan sesinya
+const loginTokenTTL = time.Hour * 2
+
+// function generateLoginToken membuat sesi baru (device, ip, user agent) lalu membuat token login (jwt) untuk sesi tersebut.
+// dipanggil setelah user lolos semua langkah login
+func (h *handlerAuth) generateLoginToken(user models.User, r *http.Request) (string, error) {
+	tokenId, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserId:     user.Id,
+		TokenId:    tokenId,
+		Device:     deviceFromUserAgent(r.UserAgent()),
+		IP:         middleware.ClientIP(r),
+		UserAgent:  truncate(r.UserAgent(), 512),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(loginTokenTTL),
+	}
+	if _, err := h.AuthRepository.CreateSession(session); err != nil {
+		return "", err
+	}
+
+	// membuat data yang akan disimpan di jwt dan claim akan digunakan untuk generate token
+	claims := jwt.MapClaims{}
+
+	claims["id"] = user.Id // buat key id valuenya user.Id
+	claims["role"] = user.Role
+	claims["email"] = user.Email
+	claims["sid"] = tokenId                  // sesi yang dicek oleh middleware Auth
+	claims["ver"] = user.TokenVersion        // token lama tidak berlaku lagi jika sesi user dicabut
+	claims["exp"] = session.ExpiresAt.Unix() // mak token 2 jam
+
+	// panggil method GenerateToken(agar dibuatkan token) dan claim akan dijadikan parameter
+	return jwtToken.GenerateToken(&claims)
+}
+
+// membuat fungsi yang digunakan untuk mendaftarkan admin baru, hanya bisa dipanggil oleh admin
 func (h *handlerAuth) RegisterAdmin(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -191,6 +385,14 @@ func (h *handlerAuth) RegisterAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// password harus memenuhi policy (panjang, password bocor, tidak memakai nama/email)
+	if err := passwordpolicy.Validate(request.Password, request.Name, request.Email); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	// menghashing password
 	hashedPassword, err := bcrypt.HashingPassword(request.Password)
 	if err != nil {
@@ -221,6 +423,10 @@ func (h *handlerAuth) RegisterAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	audit(h.AuthRepository, r, "user.admin_registered", adminData.Id, nil)
+
+	go sendVerificationEmail(adminData)
+
 	// menyiapkan response
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{
@@ -247,13 +453,138 @@ func (h *handlerAuth) CheckAuth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	CheckAuthResponse := dto.CheckAuth{
-		Id:    user.Id,
-		Name:  user.Name,
-		Email: user.Email,
-		Role:  user.Role,
+		Id:             user.Id,
+		Name:           user.Name,
+		Email:          user.Email,
+		Role:           user.Role,
+		EmailVerified:  user.EmailVerifiedAt != nil,
+		ImpersonatorId: policy.ImpersonatorId(userInfo),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	response := dto.SuccessResult{Code: http.StatusOK, Data: CheckAuthResponse}
 	json.NewEncoder(w).Encode(response)
 }
+
+// function VerifyEmail dipanggil dari link yang dikirim ke email user
+func (h *handlerAuth) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// token verifikasi hanya valid untuk keperluan verify_email dan belum expired
+	claims, err := jwtToken.DecodePurposeToken(jwtToken.PurposeVerifyEmail, r.URL.Query().Get("token"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "invalid or expired verification link"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	userId, _ := claims["id"].(float64)
+	user, err := h.AuthRepository.Getuser(int(userId))
+
+	// link lama tidak berlaku jika email user sudah diganti
+	if err != nil || claims["email"] != user.Email {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "invalid or expired verification link"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := h.AuthRepository.SetEmailVerified(user.Id, &now); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "email verified"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function ResendVerification mengirim ulang link verifikasi ke email user yang sedang login
+func (h *handlerAuth) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	user, err := h.AuthRepository.Getuser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "email already verified"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// sama seperti lupa password, setiap email dibatasi jumlah link yang dikirim
+	if !verificationEmailLimiter.Allow(strings.ToLower(user.Email)) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		response := dto.ErrorResult{Code: http.StatusTooManyRequests, Message: "too many requests, please try again later"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	go sendVerificationEmail(user)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "verification email sent"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// masa berlaku link verifikasi email
+const verificationTokenTTL = time.Hour * 24
+
+// setiap email maksimal menerima 3 link verifikasi per jam
+var verificationEmailLimiter = ratelimit.New(3, time.Hour)
+
+// function sendVerificationEmail membuat link verifikasi yang ditandatangani lalu mengirimnya ke email user
+func sendVerificationEmail(user models.User) {
+	claims := jwt.MapClaims{}
+	claims["id"] = user.Id
+	claims["email"] = user.Email
+	claims["exp"] = time.Now().Add(verificationTokenTTL).Unix()
+
+	token, err := jwtToken.GeneratePurposeToken(jwtToken.PurposeVerifyEmail, claims)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	link := baseURL() + "/api/v1/verify_email?token=" + url.QueryEscape(token)
+
+	err = mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Hi %s,</h2>
+      <p>Please verify your email address to start booking trips on dewetour.</p>
+      <p><a href="%s">Verify email</a></p>
+      <p>This link expires in 24 hours.</p>
+      </body>
+    </html>`, html.EscapeString(user.Name), link),
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// function baseURL mengambil alamat publik backend dari env BASE_URL
+func baseURL() string {
+	if value := os.Getenv("BASE_URL"); value != "" {
+		return strings.TrimRight(value, "/")
+	}
+	return "http://localhost:5000"
+}
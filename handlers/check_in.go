@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/policy"
+	"project/repositories"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// status hasil check-in satu tiket
+const (
+	checkInOk             = "checked_in"
+	checkInDuplicate      = "duplicate"
+	checkInWrongDeparture = "wrong_departure"
+	checkInNotPaid        = "not_paid"
+	checkInInvalid        = "invalid"
+)
+
+type handlerCheckIn struct {
+	CheckInRepository repositories.CheckInRepository
+}
+
+func HandlerCheckIn(CheckInRepository repositories.CheckInRepository) *handlerCheckIn {
+	return &handlerCheckIn{CheckInRepository}
+}
+
+// function CheckIn memindai QR code e-ticket di lokasi keberangkatan. tiket palsu, tiket keberangkatan lain,
+// booking yang belum dibayar dan penumpang yang sudah check-in ditolak
+func (h *handlerCheckIn) CheckIn(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.CheckInRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	trip, err := h.CheckInRepository.GetTrip(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	scan := models.CheckIn{GuideId: policy.FromRequest(r).Id, Source: models.CheckInOnline}
+	result, err := h.checkIn(trip, *request, scan)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	switch result.Status {
+	case checkInOk:
+		w.WriteHeader(http.StatusOK)
+		response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+		json.NewEncoder(w).Encode(response)
+	case checkInInvalid:
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: result.Message}
+		json.NewEncoder(w).Encode(response)
+	default:
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: result.Message}
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// function CheckInBatch menerima pindaian yang disimpan hp guide saat tidak ada sinyal. setiap pindaian diproses
+// sesuai urutan waktu pindai dan mendapat hasilnya sendiri, sehingga satu tiket yang ditolak tidak membatalkan yang lain
+func (h *handlerCheckIn) CheckInBatch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.CheckInBatchRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	trip, err := h.CheckInRepository.GetTrip(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// pindaian tanpa waktu dianggap terjadi saat sinkronisasi, jadi diproses paling akhir
+	scans := append([]dto.CheckInRequest{}, request.Scans...)
+	syncedAt := time.Now()
+	for i := range scans {
+		if scans[i].ScannedAt == nil {
+			scans[i].ScannedAt = &syncedAt
+		}
+	}
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].ScannedAt.Before(*scans[j].ScannedAt) })
+
+	guideId := policy.FromRequest(r).Id
+	result := dto.CheckInBatchResponse{Total: len(scans), Results: []dto.CheckInResult{}}
+	for _, scan := range scans {
+		item, err := h.checkIn(trip, scan, models.CheckIn{GuideId: guideId, Source: models.CheckInOffline, DeviceId: request.DeviceId})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		item.Code = scan.Code
+
+		switch item.Status {
+		case checkInOk:
+			result.CheckedIn++
+		case checkInDuplicate:
+			result.Duplicate++
+		default:
+			result.Rejected++
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function GetRoster menampilkan daftar penumpang keberangkatan beserta status check-in
+func (h *handlerCheckIn) GetRoster(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	trip, err := h.CheckInRepository.GetTrip(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	bookings, err := h.CheckInRepository.FindTripBookings(trip.Id)
+	var checkIns []models.CheckIn
+	if err == nil {
+		checkIns, err = h.CheckInRepository.FindCheckIns(trip.Id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	checkedIn := map[string]models.CheckIn{}
+	for _, checkIn := range checkIns {
+		checkedIn[ticketReference(checkIn.TransactionId, checkIn.Seat)] = checkIn
+	}
+
+	roster := dto.RosterResponse{TripId: trip.Id, Title: trip.Title, DateTrip: trip.DateTrip, Roster: []dto.RosterPassenger{}}
+	for _, booking := range bookings {
+		for i, passenger := range seatPassengers(booking) {
+			item := dto.RosterPassenger{
+				TransactionId: booking.Id,
+				Seat:          i + 1,
+				Reference:     ticketReference(booking.Id, i+1),
+				Passenger:     passenger,
+				BookedBy:      booking.User.Name,
+				Phone:         booking.User.Phone,
+			}
+			if checkIn, ok := checkedIn[item.Reference]; ok {
+				scannedAt := checkIn.ScannedAt
+				item.CheckedIn = true
+				item.CheckedInAt = &scannedAt
+				item.Source = checkIn.Source
+				roster.CheckedIn++
+			}
+			roster.Roster = append(roster.Roster, item)
+		}
+	}
+	roster.Passengers = len(roster.Roster)
+	roster.Remaining = roster.Passengers - roster.CheckedIn
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: roster}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function checkIn memeriksa satu QR code untuk trip lalu menyimpan check-in. scan berisi guide, sumber dan device.
+// error hanya dikembalikan untuk kegagalan database, tiket yang ditolak dilaporkan lewat Status
+func (h *handlerCheckIn) checkIn(trip models.Trip, request dto.CheckInRequest, scan models.CheckIn) (dto.CheckInResult, error) {
+	transactionId, seat, ok := parseTicketCode(request.Code)
+	if !ok {
+		return dto.CheckInResult{Status: checkInInvalid, Message: "invalid ticket code"}, nil
+	}
+	result := dto.CheckInResult{TransactionId: transactionId, Seat: seat, Reference: ticketReference(transactionId, seat)}
+
+	transaction, err := h.CheckInRepository.GetTransaction(transactionId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		result.Status, result.Message = checkInInvalid, "booking not found"
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	passengers := seatPassengers(transaction)
+	if seat < 1 || seat > len(passengers) {
+		result.Status, result.Message = checkInInvalid, "seat is not part of the booking"
+		return result, nil
+	}
+	result.Passenger = passengers[seat-1]
+
+	if transaction.TripId != trip.Id {
+		result.Status = checkInWrongDeparture
+		result.Message = fmt.Sprintf("ticket is for %s departing %s", transaction.Trip.Title, transaction.Trip.DateTrip.Format("2 January 2006"))
+		return result, nil
+	}
+	if transaction.Status != "success" {
+		result.Status, result.Message = checkInNotPaid, "booking is not paid"
+		return result, nil
+	}
+
+	// waktu pindai dari hp tidak boleh lebih dari waktu server
+	now := time.Now()
+	scan.ScannedAt = now
+	if request.ScannedAt != nil && request.ScannedAt.Before(now) {
+		scan.ScannedAt = *request.ScannedAt
+	}
+	scan.TransactionId = transactionId
+	scan.Seat = seat
+	scan.TripId = trip.Id
+	scan.Passenger = result.Passenger
+	scan.DeviceId = strings.TrimSpace(scan.DeviceId)
+
+	checkIn, created, err := h.CheckInRepository.CheckInPassenger(scan)
+	if err != nil {
+		return result, err
+	}
+	result.CheckedInAt = &checkIn.ScannedAt
+	if !created {
+		result.Status = checkInDuplicate
+		result.Message = fmt.Sprintf("%s already checked in at %s", result.Passenger, checkIn.ScannedAt.In(timeIn("Asia/Jakarta").Location()).Format("15:04"))
+		return result, nil
+	}
+
+	result.Status = checkInOk
+	return result, nil
+}
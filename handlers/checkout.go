@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/money"
+	"project/pkg/pricing"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+	"gorm.io/gorm"
+)
+
+// checkoutRepository adalah data yang dibutuhkan untuk menghitung harga booking
+type checkoutRepository interface {
+	FindPricingRules(TripId int) ([]models.PricingRule, error)
+	FindAddOnsByIds(TripId int, ids []int) ([]models.AddOn, error)
+	GetPromoCodeByCode(code string) (models.PromoCode, error)
+	CountPromoUses(PromoCodeId int, UserId int) (int, error)
+	FindTaxRules(activeOnly bool) ([]models.TaxRule, error)
+}
+
+// bookingOrder adalah pilihan user saat booking
+type bookingOrder struct {
+	Passengers map[string]int
+	Quantity   int
+	// id add-on dan jumlahnya, jumlah 0 berarti sesuai jumlah penumpang (per orang) atau 1 (per booking)
+	AddOns    map[int]int
+	PromoCode string
+}
+
+// bookingPrice adalah hasil perhitungan harga booking di server. Subtotal adalah harga tiket, tambahan dan add-on,
+// Discount adalah semua potongan (early bird, rombongan dan promo code), Fees adalah biaya layanan dan Tax adalah pajak.
+// Total = Subtotal - Discount + Fees + Tax
+type bookingPrice struct {
+	Items     []models.TransactionItem
+	Subtotal  int
+	Discount  int
+	Fees      int
+	Tax       int
+	Total     int
+	PromoCode models.PromoCode
+}
+
+// function priceBooking menghitung rincian harga booking dari aturan harga trip, add-on dan promo code.
+// total dari client tidak pernah dipakai. pilihan yang tidak bisa dipakai menghasilkan pricing.Error
+func priceBooking(repo checkoutRepository, userId int, trip models.Trip, order bookingOrder) (bookingPrice, error) {
+	var price bookingPrice
+
+	rules, err := repo.FindPricingRules(trip.Id)
+	if err != nil {
+		return price, err
+	}
+
+	now := time.Now()
+	price.Items = pricing.Evaluate(rules, pricing.Booking{
+		TripPrice:  int(trip.Price.Amount),
+		DepartAt:   trip.DateTrip,
+		BookedAt:   now,
+		Passengers: order.Passengers,
+	})
+
+	addOnItems, err := priceAddOns(repo, trip, order)
+	if err != nil {
+		return price, err
+	}
+	price.Items = append(price.Items, addOnItems...)
+
+	for _, item := range price.Items {
+		if item.Amount > 0 {
+			price.Subtotal += item.Amount
+		} else {
+			price.Discount -= item.Amount
+		}
+	}
+
+	// promo code dihitung dari harga setelah potongan aturan harga
+	if code := pricing.NormalizeCode(order.PromoCode); code != "" {
+		promo, err := repo.GetPromoCodeByCode(code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return price, pricing.ErrPromoNotFound
+		}
+		if err != nil {
+			return price, err
+		}
+
+		uses, err := repo.CountPromoUses(promo.Id, userId)
+		if err != nil {
+			return price, err
+		}
+
+		payable := price.Subtotal - price.Discount
+		err = pricing.CheckPromo(promo, pricing.PromoContext{
+			TripId:    trip.Id,
+			CountryId: trip.CountryId,
+			Subtotal:  payable,
+			UserUses:  uses,
+			Now:       now,
+		})
+		if err != nil {
+			return price, err
+		}
+
+		discount := pricing.PromoDiscount(promo, payable)
+		price.PromoCode = promo
+		price.Discount += discount
+		price.Items = append(price.Items, models.TransactionItem{
+			Kind:        models.ItemDiscount,
+			Code:        promo.Code,
+			Description: pricing.PromoDescription(promo),
+			Quantity:    1,
+			UnitAmount:  -discount,
+			Amount:      -discount,
+		})
+	}
+
+	err = applyCharges(repo, &price, trip, order.Quantity)
+	return price, err
+}
+
+// function applyCharges menambahkan pajak dan biaya layanan lalu menghitung Total. dipanggil terakhir karena
+// keduanya dihitung dari harga setelah semua potongan
+func applyCharges(repo checkoutRepository, price *bookingPrice, trip models.Trip, quantity int) error {
+	taxRules, err := repo.FindTaxRules(true)
+	if err != nil {
+		return err
+	}
+	charges := pricing.Charges(taxRules, pricing.Charge{
+		TripType:  trip.Type,
+		CountryId: trip.CountryId,
+		Quantity:  quantity,
+		Payable:   price.Subtotal - price.Discount,
+	})
+	for _, item := range charges {
+		if item.Kind == models.ItemTax {
+			price.Tax += item.Amount
+		} else {
+			price.Fees += item.Amount
+		}
+	}
+	price.Items = append(price.Items, charges...)
+
+	price.Total = price.Subtotal - price.Discount + price.Fees + price.Tax
+	return nil
+}
+
+// function priceAddOns membuat baris harga add-on yang dipilih. stok hanya diperiksa di sini,
+// stok benar-benar dikurangi saat transaction disimpan
+func priceAddOns(repo checkoutRepository, trip models.Trip, order bookingOrder) ([]models.TransactionItem, error) {
+	if len(order.AddOns) == 0 {
+		return nil, nil
+	}
+
+	var ids []int
+	for id := range order.AddOns {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	addOns, err := repo.FindAddOnsByIds(trip.Id, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := map[int]models.AddOn{}
+	for _, addOn := range addOns {
+		found[addOn.Id] = addOn
+	}
+
+	var items []models.TransactionItem
+	for _, id := range ids {
+		addOn, ok := found[id]
+		if !ok {
+			return nil, pricing.ErrAddOnNotFound
+		}
+
+		quantity := order.AddOns[id]
+		if addOn.PerPerson {
+			if quantity <= 0 {
+				quantity = order.Quantity
+			}
+			if quantity > order.Quantity {
+				return nil, pricing.Error(fmt.Sprintf("%s can be added at most once per passenger", addOn.Name))
+			}
+		} else if quantity <= 0 {
+			quantity = 1
+		}
+		if addOn.Stock > 0 && addOn.Sold+quantity > addOn.Stock {
+			return nil, pricing.Error(fmt.Sprintf("%s is sold out", addOn.Name))
+		}
+
+		items = append(items, models.TransactionItem{
+			Kind:        models.ItemAddOn,
+			Code:        fmt.Sprintf("add_on:%d", addOn.Id),
+			Description: addOn.Name,
+			Quantity:    quantity,
+			UnitAmount:  addOn.Price,
+			Amount:      addOn.Price * quantity,
+			AddOnId:     addOn.Id,
+		})
+	}
+	return items, nil
+}
+
+// function bookingPassengers menentukan jumlah penumpang per jenis. tanpa rincian semua penumpang dianggap dewasa,
+// jika quantity 0 jumlahnya diambil dari rincian penumpang
+func bookingPassengers(quantity int, passengers map[string]int) (map[string]int, int, error) {
+	if len(passengers) == 0 {
+		if quantity <= 0 {
+			return nil, 0, errors.New("counter_qty must be at least 1")
+		}
+		return map[string]int{models.PassengerAdult: quantity}, quantity, nil
+	}
+
+	var total int
+	for passengerType, count := range passengers {
+		if !pricing.ValidPassengerType(passengerType) {
+			return nil, 0, fmt.Errorf("unknown passenger type %q", passengerType)
+		}
+		if count < 0 {
+			return nil, 0, errors.New("passenger count must not be negative")
+		}
+		total += count
+	}
+	if total <= 0 {
+		return nil, 0, errors.New("at least one passenger is required")
+	}
+	if quantity > 0 && quantity != total {
+		return nil, 0, errors.New("passengers must add up to counter_qty")
+	}
+	return passengers, total, nil
+}
+
+// function parsePassengers membaca rincian penumpang dari form, misal "adult:2,child:1"
+func parsePassengers(value string) map[string]int {
+	passengers := map[string]int{}
+	for _, part := range strings.Split(value, ",") {
+		name, count, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			continue
+		}
+		passengers[strings.TrimSpace(name)] += n
+	}
+	return passengers
+}
+
+// function parseAddOns membaca add-on yang dipilih dari form, misal "3:2,5" (add-on 5 dengan jumlah default)
+func parseAddOns(value string) map[int]int {
+	addOns := map[int]int{}
+	for _, part := range strings.Split(value, ",") {
+		idText, quantityText, _ := strings.Cut(strings.TrimSpace(part), ":")
+		id, err := strconv.Atoi(strings.TrimSpace(idText))
+		if err != nil || id <= 0 {
+			continue
+		}
+		quantity, _ := strconv.Atoi(strings.TrimSpace(quantityText))
+		addOns[id] += quantity
+	}
+	return addOns
+}
+
+// function addOnSelections menggabungkan add-on dari body json dengan add-on dari form
+func addOnSelections(selections []dto.AddOnSelection, addOns map[int]int) map[int]int {
+	if addOns == nil {
+		addOns = map[int]int{}
+	}
+	for _, selection := range selections {
+		if selection.AddOnId > 0 {
+			addOns[selection.AddOnId] += selection.Quantity
+		}
+	}
+	return addOns
+}
+
+// function midtransItems mengubah rincian harga menjadi item_details midtrans. jumlahnya sama dengan gross amount
+// karena setiap baris memenuhi Amount = UnitAmount x Quantity. booking lama tanpa rincian tidak mengirim item_details
+func midtransItems(items []models.TransactionItem) *[]midtrans.ItemDetails {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var details []midtrans.ItemDetails
+	for i, item := range items {
+		id := item.Code
+		if id == "" {
+			id = fmt.Sprintf("%s-%d", item.Kind, i+1)
+		}
+		details = append(details, midtrans.ItemDetails{
+			ID:    truncate(id, 50),
+			Name:  truncate(item.Description, 50),
+			Price: int64(item.UnitAmount),
+			Qty:   int32(item.Quantity),
+		})
+	}
+	return &details
+}
+
+// function itemsHTML menampilkan rincian harga di email
+func itemsHTML(items []models.TransactionItem) string {
+	var result string
+	for _, item := range items {
+		label := item.Description
+		if item.Code != "" {
+			label = item.Code + " - " + label
+		}
+		if item.Quantity > 1 {
+			label = fmt.Sprintf("%s x %d", label, item.Quantity)
+		}
+		result += fmt.Sprintf("<li>%s : %s</li>", html.EscapeString(label), money.IDR(int64(item.Amount)))
+	}
+	return result
+}
+
+// function totalsHTML menampilkan rincian total yang tersimpan di transaction untuk email
+func totalsHTML(transaction models.Transaction) string {
+	return fmt.Sprintf("<li>Subtotal : %s</li><li>Discount : %s</li><li>Service fee : %s</li><li>Tax : %s</li><li>Grand total : %s</li>",
+		transaction.Subtotal, transaction.Discount, transaction.Fees, transaction.Tax, transaction.Total)
+}
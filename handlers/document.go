@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/encryption"
+	"project/pkg/middleware"
+	"project/pkg/policy"
+	"project/pkg/vault"
+	"project/repositories"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// maksimal ukuran dokumen 10mb
+const documentMaxBytes = 10 << 20
+
+// purpose tanda tangan signed url download dokumen
+const documentURLPurpose = "document_url"
+
+// tipe file yang boleh diupload ke vault
+var documentContentTypes = map[string]bool{"application/pdf": true, "image/jpeg": true, "image/png": true}
+
+var documentKinds = map[string]bool{"passport": true, "visa": true, "national_id": true, "other": true}
+
+type handlerDocument struct {
+	DocumentRepository repositories.DocumentRepository
+	Store              *vault.Store
+}
+
+func HandlerDocument(DocumentRepository repositories.DocumentRepository, Store *vault.Store) *handlerDocument {
+	return &handlerDocument{DocumentRepository, Store}
+}
+
+// function UploadDocument menyimpan scan paspor/visa ke vault. file dienkripsi dan tidak bisa diakses lewat /uploads
+func (h *handlerDocument) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	r.Body = http.MaxBytesReader(w, r.Body, documentMaxBytes+1<<20)
+	if err := r.ParseMultipartForm(documentMaxBytes); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "file is required and must be at most 10mb"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	kind := r.FormValue("kind")
+	if !documentKinds[kind] {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "kind must be one of passport, visa, national_id, other"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// dokumen boleh dihubungkan ke traveler tersimpan milik user
+	travelerId, _ := strconv.Atoi(r.FormValue("traveler_id"))
+	if travelerId != 0 {
+		traveler, err := h.DocumentRepository.GetTraveler(travelerId)
+		if err != nil || traveler.UserId != userId {
+			w.WriteHeader(http.StatusBadRequest)
+			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "traveler not found"}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "file is required"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, documentMaxBytes+1))
+	if err != nil || len(data) == 0 || len(data) > documentMaxBytes {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "file is required and must be at most 10mb"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// tipe file dicek dari isinya, bukan dari nama file
+	contentType := http.DetectContentType(data)
+	if !documentContentTypes[contentType] {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "only pdf, jpeg and png files are allowed"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	storageKey, wrappedKey, err := h.Store.Put(data)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	document := models.Document{
+		UserId:      userId,
+		TravelerId:  travelerId,
+		Kind:        kind,
+		FileName:    truncate(filepath.Base(header.Filename), 255),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  storageKey,
+		WrappedKey:  wrappedKey,
+		ExpiresAt:   time.Now().Add(documentRetention()),
+	}
+	document, err = h.DocumentRepository.CreateDocument(document)
+	if err != nil {
+		h.Store.Delete(storageKey)
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	h.logAccess(r, document.Id, policy.FromRequest(r), "upload")
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseDocument(document)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function FindDocuments menampilkan dokumen milik user yang login
+func (h *handlerDocument) FindDocuments(w http.ResponseWriter, r *http.Request) {
+	h.writeDocuments(w, policy.FromRequest(r).Id)
+}
+
+// function FindUserDocuments menampilkan dokumen user lain untuk staff
+func (h *handlerDocument) FindUserDocuments(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	h.writeDocuments(w, id)
+}
+
+func (h *handlerDocument) writeDocuments(w http.ResponseWriter, userId int) {
+	w.Header().Set("Content-Type", "application/json")
+
+	documents, err := h.DocumentRepository.FindDocumentsByUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.DocumentResponse{}
+	for _, d := range documents {
+		result = append(result, convertResponseDocument(d))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function CreateDocumentURL membuat signed url download yang hanya berlaku beberapa menit
+func (h *handlerDocument) CreateDocumentURL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	document, err := h.authorizeDocument(r, policy.DocumentReadAny)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	subject := policy.FromRequest(r)
+	expiresAt := time.Now().Add(documentURLTTL())
+	signature, err := encryption.Sign(documentURLPurpose, documentURLMessage(document.Id, subject.Id, expiresAt.Unix()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	query := url.Values{}
+	query.Set("accessor", strconv.Itoa(subject.Id))
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("sig", signature)
+	link := fmt.Sprintf("%s/api/v1/document/%d/download?%s", baseURL(), document.Id, query.Encode())
+
+	h.logAccess(r, document.Id, subject, "url_issued")
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: dto.DocumentURLResponse{URL: link, ExpiresAt: expiresAt}}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function DownloadDocument mengirim isi dokumen yang sudah didekripsi. tidak memakai token login,
+// akses dibuktikan dengan tanda tangan signed url dari CreateDocumentURL
+func (h *handlerDocument) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	accessor, _ := strconv.Atoi(r.URL.Query().Get("accessor"))
+	expires, _ := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+
+	if time.Now().Unix() > expires || !encryption.Verify(documentURLPurpose, documentURLMessage(id, accessor, expires), r.URL.Query().Get("sig")) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		response := dto.ErrorResult{Code: http.StatusForbidden, Message: "link is invalid or has expired"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	document, err := h.DocumentRepository.GetDocument(id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
+
+	data, err := h.Store.Get(document.StorageKey, document.WrappedKey)
+	if err != nil {
+		log.Println("document vault:", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: "document is not available"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	h.logAccess(r, document.Id, policy.Subject{Id: accessor}, "download")
+
+	w.Header().Set("Content-Type", document.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+strings.ReplaceAll(document.FileName, `"`, "")+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func (h *handlerDocument) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	document, err := h.authorizeDocument(r, policy.DocumentDeleteAny)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if err := h.Store.Delete(document.StorageKey); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if err := h.DocumentRepository.DeleteDocument(document); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	h.logAccess(r, document.Id, policy.FromRequest(r), "delete")
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseDocument(document)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function FindDocumentAccessLogs menampilkan siapa saja yang pernah mengakses dokumen
+func (h *handlerDocument) FindDocumentAccessLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	document, err := h.authorizeDocument(r, policy.DocumentReadAny)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	logs, err := h.DocumentRepository.FindDocumentAccessLogs(document.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: logs}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function authorizeDocument mengambil dokumen dari parameter {id}, hanya pemilik atau staff dengan permission yang boleh
+func (h *handlerDocument) authorizeDocument(r *http.Request, permission policy.Permission) (models.Document, error) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	document, err := h.DocumentRepository.GetDocument(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return document, policy.ErrNotFound
+	}
+	if err != nil {
+		return document, err
+	}
+
+	if err := policy.Authorize(policy.FromRequest(r), permission, document.UserId); err != nil {
+		return document, err
+	}
+	return document, nil
+}
+
+func (h *handlerDocument) logAccess(r *http.Request, documentId int, subject policy.Subject, action string) {
+	entry := models.DocumentAccessLog{
+		DocumentId:     documentId,
+		UserId:         subject.Id,
+		ImpersonatorId: subject.ImpersonatorId,
+		Action:         action,
+		IP:             middleware.ClientIP(r),
+	}
+	if err := h.DocumentRepository.CreateDocumentAccessLog(entry); err != nil {
+		log.Println("document access log:", err)
+	}
+}
+
+func documentURLMessage(documentId int, accessorId int, expires int64) string {
+	return fmt.Sprintf("%d:%d:%d", documentId, accessorId, expires)
+}
+
+// function documentURLTTL mengambil masa berlaku signed url dari env DOCUMENT_URL_TTL_SECONDS (default 5 menit)
+func documentURLTTL() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("DOCUMENT_URL_TTL_SECONDS"))
+	if err != nil || seconds <= 0 {
+		seconds = 300
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// function documentRetention mengambil masa simpan dokumen dari env DOCUMENT_RETENTION_DAYS (default 365 hari)
+func documentRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("DOCUMENT_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 365
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func convertResponseDocument(d models.Document) dto.DocumentResponse {
+	return dto.DocumentResponse{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		TravelerId:  d.TravelerId,
+		Kind:        d.Kind,
+		FileName:    d.FileName,
+		ContentType: d.ContentType,
+		Size:        d.Size,
+		CreatedAt:   d.CreatedAt,
+		ExpiresAt:   d.ExpiresAt,
+	}
+}
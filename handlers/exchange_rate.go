@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/money"
+	"project/pkg/policy"
+	"project/repositories"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerExchangeRate struct {
+	ExchangeRateRepository repositories.ExchangeRateRepository
+}
+
+func HandlerExchangeRate(ExchangeRateRepository repositories.ExchangeRateRepository) *handlerExchangeRate {
+	return &handlerExchangeRate{ExchangeRateRepository}
+}
+
+func (h *handlerExchangeRate) FindExchangeRates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rates, err := h.ExchangeRateRepository.FindExchangeRates()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.ExchangeRateResponse{}
+	for _, rate := range rates {
+		result = append(result, convertResponseExchangeRate(rate))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function UpdateExchangeRates menyimpan tabel kurs dari admin. jika ada satu kurs yang tidak valid maka tidak ada yang disimpan
+func (h *handlerExchangeRate) UpdateExchangeRates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdateExchangeRatesRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	updatedById := policy.FromRequest(r).Id
+	rates := []models.ExchangeRate{}
+	for code, value := range request.Rates {
+		currency, err := money.Normalize(code)
+		if err == nil && currency == money.Settlement {
+			err = errors.New("IDR is the settlement currency and has no exchange rate")
+		}
+		var rate *big.Rat
+		if err == nil {
+			rate, err = money.ParseRate(value)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: code + ": " + err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		rates = append(rates, models.ExchangeRate{Currency: currency, Rate: rate.FloatString(8), UpdatedById: updatedById})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Currency < rates[j].Currency })
+
+	rates, err := h.ExchangeRateRepository.SaveExchangeRates(rates)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	detail := map[string]string{}
+	result := []dto.ExchangeRateResponse{}
+	for _, rate := range rates {
+		detail[rate.Currency] = rate.Rate
+		result = append(result, convertResponseExchangeRate(rate))
+	}
+	audit(h.ExchangeRateRepository, r, "exchange_rate.updated", 0, detail)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function DeleteExchangeRate menghapus kurs, setelah itu harga tidak bisa ditampilkan dalam mata uang tersebut
+func (h *handlerExchangeRate) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	currency := strings.ToUpper(mux.Vars(r)["currency"])
+	rate, err := h.ExchangeRateRepository.GetExchangeRate(currency)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "exchange rate not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err == nil {
+		err = h.ExchangeRateRepository.DeleteExchangeRate(rate)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.ExchangeRateRepository, r, "exchange_rate.deleted", 0, map[string]interface{}{"currency": rate.Currency, "rate": rate.Rate})
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseExchangeRate(rate)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// exchangeRateRepository dipakai handler trip dan quote untuk mengambil kurs mata uang tampilan
+type exchangeRateRepository interface {
+	GetExchangeRate(currency string) (models.ExchangeRate, error)
+}
+
+// function displayConverter membuat Converter untuk parameter currency. currency kosong atau IDR menghasilkan nil (tanpa konversi)
+func displayConverter(repo exchangeRateRepository, currency string) (*money.Converter, error) {
+	if strings.TrimSpace(currency) == "" {
+		return nil, nil
+	}
+	currency, err := money.Normalize(currency)
+	if err != nil || currency == money.Settlement {
+		return nil, err
+	}
+
+	rate, err := repo.GetExchangeRate(currency)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, money.ErrNoRate
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := money.NewConverter(currency, rate.Rate)
+	if err != nil {
+		return nil, err
+	}
+	return &converter, nil
+}
+
+// function currencyErrorStatus membedakan parameter currency yang salah (400) dengan error database (500)
+func currencyErrorStatus(err error) int {
+	if errors.Is(err, money.ErrUnsupportedCurrency) || errors.Is(err, money.ErrNoRate) || errors.Is(err, money.ErrInvalidRate) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func convertResponseExchangeRate(rate models.ExchangeRate) dto.ExchangeRateResponse {
+	return dto.ExchangeRateResponse{
+		Currency:  rate.Currency,
+		Rate:      rate.Rate,
+		UpdatedAt: rate.UpdatedAt,
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	dto "project/dto"
+	"project/models"
+	jwtToken "project/pkg/jwt"
+	"project/pkg/middleware"
+	"project/pkg/policy"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// function Impersonate membuat token berumur pendek agar admin (support) bisa melihat aplikasi sebagai user.
+// token membawa id user dan id admin (claim "imp"), route kredensial dan pembayaran ditolak oleh middleware Auth
+func (h *handlerAuth) Impersonate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.ImpersonateRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	admin, err := h.AuthRepository.Getuser(int(userInfo["id"].(float64)))
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	user, err := h.AuthRepository.Getuser(id)
+	if err != nil {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
+
+	// admin lain tidak bisa diimpersonate agar hak akses admin tidak bisa dipinjam
+	if user.Id == admin.Id || (policy.Subject{Role: user.Role}).Has(policy.UserImpersonate) {
+		policy.Deny(w, policy.ErrForbidden)
+		return
+	}
+	if user.SuspendedAt != nil || user.AnonymizedAt != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "user is not active"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	ttl := impersonationTTL()
+	if request.Minutes > 0 && time.Duration(request.Minutes)*time.Minute < ttl {
+		ttl = time.Duration(request.Minutes) * time.Minute
+	}
+
+	tokenId, err := randomToken()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserId:         user.Id,
+		TokenId:        tokenId,
+		Device:         "Support (impersonation)",
+		IP:             middleware.ClientIP(r),
+		UserAgent:      truncate(r.UserAgent(), 512),
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(ttl),
+		ImpersonatorId: admin.Id,
+	}
+	if _, err := h.AuthRepository.CreateSession(session); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	claims := jwt.MapClaims{}
+	claims["id"] = user.Id
+	claims["role"] = user.Role
+	claims["email"] = user.Email
+	claims["sid"] = tokenId
+	claims["ver"] = user.TokenVersion
+	claims["imp"] = admin.Id               // admin yang impersonate, dicatat di setiap request
+	claims["imp_ver"] = admin.TokenVersion // token ikut tidak berlaku jika sesi admin dicabut
+	claims["exp"] = session.ExpiresAt.Unix()
+
+	token, err := jwtToken.GenerateToken(&claims)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.AuthRepository, r, "user.impersonation_started", user.Id, map[string]interface{}{
+		"reason":     request.Reason,
+		"expires_at": session.ExpiresAt,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: dto.ImpersonateResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		UserId:    user.Id,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+	}}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function impersonationTTL mengambil batas lama token impersonation dari env IMPERSONATION_TTL_MINUTES (default 30 menit)
+func impersonationTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("IMPERSONATION_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 30
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/encryption"
+	"project/pkg/mail"
+	"project/pkg/pdf"
+	"project/pkg/policy"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// purpose tanda tangan QR code e-ticket, lihat encryption.Sign
+const ticketPurpose = "ticket"
+
+// function GetInvoice mengunduh invoice pdf. invoice dibuat saat pembayaran berhasil, booking lama yang sudah dibayar
+// mendapat nomor invoice saat pertama kali diunduh
+func (h *handlerTransaction) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	transaction, err := h.authorizeTransaction(r, id, policy.TransactionReadAny)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		policy.Deny(w, err)
+		return
+	}
+	if transaction.Status != "success" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "invoice is available after payment"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	invoice, err := h.TransactionRepository.IssueInvoice(transaction, timeIn("Asia/Jakarta"))
+	var data []byte
+	if err == nil {
+		data, err = pdf.Invoice(invoice, transaction)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	writePDF(w, invoiceFilename(invoice), data)
+}
+
+// function GetTicket mengunduh e-ticket pdf, satu halaman dengan QR code untuk setiap penumpang
+func (h *handlerTransaction) GetTicket(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	transaction, err := h.authorizeTransaction(r, id, policy.TransactionReadAny)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		policy.Deny(w, err)
+		return
+	}
+	if transaction.Status != "success" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "e-ticket is available after payment"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	tickets, err := bookingTickets(transaction)
+	var data []byte
+	if err == nil {
+		data, err = pdf.Tickets(transaction, tickets)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	writePDF(w, ticketFilename(transaction), data)
+}
+
+// function confirmationAttachments membuat invoice dan e-ticket untuk email konfirmasi pembayaran.
+// jika gagal email tetap dikirim tanpa lampiran, user masih bisa mengunduhnya lewat endpoint
+func (h *handlerTransaction) confirmationAttachments(transaction models.Transaction) []mail.Attachment {
+	var attachments []mail.Attachment
+
+	invoice, err := h.TransactionRepository.IssueInvoice(transaction, timeIn("Asia/Jakarta"))
+	if err == nil {
+		var data []byte
+		if data, err = pdf.Invoice(invoice, transaction); err == nil {
+			attachments = append(attachments, mail.Attachment{Filename: invoiceFilename(invoice), ContentType: "application/pdf", Data: data})
+		}
+	}
+	if err != nil {
+		log.Printf("invoice %d: %v", transaction.Id, err)
+	}
+
+	tickets, err := bookingTickets(transaction)
+	if err == nil {
+		var data []byte
+		if data, err = pdf.Tickets(transaction, tickets); err == nil {
+			attachments = append(attachments, mail.Attachment{Filename: ticketFilename(transaction), ContentType: "application/pdf", Data: data})
+		}
+	}
+	if err != nil {
+		log.Printf("e-ticket %d: %v", transaction.Id, err)
+	}
+
+	return attachments
+}
+
+// function bookingTickets membuat satu e-ticket untuk setiap kursi
+func bookingTickets(transaction models.Transaction) ([]pdf.Ticket, error) {
+	passengers := seatPassengers(transaction)
+
+	var tickets []pdf.Ticket
+	for i, passenger := range passengers {
+		seat := i + 1
+		code, err := ticketCode(transaction.Id, seat)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, pdf.Ticket{
+			Reference: ticketReference(transaction.Id, seat),
+			Code:      code,
+			Passenger: passenger,
+			Seat:      seat,
+			Seats:     len(passengers),
+		})
+	}
+	return tickets, nil
+}
+
+// function seatPassengers mengembalikan nama penumpang untuk setiap kursi (index 0 = kursi 1). kursi diisi traveler
+// sesuai urutan, kursi tanpa traveler tersimpan ditulis atas nama pemesan
+func seatPassengers(transaction models.Transaction) []string {
+	travelers := append([]models.TransactionTraveler{}, transaction.Travelers...)
+	sort.Slice(travelers, func(i, j int) bool { return travelers[i].Id < travelers[j].Id })
+
+	seats := transaction.CounterQty
+	if len(travelers) > seats {
+		seats = len(travelers)
+	}
+
+	passengers := make([]string, seats)
+	for i := range passengers {
+		passengers[i] = fmt.Sprintf("%s (guest %d)", transaction.User.Name, i+1)
+		if i < len(travelers) {
+			passengers[i] = travelers[i].FullName
+		}
+	}
+	return passengers
+}
+
+// function ticketReference membuat referensi booking per penumpang, misal DWT-1700000000-2
+func ticketReference(transactionId int, seat int) string {
+	return fmt.Sprintf("DWT-%d-%d", transactionId, seat)
+}
+
+// function ticketCode membuat isi QR code: referensi booking diikuti tanda tangan HMAC agar tidak bisa dipalsukan
+func ticketCode(transactionId int, seat int) (string, error) {
+	reference := ticketReference(transactionId, seat)
+	signature, err := encryption.Sign(ticketPurpose, reference)
+	if err != nil {
+		return "", err
+	}
+	return reference + "." + signature, nil
+}
+
+// function parseTicketCode memeriksa tanda tangan isi QR code lalu mengambil id transaction dan nomor kursi
+func parseTicketCode(code string) (transactionId int, seat int, ok bool) {
+	reference, signature, found := strings.Cut(strings.TrimSpace(code), ".")
+	if !found || !encryption.Verify(ticketPurpose, reference, signature) {
+		return 0, 0, false
+	}
+
+	if _, err := fmt.Sscanf(reference, "DWT-%d-%d", &transactionId, &seat); err != nil || ticketReference(transactionId, seat) != reference {
+		return 0, 0, false
+	}
+	return transactionId, seat, true
+}
+
+func invoiceFilename(invoice models.Invoice) string {
+	return strings.ReplaceAll(invoice.Number, "/", "-") + ".pdf"
+}
+
+func ticketFilename(transaction models.Transaction) string {
+	return fmt.Sprintf("e-ticket-%d.pdf", transaction.Id)
+}
+
+func writePDF(w http.ResponseWriter, filename string, data []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	dto "project/dto"
+	"project/models"
+	jwtToken "project/pkg/jwt"
+	"project/pkg/ratelimit"
+	"project/pkg/totp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	mfaTokenTTL       = time.Minute * 5
+	recoveryCodeCount = 10
+	totpIssuer        = "dewetour"
+)
+
+// setiap user maksimal 5 kali mencoba kode 2FA per 5 menit
+var mfaAttemptLimiter = ratelimit.New(5, time.Minute*5)
+
+var errInvalidMFACode = errors.New("invalid authentication code")
+
+// function mfaRequired mengecek apakah role wajib memakai 2FA. daftar role diambil dari env MFA_REQUIRED_ROLES (default admin)
+func mfaRequired(role string) bool {
+	roles, ok := os.LookupEnv("MFA_REQUIRED_ROLES")
+	if !ok {
+		roles = "admin"
+	}
+	for _, r := range strings.Split(roles, ",") {
+		if strings.TrimSpace(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// function generateMFAToken membuat token sementara untuk langkah kedua login
+func generateMFAToken(user models.User, purpose string) (string, error) {
+	claims := jwt.MapClaims{}
+	claims["id"] = user.Id
+	claims["role"] = user.Role
+	claims["ver"] = user.TokenVersion
+	claims["exp"] = time.Now().Add(mfaTokenTTL).Unix()
+
+	return jwtToken.GeneratePurposeToken(purpose, claims)
+}
+
+// function LoginMFA menukar token sementara dan kode 2FA (atau kode cadangan) dengan token login
+func (h *handlerAuth) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.LoginMFARequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	claims, err := jwtToken.DecodePurposeToken(jwtToken.PurposeMFALogin, request.MFAToken)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		response := dto.ErrorResult{Code: http.StatusUnauthorized, Message: "invalid or expired mfa token"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	userId, _ := claims["id"].(float64)
+	user, err := h.AuthRepository.Getuser(int(userId))
+	version, _ := claims["ver"].(float64)
+	if err != nil || !user.TotpEnabled || int(version) != user.TokenVersion {
+		w.WriteHeader(http.StatusUnauthorized)
+		response := dto.ErrorResult{Code: http.StatusUnauthorized, Message: "invalid or expired mfa token"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if message := accountLoginBlocked(user); message != "" {
+		w.WriteHeader(http.StatusForbidden)
+		response := dto.ErrorResult{Code: http.StatusForbidden, Message: message}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.verifySecondFactor(user, request.Code, true); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	token, err := h.generateLoginToken(user, r)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	loginResponse := dto.LoginResponse{
+		Name:  user.Name,
+		Email: user.Email,
+		Token: token,
+		Role:  user.Role,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: loginResponse}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function SetupTotp membuat secret baru dan qr code untuk di-scan aplikasi authenticator. 2FA belum aktif sampai EnableTotp
+func (h *handlerAuth) SetupTotp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	user, err := h.AuthRepository.Getuser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if user.TotpEnabled {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "two-factor authentication is already enabled"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err == nil {
+		err = h.AuthRepository.SetTotpSecret(user.Id, secret)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	uri := totp.URI(totpIssuer, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	setupResponse := dto.TotpSetupResponse{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: setupResponse}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function EnableTotp mengaktifkan 2FA setelah user memasukkan kode dari authenticator, lalu mengirim kode cadangan sekali saja
+func (h *handlerAuth) EnableTotp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	request := new(dto.TotpCodeRequest)
+	json.NewDecoder(r.Body).Decode(&request)
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	user, err := h.AuthRepository.Getuser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if user.TotpEnabled || user.TotpSecret == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "call /2fa/setup first"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if !mfaAttemptLimiter.Allow(strconv.Itoa(user.Id)) {
+		writeMFAError(w, errTooManyMFAAttempts)
+		return
+	}
+
+	step, ok := totp.Validate(user.TotpSecret, request.Code, time.Now())
+	if !ok {
+		writeMFAError(w, errInvalidMFACode)
+		return
+	}
+
+	codes, records, err := generateRecoveryCodes(user.Id)
+	if err == nil {
+		err = h.AuthRepository.EnableTotp(user.Id, step, records)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	enableResponse := dto.TotpEnableResponse{RecoveryCodes: codes}
+
+	// jika dipanggil dengan token enroll (saat login) maka langsung berikan token login
+	if userInfo["purpose"] == jwtToken.PurposeMFAEnroll {
+		enableResponse.Token, err = h.generateLoginToken(user, r)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: enableResponse}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function DisableTotp mematikan 2FA, butuh kode 2FA atau kode cadangan. role yang wajib 2FA tidak bisa mematikannya
+func (h *handlerAuth) DisableTotp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	request := new(dto.TotpCodeRequest)
+	json.NewDecoder(r.Body).Decode(&request)
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	user, err := h.AuthRepository.Getuser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if mfaRequired(user.Role) {
+		w.WriteHeader(http.StatusForbidden)
+		response := dto.ErrorResult{Code: http.StatusForbidden, Message: "two-factor authentication is mandatory for your role"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if !user.TotpEnabled {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "two-factor authentication is not enabled"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.verifySecondFactor(user, request.Code, true); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	if err := h.AuthRepository.DisableTotp(user.Id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "two-factor authentication disabled"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function RegenerateRecoveryCodes membuat kode cadangan baru (kode lama tidak berlaku), butuh kode dari authenticator
+func (h *handlerAuth) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	request := new(dto.TotpCodeRequest)
+	json.NewDecoder(r.Body).Decode(&request)
+
+	user, err := h.AuthRepository.Getuser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if !user.TotpEnabled {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "two-factor authentication is not enabled"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// kode cadangan tidak bisa dipakai untuk membuat kode cadangan baru
+	if err := h.verifySecondFactor(user, request.Code, false); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	codes, records, err := generateRecoveryCodes(user.Id)
+	if err == nil {
+		err = h.AuthRepository.ReplaceRecoveryCodes(user.Id, records)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: dto.TotpEnableResponse{RecoveryCodes: codes}}
+	json.NewEncoder(w).Encode(response)
+}
+
+var errTooManyMFAAttempts = errors.New("too many attempts, please try again later")
+
+// function verifySecondFactor memvalidasi kode TOTP (sekali pakai per langkah waktu) atau kode cadangan jika diizinkan
+func (h *handlerAuth) verifySecondFactor(user models.User, code string, allowRecovery bool) error {
+	if !mfaAttemptLimiter.Allow(strconv.Itoa(user.Id)) {
+		return errTooManyMFAAttempts
+	}
+
+	if step, ok := totp.Validate(user.TotpSecret, code, time.Now()); ok {
+		if err := h.AuthRepository.UseTotpStep(user.Id, step); err != nil {
+			return errInvalidMFACode
+		}
+		return nil
+	}
+
+	if allowRecovery {
+		if err := h.AuthRepository.UseRecoveryCode(user.Id, hashToken(normalizeRecoveryCode(code))); err == nil {
+			return nil
+		}
+	}
+
+	return errInvalidMFACode
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	code := http.StatusUnauthorized
+	if errors.Is(err, errTooManyMFAAttempts) {
+		code = http.StatusTooManyRequests
+	}
+
+	w.WriteHeader(code)
+	response := dto.ErrorResult{Code: code, Message: err.Error()}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function generateRecoveryCodes membuat kode cadangan berformat xxxxx-xxxxx, yang disimpan hanya hash-nya
+func generateRecoveryCodes(userId int) ([]string, []models.RecoveryCode, error) {
+	var codes []string
+	var records []models.RecoveryCode
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		token, err := randomToken()
+		if err != nil {
+			return nil, nil, err
+		}
+		code := token[:5] + "-" + token[5:10]
+
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{
+			UserId:   userId,
+			CodeHash: hashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	return codes, records, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/bcrypt"
+	jwtToken "project/pkg/jwt"
+	"project/pkg/oidc"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	// waktu maksimal user berada di halaman login provider
+	oidcStateTTL = time.Minute * 10
+)
+
+var errOIDCEmailNotVerified = errors.New("your email is not verified by the identity provider")
+
+// akun lokal dengan email yang belum diverifikasi bisa saja dibuat orang lain (pre-hijacking), akun tersebut tidak dihubungkan
+var errOIDCAccountNotVerified = errors.New("an account with this email already exists, please verify your email or log in with your password first")
+
+// provider dibuat sekali per nama agar hasil discovery dan jwks tersimpan
+var oidcProviders = struct {
+	sync.Mutex
+	providers map[string]*oidc.Provider
+}{providers: map[string]*oidc.Provider{}}
+
+// function oidcProvider mengambil provider dari env OIDC_<NAME>_*, ok false jika provider belum dikonfigurasi
+func oidcProvider(name string) (*oidc.Provider, bool) {
+	oidcProviders.Lock()
+	defer oidcProviders.Unlock()
+
+	if provider, ok := oidcProviders.providers[name]; ok {
+		return provider, true
+	}
+
+	config, ok := oidc.ConfigFromEnv(name)
+	if !ok {
+		return nil, false
+	}
+
+	provider := oidc.NewProvider(config)
+	oidcProviders.providers[name] = provider
+	return provider, true
+}
+
+// function OIDCLogin mengarahkan user ke halaman login provider. state, nonce dan PKCE verifier disimpan di cookie yang ditandatangani
+func (h *handlerAuth) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["provider"]
+	provider, ok := oidcProvider(name)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "unknown login provider"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			writeOIDCError(w, http.StatusInternalServerError, err)
+			return
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	claims := jwt.MapClaims{}
+	claims["provider"] = name
+	claims["state"] = state
+	claims["nonce"] = nonce
+	claims["verifier"] = verifier
+	claims["exp"] = time.Now().Add(oidcStateTTL).Unix()
+
+	stateToken, err := jwtToken.GeneratePurposeToken(jwtToken.PurposeOIDCState, claims)
+	if err != nil {
+		writeOIDCError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(r.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Println(err)
+		writeOIDCError(w, http.StatusBadGateway, errors.New("login provider is unavailable"))
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateToken,
+		Path:     "/api/v1/oidc",
+		MaxAge:   int(oidcStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(baseURL(), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// function OIDCCallback dipanggil provider setelah user login. state dicocokkan dengan cookie, code ditukar dengan id token
+// lalu akun eksternal dihubungkan ke user dan token login biasa dibuat
+func (h *handlerAuth) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	name := mux.Vars(r)["provider"]
+	provider, ok := oidcProvider(name)
+	if !ok {
+		writeOIDCError(w, http.StatusNotFound, errors.New("unknown login provider"))
+		return
+	}
+
+	// cookie state hanya berlaku untuk satu kali callback
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Value: "", Path: "/api/v1/oidc", MaxAge: -1, HttpOnly: true})
+
+	if providerError := r.URL.Query().Get("error"); providerError != "" {
+		writeOIDCError(w, http.StatusUnauthorized, errors.New("login cancelled: "+providerError))
+		return
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		writeOIDCError(w, http.StatusBadRequest, errors.New("missing login state, please try again"))
+		return
+	}
+
+	claims, err := jwtToken.DecodePurposeToken(jwtToken.PurposeOIDCState, cookie.Value)
+	state, _ := claims["state"].(string)
+	if err != nil || claims["provider"] != name || state == "" ||
+		subtle.ConstantTimeCompare([]byte(state), []byte(r.URL.Query().Get("state"))) != 1 {
+		writeOIDCError(w, http.StatusBadRequest, errors.New("invalid login state, please try again"))
+		return
+	}
+
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+
+	identity, err := provider.Exchange(r.Context(), r.URL.Query().Get("code"), verifier, nonce)
+	if err != nil {
+		log.Println(err)
+		writeOIDCError(w, http.StatusUnauthorized, errors.New("could not verify your login with the provider"))
+		return
+	}
+
+	user, err := h.linkIdentity(name, identity)
+	if errors.Is(err, errOIDCEmailNotVerified) {
+		writeOIDCError(w, http.StatusForbidden, err)
+		return
+	}
+	if errors.Is(err, errOIDCAccountNotVerified) {
+		writeOIDCError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeOIDCError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.completeLogin(w, r, user)
+}
+
+// function linkIdentity mencari user dari akun eksternal. akun yang belum terhubung dihubungkan ke user
+// dengan email yang sama (hanya jika email sudah diverifikasi provider dan user), atau dibuatkan user baru
+func (h *handlerAuth) linkIdentity(provider string, identity oidc.Identity) (models.User, error) {
+	existing, err := h.AuthRepository.GetIdentity(provider, identity.Subject)
+	if err == nil {
+		return h.AuthRepository.Getuser(existing.UserId)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return models.User{}, errOIDCEmailNotVerified
+	}
+
+	user, err := h.AuthRepository.Login(identity.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = h.registerOIDCUser(identity, time.Now())
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// pemilik email belum terbukti, siapa pun bisa mendaftar dengan email orang lain sebelum pemiliknya login lewat provider
+	if user.EmailVerifiedAt == nil {
+		return models.User{}, errOIDCAccountNotVerified
+	}
+
+	_, err = h.AuthRepository.CreateIdentity(models.UserIdentity{
+		UserId:   user.Id,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	return user, err
+}
+
+// function registerOIDCUser membuat user baru dari akun eksternal dengan password acak (user bisa memakai reset password)
+func (h *handlerAuth) registerOIDCUser(identity oidc.Identity, verifiedAt time.Time) (models.User, error) {
+	random, err := randomToken()
+	if err != nil {
+		return models.User{}, err
+	}
+	password, err := bcrypt.HashingPassword(random)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = strings.Split(identity.Email, "@")[0]
+	}
+
+	return h.AuthRepository.Register(models.User{
+		Name:            name,
+		Email:           identity.Email,
+		Password:        password,
+		Role:            "user",
+		EmailVerifiedAt: &verifiedAt,
+	})
+}
+
+func writeOIDCError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	response := dto.ErrorResult{Code: code, Message: err.Error()}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"project/models"
+	"project/pkg/loginguard"
+	"project/pkg/oidc/oidctest"
+	"project/repositories"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// fakeAuthRepository menyimpan user dan akun eksternal di memory, method lain tidak dipakai oleh login OIDC
+type fakeAuthRepository struct {
+	repositories.AuthRepository
+	users      map[int]models.User
+	identities []models.UserIdentity
+	sessions   int
+}
+
+func newFakeAuthRepository(users ...models.User) *fakeAuthRepository {
+	repo := &fakeAuthRepository{users: map[int]models.User{}}
+	for _, user := range users {
+		repo.users[user.Id] = user
+	}
+	return repo
+}
+
+func (f *fakeAuthRepository) Register(user models.User) (models.User, error) {
+	user.Id = len(f.users) + 1
+	f.users[user.Id] = user
+	return user, nil
+}
+
+func (f *fakeAuthRepository) Login(email string) (models.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeAuthRepository) Getuser(id int) (models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return models.User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeAuthRepository) SetEmailVerified(id int, verifiedAt *time.Time) error {
+	user := f.users[id]
+	user.EmailVerifiedAt = verifiedAt
+	f.users[id] = user
+	return nil
+}
+
+func (f *fakeAuthRepository) GetIdentity(provider string, subject string) (models.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return models.UserIdentity{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeAuthRepository) CreateIdentity(identity models.UserIdentity) (models.UserIdentity, error) {
+	f.identities = append(f.identities, identity)
+	return identity, nil
+}
+
+func (f *fakeAuthRepository) CreateSession(session models.Session) (models.Session, error) {
+	f.sessions++
+	return session, nil
+}
+
+// function setupOIDC menjalankan provider palsu dengan nama "mock" yang dibaca handler dari env
+func setupOIDC(t *testing.T) *oidctest.Server {
+	server := oidctest.NewServer("client-1")
+	t.Cleanup(server.Close)
+
+	t.Setenv("OIDC_MOCK_ISSUER", server.URL)
+	t.Setenv("OIDC_MOCK_CLIENT_ID", "client-1")
+	t.Setenv("OIDC_MOCK_REDIRECT_URL", "https://app.example.com/api/v1/oidc/mock/callback")
+
+	// provider disimpan per nama, hapus agar memakai server test ini
+	oidcProviders.Lock()
+	delete(oidcProviders.providers, "mock")
+	oidcProviders.Unlock()
+	t.Cleanup(func() {
+		oidcProviders.Lock()
+		delete(oidcProviders.providers, "mock")
+		oidcProviders.Unlock()
+	})
+
+	return server
+}
+
+// function oidcLogin memanggil OIDCLogin lalu mengembalikan cookie state dan url login provider
+func oidcLogin(t *testing.T, h *handlerAuth) (*http.Cookie, string) {
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/oidc/mock/login", nil), map[string]string{"provider": "mock"})
+	w := httptest.NewRecorder()
+	h.OIDCLogin(w, req)
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("OIDCLogin status = %d, body %s", w.Code, w.Body)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcStateCookie {
+		t.Fatalf("OIDCLogin cookies = %v, want %s", cookies, oidcStateCookie)
+	}
+	return cookies[0], w.Header().Get("Location")
+}
+
+func oidcCallback(h *handlerAuth, cookie *http.Cookie, code string, state string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/v1/oidc/mock/callback?code="+code+"&state="+state, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	req = mux.SetURLVars(req, map[string]string{"provider": "mock"})
+	w := httptest.NewRecorder()
+	h.OIDCCallback(w, req)
+	return w
+}
+
+func TestOIDCCallback(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+	existing := models.User{Id: 1, Name: "Budi", Email: "user@example.com", Role: "user", EmailVerifiedAt: &verifiedAt}
+	unverified := models.User{Id: 1, Name: "Budi", Email: "user@example.com", Role: "user"}
+	linked := models.UserIdentity{UserId: 1, Provider: "mock", Subject: "subject-1", Email: "old@example.com"}
+
+	tests := []struct {
+		name       string
+		users      []models.User
+		identities []models.UserIdentity
+		claims     jwt.MapClaims
+		// mengubah state di callback atau tidak mengirim cookie
+		tamperState bool
+		noCookie    bool
+		wantCode    int
+		// user yang login dan jumlah akun eksternal yang baru dihubungkan
+		wantUserId     int
+		wantIdentities int
+		wantVerified   bool
+	}{
+		{
+			name:           "new user with verified email",
+			wantCode:       http.StatusOK,
+			wantUserId:     1,
+			wantIdentities: 1,
+			wantVerified:   true,
+		},
+		{
+			name:           "links existing user by verified email",
+			users:          []models.User{existing, {Id: 2, Email: "other@example.com"}},
+			wantCode:       http.StatusOK,
+			wantUserId:     1,
+			wantIdentities: 1,
+			wantVerified:   true,
+		},
+		{
+			name:           "keeps existing verification time",
+			users:          []models.User{{Id: 1, Email: "user@example.com", Role: "user", EmailVerifiedAt: &verifiedAt}},
+			wantCode:       http.StatusOK,
+			wantUserId:     1,
+			wantIdentities: 1,
+			wantVerified:   true,
+		},
+		{
+			// akun lokal bisa saja didaftarkan penyerang sebelum pemilik email login lewat provider
+			name:     "refuses to link unverified local account",
+			users:    []models.User{unverified},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "refuses to link by unverified email",
+			users:    []models.User{existing},
+			claims:   jwt.MapClaims{"email_verified": false},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "refuses new user without email",
+			claims:   jwt.MapClaims{"email": nil},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:         "already linked identity ignores email",
+			users:        []models.User{existing},
+			identities:   []models.UserIdentity{linked},
+			claims:       jwt.MapClaims{"email": "changed@example.com", "email_verified": false},
+			wantCode:     http.StatusOK,
+			wantUserId:   1,
+			wantVerified: true,
+		},
+		{
+			name:        "state mismatch",
+			tamperState: true,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "missing state cookie",
+			noCookie: true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "nonce mismatch",
+			claims:   jwt.MapClaims{"nonce": "another-nonce"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "audience mismatch",
+			claims:   jwt.MapClaims{"aud": "client-2"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "issuer mismatch",
+			claims:   jwt.MapClaims{"iss": "https://evil.example.com"},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := setupOIDC(t)
+			repo := newFakeAuthRepository(tt.users...)
+			repo.identities = append(repo.identities, tt.identities...)
+			h := HandlerAuth(repo, loginguard.New(loginguard.NewMemoryStore()))
+
+			cookie, authURL := oidcLogin(t, h)
+			code, state, err := server.Authorize(authURL, tt.claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tamperState {
+				state = "forged-state"
+			}
+			if tt.noCookie {
+				cookie = nil
+			}
+
+			w := oidcCallback(h, cookie, code, state)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantCode, w.Body)
+			}
+			if len(repo.identities) != len(tt.identities)+tt.wantIdentities {
+				t.Errorf("identities = %d, want %d", len(repo.identities), len(tt.identities)+tt.wantIdentities)
+			}
+			if tt.wantCode != http.StatusOK {
+				if repo.sessions != 0 {
+					t.Error("session created for a rejected login")
+				}
+				return
+			}
+
+			var response struct {
+				Data struct {
+					Email string `json:"email"`
+					Token string `json:"token"`
+				} `json:"data"`
+			}
+			json.NewDecoder(w.Body).Decode(&response)
+			user := repo.users[tt.wantUserId]
+			if response.Data.Token == "" || response.Data.Email != user.Email || repo.sessions != 1 {
+				t.Errorf("response = %+v, sessions %d, want token for %s", response.Data, repo.sessions, user.Email)
+			}
+			if verified := user.EmailVerifiedAt != nil; verified != tt.wantVerified {
+				t.Errorf("email verified = %t, want %t", verified, tt.wantVerified)
+			}
+			if tt.name == "keeps existing verification time" && !user.EmailVerifiedAt.Equal(verifiedAt) {
+				t.Errorf("email_verified_at changed to %v", user.EmailVerifiedAt)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackCodeReplay(t *testing.T) {
+	server := setupOIDC(t)
+	repo := newFakeAuthRepository()
+	h := HandlerAuth(repo, loginguard.New(loginguard.NewMemoryStore()))
+
+	cookie, authURL := oidcLogin(t, h)
+	code, state, err := server.Authorize(authURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := oidcCallback(h, cookie, code, state); w.Code != http.StatusOK {
+		t.Fatalf("first callback status = %d, body %s", w.Code, w.Body)
+	}
+	if w := oidcCallback(h, cookie, code, state); w.Code != http.StatusUnauthorized {
+		t.Errorf("replayed callback status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// batas jumlah data per halaman
+const maxPageLimit = 100
+
+// function pagination membaca query ?page=&limit=. limit 0 dikembalikan jika request tidak meminta pagination
+// dan defaultLimit juga 0, sehingga endpoint lama tetap mengembalikan semua data
+func pagination(r *http.Request, defaultLimit int) (page int, limit int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+
+	if limit <= 0 {
+		limit = defaultLimit
+		if r.URL.Query().Get("page") != "" && limit == 0 {
+			limit = 20
+		}
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	if page < 1 {
+		page = 1
+	}
+	return page, limit
+}
+
+// function setTotalCount mengirim jumlah seluruh data lewat header agar bentuk response tidak berubah
+func setTotalCount(w http.ResponseWriter, total int64) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/bcrypt"
+	"project/pkg/mail"
+	"project/pkg/passwordpolicy"
+	"project/pkg/ratelimit"
+	"project/repositories"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// masa berlaku link reset password
+const passwordResetTTL = time.Minute * 30
+
+// setiap email maksimal menerima 3 link reset per jam
+var forgotPasswordEmailLimiter = ratelimit.New(3, time.Hour)
+
+// batas percobaan password lama yang salah per user
+var changePasswordLimiter = ratelimit.New(5, time.Minute*15)
+
+type handlerPassword struct {
+	PasswordRepository repositories.PasswordRepository
+}
+
+func HandlerPassword(PasswordRepository repositories.PasswordRepository) *handlerPassword {
+	return &handlerPassword{PasswordRepository}
+}
+
+// function ForgotPassword mengirim link reset password ke email user.
+// response selalu sama baik email terdaftar maupun tidak agar email user tidak bisa ditebak
+func (h *handlerPassword) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.ForgotPasswordRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	// dijalankan di background agar waktu response tidak membedakan email terdaftar atau tidak
+	if forgotPasswordEmailLimiter.Allow(email) {
+		go h.createPasswordReset(email)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "if the email is registered, a password reset link has been sent"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function createPasswordReset membuat token reset untuk user dengan email tersebut (jika ada) lalu mengirim emailnya
+func (h *handlerPassword) createPasswordReset(email string) {
+	user, err := h.PasswordRepository.GetUserByEmail(email)
+	if err != nil {
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	reset := models.PasswordReset{
+		UserId:    user.Id,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if _, err := h.PasswordRepository.CreatePasswordReset(reset); err != nil {
+		log.Println(err)
+		return
+	}
+
+	sendPasswordResetEmail(user, token)
+}
+
+// function ResetPassword mengganti password memakai token dari email, token hanya bisa dipakai sekali
+func (h *handlerPassword) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.ResetPasswordRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	reset, err := h.PasswordRepository.GetPasswordResetByHash(hashToken(request.Token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "invalid or expired reset token"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	user, err := h.PasswordRepository.GetUser(reset.UserId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "invalid or expired reset token"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := passwordpolicy.Validate(request.Password, user.Name, user.Email); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	password, err := bcrypt.HashingPassword(request.Password)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// mengganti password sekaligus mencabut semua sesi yang sedang login
+	if err := h.PasswordRepository.ResetPassword(reset, password); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "invalid or expired reset token"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "password has been reset, please login again"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function ChangePassword mengganti password user yang login, wajib menyertakan password lama.
+// semua sesi lain dicabut, sesi yang dipakai request ini tetap login
+func (h *handlerPassword) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	currentTokenId, _ := userInfo["sid"].(string)
+
+	request := new(dto.ChangePasswordRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	user, err := h.PasswordRepository.GetUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if !changePasswordLimiter.Allow(strconv.Itoa(user.Id)) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		response := dto.ErrorResult{Code: http.StatusTooManyRequests, Message: "too many attempts, try again later"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if !bcrypt.CheckPasswordHash(request.CurrentPassword, user.Password) {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "current password is incorrect"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if request.NewPassword == request.CurrentPassword {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "new password must be different from the current password"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := passwordpolicy.Validate(request.NewPassword, user.Name, user.Email); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	password, err := bcrypt.HashingPassword(request.NewPassword)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.PasswordRepository.ChangePassword(user.Id, password, currentTokenId); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.PasswordRepository, r, "user.password_changed", user.Id, nil)
+	go sendPasswordChangedEmail(user)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "password changed, other sessions have been logged out"}
+	json.NewEncoder(w).Encode(response)
+}
+
+func sendPasswordChangedEmail(user models.User) {
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Hi %s,</h2>
+      <p>The password of your dewetour account was just changed and all other devices have been logged out.</p>
+      <p>If this was not you, reset your password immediately using the "forgot password" link.</p>
+      </body>
+    </html>`, html.EscapeString(user.Name)),
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func sendPasswordResetEmail(user models.User, token string) {
+	link := frontendURL() + "/reset-password?token=" + url.QueryEscape(token)
+
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Hi %s,</h2>
+      <p>We received a request to reset your dewetour password.</p>
+      <p><a href="%s">Reset password</a></p>
+      <p>This link expires in 30 minutes and can only be used once. If you did not request it, you can ignore this email.</p>
+      </body>
+    </html>`, html.EscapeString(user.Name), link),
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// function randomToken membuat token acak 32 byte dalam bentuk hex
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// function hashToken mengubah token menjadi hash sha256, hanya hash ini yang disimpan di database
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// function frontendURL mengambil alamat frontend dari env FRONTEND_URL
+func frontendURL() string {
+	if value := os.Getenv("FRONTEND_URL"); value != "" {
+		return strings.TrimRight(value, "/")
+	}
+	return "http://localhost:3000"
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/money"
+	"project/pkg/pricing"
+	"project/repositories"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+type handlerPricingRule struct {
+	PricingRuleRepository repositories.PricingRuleRepository
+}
+
+func HandlerPricingRule(PricingRuleRepository repositories.PricingRuleRepository) *handlerPricingRule {
+	return &handlerPricingRule{PricingRuleRepository}
+}
+
+// function Quote menampilkan rincian harga trip sebelum booking, dihitung dengan cara yang sama seperti saat booking dibuat
+func (h *handlerPricingRule) Quote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	request := new(dto.QuoteRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	passengers, quantity, err := bookingPassengers(request.CounterQty, request.Passengers)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	currency := request.Currency
+	if currency == "" {
+		currency = r.URL.Query().Get("currency")
+	}
+	converter, err := displayConverter(h.PricingRuleRepository, currency)
+	if err != nil {
+		w.WriteHeader(currencyErrorStatus(err))
+		response := dto.ErrorResult{Code: currencyErrorStatus(err), Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	trip, err := h.PricingRuleRepository.GetTrip(tripId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	price, err := priceBooking(h.PricingRuleRepository, userId, trip, bookingOrder{
+		Passengers: passengers,
+		Quantity:   quantity,
+		AddOns:     addOnSelections(request.AddOns, nil),
+		PromoCode:  request.PromoCode,
+	})
+	var checkoutErr pricing.Error
+	if errors.As(err, &checkoutErr) {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: checkoutErr.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: dto.QuoteResponse{
+		TripId:     trip.Id,
+		Quantity:   quantity,
+		Passengers: passengers,
+		Items:      price.Items,
+		Subtotal:   money.IDR(int64(price.Subtotal)),
+		Discount:   money.IDR(int64(price.Discount)),
+		Fees:       money.IDR(int64(price.Fees)),
+		Tax:        money.IDR(int64(price.Tax)),
+		Total:      money.IDR(int64(price.Total)),
+		PromoCode:  price.PromoCode.Code,
+		Available:  quantity <= trip.Quota,
+		Display:    quoteDisplay(price, converter),
+	}}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPricingRule) FindPricingRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	rules, err := h.PricingRuleRepository.FindPricingRules(tripId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rules}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPricingRule) CreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.CreatePricingRuleRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if _, err := h.PricingRuleRepository.GetTrip(tripId); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	rule := models.PricingRule{
+		TripId:        tripId,
+		Kind:          request.Kind,
+		Description:   request.Description,
+		PassengerType: request.PassengerType,
+		Percent:       request.Percent,
+		Amount:        request.Amount,
+		MinQuantity:   request.MinQuantity,
+		StartsAt:      request.StartsAt,
+		EndsAt:        request.EndsAt,
+	}
+	if err := validatePricingRule(rule); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	rule, err := h.PricingRuleRepository.CreatePricingRule(rule)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rule}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPricingRule) UpdatePricingRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdatePricingRuleRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	rule, err := h.PricingRuleRepository.GetPricingRule(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "pricing rule not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if request.Description != nil {
+		rule.Description = *request.Description
+	}
+	if request.PassengerType != nil {
+		rule.PassengerType = *request.PassengerType
+	}
+	if request.Percent != nil {
+		rule.Percent = *request.Percent
+	}
+	if request.Amount != nil {
+		rule.Amount = *request.Amount
+	}
+	if request.MinQuantity != nil {
+		rule.MinQuantity = *request.MinQuantity
+	}
+	if request.StartsAt != nil {
+		rule.StartsAt = request.StartsAt
+	}
+	if request.EndsAt != nil {
+		rule.EndsAt = request.EndsAt
+	}
+	if err := validatePricingRule(rule); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	rule, err = h.PricingRuleRepository.UpdatePricingRule(rule)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rule}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPricingRule) DeletePricingRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	rule, err := h.PricingRuleRepository.GetPricingRule(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "pricing rule not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.PricingRuleRepository.DeletePricingRule(rule); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rule}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function validatePricingRule memeriksa field yang wajib untuk setiap jenis aturan
+func validatePricingRule(rule models.PricingRule) error {
+	switch rule.Kind {
+	case models.RulePassengerType:
+		if rule.PassengerType == "" {
+			return errors.New("passenger_type is required")
+		}
+		if rule.Percent != 0 {
+			return errors.New("passenger_type rules use amount as the fare per person")
+		}
+	case models.RuleQuantityTier:
+		if rule.MinQuantity < 2 {
+			return errors.New("min_quantity must be at least 2")
+		}
+	case models.RuleEarlyBird, models.RulePeakSurcharge:
+		if rule.StartsAt == nil && rule.EndsAt == nil {
+			return errors.New("starts_at or ends_at is required")
+		}
+	}
+	if rule.Kind != models.RulePassengerType && rule.Percent == 0 && rule.Amount == 0 {
+		return errors.New("percent or amount is required")
+	}
+	if rule.StartsAt != nil && rule.EndsAt != nil && !rule.EndsAt.After(*rule.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// function quoteDisplay mengonversi quote ke mata uang tampilan, converter nil berarti tanpa konversi
+func quoteDisplay(price bookingPrice, converter *money.Converter) *dto.QuoteDisplay {
+	if converter == nil {
+		return nil
+	}
+
+	display := dto.QuoteDisplay{
+		Currency: converter.Currency,
+		Rate:     converter.Rate,
+		Items:    []dto.DisplayItem{},
+		Subtotal: converter.Convert(money.IDR(int64(price.Subtotal))),
+		Fees:     converter.Convert(money.IDR(int64(price.Fees))),
+		Tax:      converter.Convert(money.IDR(int64(price.Tax))),
+		Total:    converter.Convert(money.IDR(int64(price.Total))),
+	}
+	discount := display.Subtotal.Amount + display.Fees.Amount + display.Tax.Amount - display.Total.Amount
+	display.Discount = money.Money{Amount: discount, Currency: converter.Currency}
+	for _, item := range price.Items {
+		display.Items = append(display.Items, dto.DisplayItem{
+			Kind:        item.Kind,
+			Code:        item.Code,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitAmount:  converter.Convert(money.IDR(int64(item.UnitAmount))),
+			Amount:      converter.Convert(money.IDR(int64(item.Amount))),
+		})
+	}
+	return &display
+}
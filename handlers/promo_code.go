@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/pricing"
+	"project/repositories"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerPromoCode struct {
+	PromoCodeRepository repositories.PromoCodeRepository
+}
+
+func HandlerPromoCode(PromoCodeRepository repositories.PromoCodeRepository) *handlerPromoCode {
+	return &handlerPromoCode{PromoCodeRepository}
+}
+
+func (h *handlerPromoCode) FindPromoCodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, limit := pagination(r, 50)
+	promos, total, err := h.PromoCodeRepository.FindPromoCodes((page-1)*limit, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.PromoCodeResponse{}
+	for _, promo := range promos {
+		result = append(result, convertResponsePromoCode(promo))
+	}
+
+	setTotalCount(w, total)
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPromoCode) GetPromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	promo, err := h.PromoCodeRepository.GetPromoCode(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "promo code not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePromoCode(promo)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPromoCode) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.CreatePromoCodeRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	promo := models.PromoCode{
+		Code:         pricing.NormalizeCode(request.Code),
+		Description:  request.Description,
+		DiscountType: request.DiscountType,
+		Value:        request.Value,
+		MaxDiscount:  request.MaxDiscount,
+		MinSpend:     request.MinSpend,
+		StartsAt:     request.StartsAt,
+		EndsAt:       request.EndsAt,
+		UsageLimit:   request.UsageLimit,
+		PerUserLimit: request.PerUserLimit,
+		TripIds:      joinIds(request.TripIds),
+		CountryIds:   joinIds(request.CountryIds),
+		Active:       request.Active == nil || *request.Active,
+	}
+	if err := validatePromoCode(promo); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if _, err := h.PromoCodeRepository.GetPromoCodeByCode(promo.Code); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "promo code already exists"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	promo, err := h.PromoCodeRepository.CreatePromoCode(promo)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.PromoCodeRepository, r, "promo_code.created", 0, map[string]interface{}{"promo_code_id": promo.Id, "code": promo.Code})
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePromoCode(promo)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPromoCode) UpdatePromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdatePromoCodeRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	promo, err := h.PromoCodeRepository.GetPromoCode(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "promo code not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if request.Description != nil {
+		promo.Description = *request.Description
+	}
+	if request.DiscountType != nil {
+		promo.DiscountType = *request.DiscountType
+	}
+	if request.Value != nil {
+		promo.Value = *request.Value
+	}
+	if request.MaxDiscount != nil {
+		promo.MaxDiscount = *request.MaxDiscount
+	}
+	if request.MinSpend != nil {
+		promo.MinSpend = *request.MinSpend
+	}
+	if request.StartsAt != nil {
+		promo.StartsAt = request.StartsAt
+	}
+	if request.EndsAt != nil {
+		promo.EndsAt = request.EndsAt
+	}
+	if request.UsageLimit != nil {
+		promo.UsageLimit = *request.UsageLimit
+	}
+	if request.PerUserLimit != nil {
+		promo.PerUserLimit = *request.PerUserLimit
+	}
+	if request.TripIds != nil {
+		promo.TripIds = joinIds(*request.TripIds)
+	}
+	if request.CountryIds != nil {
+		promo.CountryIds = joinIds(*request.CountryIds)
+	}
+	if request.Active != nil {
+		promo.Active = *request.Active
+	}
+	if err := validatePromoCode(promo); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	promo, err = h.PromoCodeRepository.UpdatePromoCode(promo)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.PromoCodeRepository, r, "promo_code.updated", 0, map[string]interface{}{"promo_code_id": promo.Id, "code": promo.Code})
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePromoCode(promo)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function DeletePromoCode menghapus promo code. booking yang sudah memakai promo tetap menyimpan kode dan potongannya
+func (h *handlerPromoCode) DeletePromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	promo, err := h.PromoCodeRepository.GetPromoCode(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "promo code not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err == nil {
+		err = h.PromoCodeRepository.DeletePromoCode(promo)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.PromoCodeRepository, r, "promo_code.deleted", 0, map[string]interface{}{"promo_code_id": promo.Id, "code": promo.Code})
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePromoCode(promo)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func validatePromoCode(promo models.PromoCode) error {
+	if promo.DiscountType == models.DiscountPercentage && promo.Value > 100 {
+		return errors.New("percentage discount must be at most 100")
+	}
+	if promo.StartsAt != nil && promo.EndsAt != nil && !promo.EndsAt.After(*promo.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// function joinIds menyimpan daftar id sebagai teks dipisah koma, kebalikan dari parseIds
+func joinIds(ids []int) string {
+	var parts []string
+	for _, id := range uniqueIds(ids) {
+		if id > 0 {
+			parts = append(parts, strconv.Itoa(id))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func convertResponsePromoCode(promo models.PromoCode) dto.PromoCodeResponse {
+	return dto.PromoCodeResponse{
+		Id:           promo.Id,
+		Code:         promo.Code,
+		Description:  promo.Description,
+		DiscountType: promo.DiscountType,
+		Value:        promo.Value,
+		MaxDiscount:  promo.MaxDiscount,
+		MinSpend:     promo.MinSpend,
+		StartsAt:     promo.StartsAt,
+		EndsAt:       promo.EndsAt,
+		UsageLimit:   promo.UsageLimit,
+		PerUserLimit: promo.PerUserLimit,
+		UsedCount:    promo.UsedCount,
+		TripIds:      append([]int{}, parseIds(promo.TripIds)...),
+		CountryIds:   append([]int{}, parseIds(promo.CountryIds)...),
+		Active:       promo.Active,
+		CreatedAt:    promo.CreatedAt,
+	}
+}
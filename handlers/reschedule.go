@@ -0,0 +1,553 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"os"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/mail"
+	"project/pkg/money"
+	"project/pkg/pdf"
+	"project/pkg/policy"
+	"project/pkg/pricing"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/midtrans/midtrans-go/snap"
+	"gorm.io/gorm"
+)
+
+// order id midtrans untuk pembayaran selisih harga reschedule, misal RS-12
+const rescheduleOrderPrefix = "RS-"
+
+const rescheduleSubject = "Booking Reschedule"
+
+// function RescheduleTransaction memindahkan booking yang sudah dibayar ke keberangkatan lain dari trip yang sama
+// (group key sama). kursi di keberangkatan baru langsung ditahan. jika harga baru lebih mahal, selisihnya dibayar
+// lewat midtrans dan booking baru dipindahkan setelah pembayaran berhasil. jika lebih murah, booking langsung dipindahkan
+// dan selisihnya dikembalikan
+func (h *handlerTransaction) RescheduleTransaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	// hanya pemilik booking atau admin yang boleh memindahkan booking
+	transaction, err := h.authorizeTransaction(r, id, policy.TransactionUpdateAny)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	request := new(dto.RescheduleRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if transaction.Status != "success" {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "only paid bookings can be rescheduled"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	// add-on terikat ke keberangkatan lama dan stoknya dihitung per keberangkatan, jadi tidak bisa ikut dipindahkan
+	if hasAddOns(transaction) {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "bookings with add-ons cannot be rescheduled, please contact us to change your departure"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	trip, err := h.TransactionRepository.GetTrip(request.TripId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if err := checkRescheduleTarget(transaction, trip, time.Now()); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// booking yang masih menunggu pembayaran reschedule atau penumpangnya sudah check-in tidak bisa dipindahkan
+	_, err = h.TransactionRepository.GetPendingReschedule(transaction.Id)
+	if err == nil {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "a reschedule for this booking is waiting for payment"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	var checkIns int64
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		checkIns, err = h.TransactionRepository.CountCheckIns(transaction.Id)
+	}
+	var held int
+	if err == nil {
+		held, err = h.TransactionRepository.HeldWaitlistSeats(trip.Id, transaction.UserId)
+	}
+	var price bookingPrice
+	if err == nil {
+		price, err = priceReschedule(h.TransactionRepository, transaction, trip)
+	}
+	var items []byte
+	if err == nil {
+		items, err = json.Marshal(price.Items)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if checkIns > 0 {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "passengers have already checked in"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	reschedule, err := h.TransactionRepository.StartReschedule(models.Reschedule{
+		TransactionId: transaction.Id,
+		UserId:        transaction.UserId,
+		RequestedById: policy.FromRequest(r).Id,
+		FromTripId:    transaction.TripId,
+		ToTripId:      trip.Id,
+		Seats:         transaction.CounterQty,
+		OldTotal:      transaction.Total,
+		Subtotal:      money.IDR(int64(price.Subtotal)),
+		Discount:      money.IDR(int64(price.Discount)),
+		Fees:          money.IDR(int64(price.Fees)),
+		Tax:           money.IDR(int64(price.Tax)),
+		Total:         money.IDR(int64(price.Total)),
+		Difference:    money.IDR(int64(price.Total) - transaction.Total.Amount),
+		Items:         string(items),
+		Reason:        strings.TrimSpace(request.Reason),
+		Status:        models.ReschedulePending,
+	}, held)
+	var checkoutErr pricing.Error
+	if errors.As(err, &checkoutErr) {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: checkoutErr.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.TransactionRepository, r, "transaction.reschedule_requested", transaction.UserId, map[string]interface{}{
+		"transaction_id": transaction.Id,
+		"reschedule_id":  reschedule.Id,
+		"from_trip_id":   reschedule.FromTripId,
+		"to_trip_id":     reschedule.ToTripId,
+		"difference":     reschedule.Difference.Amount,
+	})
+	recordReschedule(h.TransactionRepository, r, models.EventRescheduleRequested, reschedule, nil)
+
+	// selisih yang harus dibayar ditagih dengan order midtrans sendiri karena order booking sudah dibayar
+	if reschedule.Difference.Amount > 0 {
+		var s = snap.Client{}
+		s.New(os.Getenv("SERVER_KEY"), midtrans.Sandbox)
+
+		snapResp, snapErr := s.CreateTransaction(&snap.Request{
+			TransactionDetails: midtrans.TransactionDetails{
+				OrderID:  rescheduleOrderId(reschedule.Id),
+				GrossAmt: reschedule.Difference.Amount,
+			},
+			Items: &[]midtrans.ItemDetails{{
+				ID:    rescheduleOrderId(reschedule.Id),
+				Name:  "Reschedule fare difference",
+				Price: reschedule.Difference.Amount,
+				Qty:   1,
+			}},
+			CreditCard: &snap.CreditCardDetails{
+				Secure: true,
+			},
+			CustomerDetail: &midtrans.CustomerDetails{
+				FName: transaction.User.Name,
+				Email: transaction.User.Email,
+			},
+			// kursi di keberangkatan baru hanya ditahan selama batas waktu pembayaran, lihat jobs.ExpireReschedules
+			Expiry: &snap.ExpiryDetails{
+				StartTime: reschedule.CreatedAt.Format("2006-01-02 15:04:05 -0700"),
+				Unit:      "minute",
+				Duration:  int64(models.ReschedulePaymentTTL / time.Minute),
+			},
+		})
+		if snapErr != nil {
+			if _, _, err := h.TransactionRepository.FailReschedule(reschedule); err != nil {
+				log.Printf("reschedule %d: %v", reschedule.Id, err)
+			}
+			recordReschedule(h.TransactionRepository, r, models.EventRescheduleFailed, reschedule, errors.New("payment gateway error"))
+			w.WriteHeader(http.StatusBadGateway)
+			response := dto.ErrorResult{Code: http.StatusBadGateway, Message: "payment gateway error: " + snapErr.GetMessage()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		reschedule.Token = snapResp.Token
+		reschedule, err = h.TransactionRepository.UpdateReschedule(reschedule)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		response := dto.SuccessResult{Code: http.StatusOK, Data: reschedule}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	reschedule, err = h.completeReschedule(r, reschedule)
+	if errors.As(err, &checkoutErr) {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: checkoutErr.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: reschedule}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function checkRescheduleTarget memastikan keberangkatan baru adalah trip yang sama, berbeda tanggal dan belum berangkat
+func checkRescheduleTarget(transaction models.Transaction, trip models.Trip, now time.Time) error {
+	if trip.Id == transaction.TripId {
+		return errors.New("booking is already on this departure")
+	}
+	if trip.GroupKey == "" || trip.GroupKey != transaction.Trip.GroupKey {
+		return errors.New("booking can only be moved to another departure of the same trip")
+	}
+	if !transaction.Trip.DateTrip.After(now) {
+		return errors.New("trip has already departed")
+	}
+	if !trip.DateTrip.After(now) {
+		return errors.New("new departure must be in the future")
+	}
+	return nil
+}
+
+// function hasAddOns mengecek apakah booking memiliki add-on
+func hasAddOns(transaction models.Transaction) bool {
+	for _, item := range transaction.Items {
+		if item.Kind == models.ItemAddOn {
+			return true
+		}
+	}
+	return false
+}
+
+// function priceReschedule menghitung ulang harga booking tanpa add-on untuk keberangkatan baru. harga tiket dihitung
+// dari aturan harga keberangkatan baru dengan tanggal booking asli (early bird tetap berlaku jika dulu berlaku). potongan
+// promo yang sudah didapat ikut dipindahkan, lalu pajak dan biaya layanan dihitung ulang
+func priceReschedule(repo checkoutRepository, transaction models.Transaction, trip models.Trip) (bookingPrice, error) {
+	var price bookingPrice
+
+	passengers := map[string]int{}
+	var promos []models.TransactionItem
+	for _, item := range transaction.Items {
+		switch item.Kind {
+		case models.ItemFare:
+			passengers[item.Code] += item.Quantity
+		case models.ItemDiscount:
+			promos = append(promos, item)
+		}
+	}
+	// booking lama tanpa rincian harga dianggap semua penumpang dewasa
+	if len(passengers) == 0 {
+		passengers[models.PassengerAdult] = transaction.CounterQty
+	}
+
+	rules, err := repo.FindPricingRules(trip.Id)
+	if err != nil {
+		return price, err
+	}
+	price.Items = pricing.Evaluate(rules, pricing.Booking{
+		TripPrice:  int(trip.Price.Amount),
+		DepartAt:   trip.DateTrip,
+		BookedAt:   transaction.BookingDate,
+		Passengers: passengers,
+	})
+	for _, item := range price.Items {
+		if item.Amount > 0 {
+			price.Subtotal += item.Amount
+		} else {
+			price.Discount -= item.Amount
+		}
+	}
+
+	// potongan promo tidak boleh lebih besar dari harga baru
+	for _, item := range promos {
+		discount := -item.Amount
+		if payable := price.Subtotal - price.Discount; discount > payable {
+			discount = payable
+		}
+		item.Quantity = 1
+		item.UnitAmount = -discount
+		item.Amount = -discount
+		price.Discount += discount
+		price.Items = append(price.Items, item)
+	}
+
+	err = applyCharges(repo, &price, trip, transaction.CounterQty)
+	return price, err
+}
+
+// function completeReschedule memindahkan booking ke keberangkatan baru, mengembalikan selisih lewat midtrans jika
+// harga baru lebih murah lalu mengirim email konfirmasi dengan e-ticket baru serta adjustment invoice atau credit note
+// untuk selisihnya. reschedule yang sudah diproses dilewati. r nil berarti dipanggil dari notifikasi midtrans
+func (h *handlerTransaction) completeReschedule(r *http.Request, reschedule models.Reschedule) (models.Reschedule, error) {
+	var items []models.TransactionItem
+	if err := json.Unmarshal([]byte(reschedule.Items), &items); err != nil {
+		return reschedule, err
+	}
+
+	// rincian booking sebelum dipindahkan dibutuhkan untuk adjustment invoice. booking lama yang belum punya invoice
+	// dibuatkan sekarang agar invoicenya berisi harga lama, bukan harga setelah reschedule
+	previous, err := h.TransactionRepository.GetTransaction(reschedule.TransactionId)
+	if err != nil {
+		return reschedule, err
+	}
+	if previous.Status == "success" {
+		if _, err := h.TransactionRepository.IssueInvoice(previous, timeIn("Asia/Jakarta")); err != nil {
+			return reschedule, err
+		}
+	}
+
+	reschedule, completed, err := h.TransactionRepository.CompleteReschedule(reschedule, items, timeIn("Asia/Jakarta"))
+	// booking dibatalkan sebelum reschedule selesai, kursi yang ditahan dikembalikan
+	var bookingErr pricing.Error
+	if errors.As(err, &bookingErr) {
+		if _, _, err := h.TransactionRepository.FailReschedule(reschedule); err != nil {
+			log.Printf("reschedule %d: %v", reschedule.Id, err)
+		}
+		recordReschedule(h.TransactionRepository, r, models.EventRescheduleFailed, reschedule, bookingErr)
+	}
+	if err != nil || !completed {
+		return reschedule, err
+	}
+
+	if reschedule.Difference.Amount < 0 {
+		reschedule.RefundStatus = refundReschedule(reschedule)
+		if _, err := h.TransactionRepository.UpdateReschedule(reschedule); err != nil {
+			log.Printf("reschedule %d: %v", reschedule.Id, err)
+		}
+	}
+	recordReschedule(h.TransactionRepository, r, models.EventRescheduled, reschedule, nil)
+
+	transaction, err := h.TransactionRepository.GetTransaction(reschedule.TransactionId)
+	if err != nil {
+		log.Printf("reschedule %d: %v", reschedule.Id, err)
+		return reschedule, nil
+	}
+	var attachments []mail.Attachment
+	if reschedule.Difference.Amount != 0 {
+		invoice, err := h.TransactionRepository.IssueRescheduleInvoice(reschedule, previous, timeIn("Asia/Jakarta"))
+		if err == nil {
+			var data []byte
+			if data, err = pdf.Invoice(invoice, transaction); err == nil {
+				attachments = append(attachments, mail.Attachment{Filename: invoiceFilename(invoice), ContentType: "application/pdf", Data: data})
+			}
+		}
+		if err != nil {
+			log.Printf("reschedule invoice %d: %v", reschedule.Id, err)
+		}
+	}
+	tickets, err := bookingTickets(transaction)
+	if err == nil {
+		var data []byte
+		if data, err = pdf.Tickets(transaction, tickets); err == nil {
+			attachments = append(attachments, mail.Attachment{Filename: ticketFilename(transaction), ContentType: "application/pdf", Data: data})
+		}
+	}
+	if err != nil {
+		log.Printf("e-ticket %d: %v", transaction.Id, err)
+	}
+	err = SendRescheduleEmail(transaction, reschedule, attachments...)
+	recordEmail(h.TransactionRepository, transaction, rescheduleSubject, attachments, err)
+
+	return reschedule, nil
+}
+
+// function failReschedule membatalkan reschedule yang selisihnya tidak dibayar (dari notifikasi midtrans),
+// booking tetap di keberangkatan lama
+func (h *handlerTransaction) failReschedule(reschedule models.Reschedule) {
+	reschedule, failed, err := h.TransactionRepository.FailReschedule(reschedule)
+	if err != nil {
+		log.Printf("reschedule %d: %v", reschedule.Id, err)
+		return
+	}
+	if !failed {
+		return
+	}
+	recordReschedule(h.TransactionRepository, nil, models.EventRescheduleFailed, reschedule, errors.New("fare difference was not paid"))
+
+	transaction, err := h.TransactionRepository.GetTransaction(reschedule.TransactionId)
+	if err != nil {
+		log.Printf("reschedule %d: %v", reschedule.Id, err)
+		return
+	}
+	err = SendRescheduleEmail(transaction, reschedule)
+	recordEmail(h.TransactionRepository, transaction, rescheduleSubject, nil, err)
+}
+
+// function rescheduleNotification memproses notifikasi midtrans untuk pembayaran selisih harga reschedule. notifikasi
+// dengan nominal yang berbeda dari selisih harga ditolak sebelum reschedule diproses
+func (h *handlerTransaction) rescheduleNotification(orderId string, transactionStatus string, fraudStatus string, grossAmount string) error {
+	id, _ := strconv.Atoi(strings.TrimPrefix(orderId, rescheduleOrderPrefix))
+	reschedule, err := h.TransactionRepository.GetReschedule(id)
+	if err != nil {
+		log.Printf("reschedule notification %s: %v", orderId, err)
+		return err
+	}
+	if !grossAmountEquals(grossAmount, reschedule.Difference.Amount) {
+		log.Printf("reschedule notification %s: gross_amount %s does not match difference %d", orderId, grossAmount, reschedule.Difference.Amount)
+		return errGrossAmount
+	}
+	transaction := models.Transaction{Id: reschedule.TransactionId, UserId: reschedule.UserId}
+	recordEvent(h.TransactionRepository, nil, transaction, models.TransactionEvent{Type: models.EventNotificationReceived, ActorType: models.ActorGateway}, map[string]interface{}{
+		"order_id":           orderId,
+		"transaction_status": transactionStatus,
+		"fraud_status":       fraudStatus,
+		"gross_amount":       grossAmount,
+	})
+
+	switch {
+	case transactionStatus == "settlement" || (transactionStatus == "capture" && fraudStatus == "accept"):
+		// selisih yang sudah dibayar untuk booking yang dibatalkan harus dikembalikan admin
+		if _, err := h.completeReschedule(nil, reschedule); err != nil {
+			log.Printf("reschedule %d: %v, refund the paid difference manually", reschedule.Id, err)
+		}
+	case transactionStatus == "deny" || transactionStatus == "cancel" || transactionStatus == "expire":
+		h.failReschedule(reschedule)
+	}
+	return nil
+}
+
+// function refundReschedule mengembalikan selisih harga ke pembayaran booking lewat midtrans. jika gagal
+// (misal metode pembayaran tidak mendukung refund) admin harus mengembalikannya secara manual
+func refundReschedule(reschedule models.Reschedule) string {
+	var c = coreapi.Client{}
+	c.New(os.Getenv("SERVER_KEY"), midtrans.Sandbox)
+
+	_, err := c.RefundTransaction(strconv.Itoa(reschedule.TransactionId), &coreapi.RefundReq{
+		RefundKey: rescheduleOrderId(reschedule.Id),
+		Amount:    -reschedule.Difference.Amount,
+		Reason:    "reschedule to another departure",
+	})
+	if err != nil {
+		log.Printf("refund reschedule %d: %s", reschedule.Id, err.GetMessage())
+		return models.RefundManual
+	}
+	return models.RefundRefunded
+}
+
+// function recordReschedule mencatat event reschedule di riwayat booking, r nil berarti event dari notifikasi midtrans
+func recordReschedule(repo transactionEventWriter, r *http.Request, eventType string, reschedule models.Reschedule, reason error) {
+	detail := map[string]interface{}{
+		"reschedule_id": reschedule.Id,
+		"from_trip_id":  reschedule.FromTripId,
+		"to_trip_id":    reschedule.ToTripId,
+		"old_total":     reschedule.OldTotal,
+		"total":         reschedule.Total,
+		"difference":    reschedule.Difference,
+	}
+	if reschedule.RefundStatus != models.RefundNone {
+		detail["refund_status"] = reschedule.RefundStatus
+	}
+	if reason != nil {
+		detail["reason"] = reason.Error()
+	}
+
+	event := models.TransactionEvent{Type: eventType}
+	if r == nil {
+		event.ActorType = models.ActorGateway
+	}
+	recordEvent(repo, r, models.Transaction{Id: reschedule.TransactionId, UserId: reschedule.UserId}, event, detail)
+}
+
+func rescheduleOrderId(id int) string {
+	return fmt.Sprintf("%s%d", rescheduleOrderPrefix, id)
+}
+
+// function SendRescheduleEmail mengirim hasil reschedule ke pemesan, email yang berhasil melampirkan e-ticket baru
+func SendRescheduleEmail(transaction models.Transaction, reschedule models.Reschedule, attachments ...mail.Attachment) error {
+	status := "Your booking has been moved to the new departure."
+	if reschedule.Status == models.RescheduleFailed {
+		status = "The fare difference was not paid, your booking stays on its original departure."
+	}
+
+	var difference string
+	switch {
+	case reschedule.Difference.Amount > 0:
+		difference = "Fare difference paid : " + reschedule.Difference.String()
+	case reschedule.Difference.Amount < 0:
+		refund := money.IDR(-reschedule.Difference.Amount)
+		difference = "Fare difference refunded : " + refund.String()
+		if reschedule.RefundStatus == models.RefundManual {
+			difference += " (our team will transfer the refund to you)"
+		}
+	default:
+		difference = "No fare difference"
+	}
+
+	err := mail.Send(mail.Message{
+		To:          transaction.User.Email,
+		Subject:     rescheduleSubject,
+		Attachments: attachments,
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Booking reschedule :</h2>
+      <p>%s</p>
+      <ul style="list-style-type:none;">
+        <li>Booking : #%d</li>
+        <li>Trip : %s</li>
+        <li>Departure : %s</li>
+        <li>%s</li>
+      </ul>
+      <ul style="list-style-type:none;">%s</ul>
+      </body>
+    </html>`, status, transaction.Id, html.EscapeString(transaction.Trip.Title),
+			transaction.Trip.DateTrip.Format("Monday, 2 January 2006"), difference, totalsHTML(transaction)),
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return err
+}
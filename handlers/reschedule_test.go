@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"project/models"
+	"testing"
+	"time"
+)
+
+func TestCheckRescheduleTarget(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	booking := models.Transaction{
+		TripId: 1,
+		Trip:   models.TripResponse{Id: 1, Title: "Bali 4D3N", CountryId: 1, GroupKey: "bali-4d3n", DateTrip: now.AddDate(0, 0, 10)},
+	}
+	target := models.Trip{Id: 2, Title: "Bali 4D3N", CountryId: 1, GroupKey: "bali-4d3n", DateTrip: now.AddDate(0, 0, 20)}
+
+	tests := []struct {
+		name    string
+		booking func(*models.Transaction)
+		trip    func(*models.Trip)
+		wantErr bool
+	}{
+		{"same group", func(b *models.Transaction) {}, func(t *models.Trip) {}, false},
+		{"renamed departure of the same group", func(b *models.Transaction) {}, func(t *models.Trip) { t.Title = "Bali Getaway" }, false},
+		{"same title in another group", func(b *models.Transaction) {}, func(t *models.Trip) { t.GroupKey = "bali-budget" }, true},
+		{"no group key", func(b *models.Transaction) { b.Trip.GroupKey = "" }, func(t *models.Trip) { t.GroupKey = "" }, true},
+		{"same departure", func(b *models.Transaction) {}, func(t *models.Trip) { t.Id = 1 }, true},
+		{"booking already departed", func(b *models.Transaction) { b.Trip.DateTrip = now }, func(t *models.Trip) {}, true},
+		{"target in the past", func(b *models.Transaction) {}, func(t *models.Trip) { t.DateTrip = now.AddDate(0, 0, -1) }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, trip := booking, target
+			tt.booking(&b)
+			tt.trip(&trip)
+			if err := checkRescheduleTarget(b, trip, now); (err != nil) != tt.wantErr {
+				t.Errorf("checkRescheduleTarget() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/policy"
+	"project/repositories"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerSession struct {
+	SessionRepository repositories.SessionRepository
+}
+
+func HandlerSession(SessionRepository repositories.SessionRepository) *handlerSession {
+	return &handlerSession{SessionRepository}
+}
+
+// function FindSessions menampilkan semua sesi aktif milik user yang login
+func (h *handlerSession) FindSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	currentTokenId, _ := userInfo["sid"].(string)
+
+	sessions, err := h.SessionRepository.FindSessionsByUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertSessionsResponse(sessions, currentTokenId)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function RevokeSession mencabut satu sesi milik user (admin boleh mencabut sesi user lain)
+func (h *handlerSession) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	session, err := h.SessionRepository.GetSession(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if err := policy.Authorize(policy.FromRequest(r), policy.SessionRevokeAny, session.UserId); err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if err := h.SessionRepository.RevokeSession(session.Id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "session revoked"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function RevokeOtherSessions mencabut semua sesi user kecuali sesi yang sedang dipakai
+func (h *handlerSession) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	currentTokenId, _ := userInfo["sid"].(string)
+
+	if err := h.SessionRepository.RevokeOtherSessions(userId, currentTokenId); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "other sessions revoked"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function RevokeUserSessions dipakai admin untuk mengeluarkan user dari semua perangkat (misal akun dibobol)
+func (h *handlerSession) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	if _, err := h.SessionRepository.GetUser(id); err != nil {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
+
+	if err := h.SessionRepository.RevokeAllSessions(id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "all sessions revoked"}
+	json.NewEncoder(w).Encode(response)
+}
+
+func convertSessionsResponse(sessions []models.Session, currentTokenId string) []dto.SessionResponse {
+	result := []dto.SessionResponse{}
+	for _, s := range sessions {
+		result = append(result, dto.SessionResponse{
+			Id:           s.Id,
+			Device:       s.Device,
+			IP:           s.IP,
+			UserAgent:    s.UserAgent,
+			CreatedAt:    s.CreatedAt,
+			LastSeenAt:   s.LastSeenAt,
+			ExpiresAt:    s.ExpiresAt,
+			Current:      s.TokenId == currentTokenId,
+			Impersonated: s.ImpersonatorId != 0,
+		})
+	}
+	return result
+}
+
+// function deviceFromUserAgent membuat label perangkat sederhana dari user agent, misal "Chrome on Android"
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	platform := "Unknown device"
+	for _, p := range []struct{ match, name string }{
+		{"android", "Android"},
+		{"iphone", "iPhone"},
+		{"ipad", "iPad"},
+		{"windows", "Windows"},
+		{"mac os", "macOS"},
+		{"linux", "Linux"},
+	} {
+		if strings.Contains(ua, p.match) {
+			platform = p.name
+			break
+		}
+	}
+
+	// urutan penting karena user agent Chrome juga mengandung "safari" dan Edge mengandung "chrome"
+	for _, b := range []struct{ match, name string }{
+		{"edg/", "Edge"},
+		{"firefox/", "Firefox"},
+		{"chrome/", "Chrome"},
+		{"safari/", "Safari"},
+		{"postman", "Postman"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(ua, b.match) {
+			return b.name + " on " + platform
+		}
+	}
+
+	return platform
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/repositories"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type handlerTaxRule struct {
+	TaxRuleRepository repositories.TaxRuleRepository
+}
+
+func HandlerTaxRule(TaxRuleRepository repositories.TaxRuleRepository) *handlerTaxRule {
+	return &handlerTaxRule{TaxRuleRepository}
+}
+
+func (h *handlerTaxRule) FindTaxRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rules, err := h.TaxRuleRepository.FindTaxRules(false)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rules}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerTaxRule) CreateTaxRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.CreateTaxRuleRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	rule := models.TaxRule{
+		Name:        strings.TrimSpace(request.Name),
+		Kind:        request.Kind,
+		TripType:    strings.ToLower(strings.TrimSpace(request.TripType)),
+		CountryId:   request.CountryId,
+		RateBps:     request.RateBps,
+		FixedAmount: request.FixedAmount,
+		PerPerson:   request.PerPerson,
+		Active:      request.Active == nil || *request.Active,
+	}
+	if err := validateTaxRule(rule); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	rule, err := h.TaxRuleRepository.CreateTaxRule(rule)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.TaxRuleRepository, r, "tax_rule.created", 0, rule)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rule}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function UpdateTaxRule mengubah aturan pajak. booking lama tidak ikut berubah karena rinciannya sudah tersimpan
+func (h *handlerTaxRule) UpdateTaxRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdateTaxRuleRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	rule, err := h.TaxRuleRepository.GetTaxRule(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "tax rule not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if request.Name != nil {
+		rule.Name = strings.TrimSpace(*request.Name)
+	}
+	if request.Kind != nil {
+		rule.Kind = *request.Kind
+	}
+	if request.TripType != nil {
+		rule.TripType = strings.ToLower(strings.TrimSpace(*request.TripType))
+	}
+	if request.CountryId != nil {
+		rule.CountryId = *request.CountryId
+	}
+	if request.RateBps != nil {
+		rule.RateBps = *request.RateBps
+	}
+	if request.FixedAmount != nil {
+		rule.FixedAmount = *request.FixedAmount
+	}
+	if request.PerPerson != nil {
+		rule.PerPerson = *request.PerPerson
+	}
+	if request.Active != nil {
+		rule.Active = *request.Active
+	}
+	if err := validateTaxRule(rule); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	rule, err = h.TaxRuleRepository.UpdateTaxRule(rule)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.TaxRuleRepository, r, "tax_rule.updated", 0, rule)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rule}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerTaxRule) DeleteTaxRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	rule, err := h.TaxRuleRepository.GetTaxRule(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "tax rule not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.TaxRuleRepository.DeleteTaxRule(rule); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.TaxRuleRepository, r, "tax_rule.deleted", 0, map[string]interface{}{"tax_rule_id": rule.Id, "name": rule.Name})
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: rule}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function validateTaxRule memastikan aturan menghasilkan biaya
+func validateTaxRule(rule models.TaxRule) error {
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if rule.RateBps == 0 && rule.FixedAmount == 0 {
+		return errors.New("rate_bps or fixed_amount is required")
+	}
+	if rule.PerPerson && rule.FixedAmount == 0 {
+		return errors.New("per_person requires fixed_amount")
+	}
+	return nil
+}
@@ -1,25 +1,33 @@
 package handlers
 
 import (
-	"crypto/tls"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"net/http"
 	"os"
 	dto "project/dto"
 	"project/models"
+	"project/pkg/mail"
+	"project/pkg/money"
+	"project/pkg/policy"
+	"project/pkg/pricing"
 	"project/repositories"
 	"strconv"
+	"strings"
 	"time"
 
-	"gopkg.in/gomail.v2"
-
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
 	"github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/snap"
+	"gorm.io/gorm"
 )
 
 var path_file_trans = "http://localhost:5000/uploads/"
@@ -55,6 +63,71 @@ func (h *handlerTransaction) FindTransactions(w http.ResponseWriter, r *http.Req
 	json.NewEncoder(w).Encode(response)
 }
 
+// function TransactionReport menjumlahkan subtotal, potongan, biaya layanan, pajak dan total transaksi yang berhasil
+// antara ?from= dan ?to= (YYYY-MM-DD, to ikut dihitung). default bulan berjalan
+func (h *handlerTransaction) TransactionReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	now := timeIn("Asia/Jakarta")
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := from.AddDate(0, 1, -1)
+	for param, value := range map[string]*time.Time{"from": &from, "to": &to} {
+		if r.URL.Query().Get(param) == "" {
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", r.URL.Query().Get(param), now.Location())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: param + " must be a date (YYYY-MM-DD)"}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		*value = date
+	}
+	if to.Before(from) {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "to must not be before from"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	totals, err := h.TransactionRepository.SumTransactions(from, to.AddDate(0, 0, 1))
+	var charges []repositories.ChargeTotal
+	if err == nil {
+		charges, err = h.TransactionRepository.SumTransactionCharges(from, to.AddDate(0, 0, 1))
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	report := dto.TransactionReport{
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		Transactions: totals.Count,
+		Subtotal:     money.IDR(totals.Subtotal),
+		Discount:     money.IDR(totals.Discount),
+		Fees:         money.IDR(totals.Fees),
+		Tax:          money.IDR(totals.Tax),
+		Total:        money.IDR(totals.Total),
+		Charges:      []dto.ReportCharge{},
+	}
+	for _, charge := range charges {
+		report.Charges = append(report.Charges, dto.ReportCharge{
+			Kind:        charge.Kind,
+			Code:        charge.Code,
+			Description: charge.Description,
+			Amount:      money.IDR(charge.Amount),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: report}
+	json.NewEncoder(w).Encode(response)
+}
+
 func (h *handlerTransaction) GetAllTransactionByUser(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -89,11 +162,10 @@ func (h *handlerTransaction) GetTransaction(w http.ResponseWriter, r *http.Reque
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-	trans, err := h.TransactionRepository.GetTransaction(id)
+	// hanya pemilik transaction atau admin yang boleh melihat transaction
+	trans, err := h.authorizeTransaction(r, id, policy.TransactionReadAny)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		policy.Deny(w, err)
 		return
 	}
 
@@ -115,17 +187,33 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
 	userId := int(userInfo["id"].(float64))
 
+	// booking hanya bisa dibuat oleh user yang emailnya sudah diverifikasi
+	user, err := h.TransactionRepository.GetUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		response := dto.ErrorResult{Code: http.StatusUnauthorized, Message: "unauthorized"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if user.EmailVerifiedAt == nil {
+		w.WriteHeader(http.StatusForbidden)
+		response := dto.ErrorResult{Code: http.StatusForbidden, Message: "please verify your email before booking"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	// mengambil data dari request form
 	counterqty, _ := strconv.Atoi(r.FormValue("counter_qty"))
 	total, _ := strconv.Atoi(r.FormValue("total"))
-	// status := r.FormValue("status")
 	tripId, _ := strconv.Atoi(r.FormValue("trip_id"))
 	request := dto.CreateTransactionRequest{
-		CounterQty: counterqty,
-		Total:      total,
-		// Status:     status,
-		TripId: tripId,
-		UserId: userId,
+		CounterQty:  counterqty,
+		Total:       total,
+		TripId:      tripId,
+		UserId:      userId,
+		PromoCode:   r.FormValue("promo_code"),
+		Passengers:  parsePassengers(r.FormValue("passengers")),
+		TravelerIds: parseIds(r.FormValue("traveler_ids")),
 		// Image:      filename,
 	}
 
@@ -133,7 +221,7 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 
 	// memvalidasi inputan dari request body berdasarkan struct dto.TransactionRequest
 	validation := validator.New()
-	err := validation.Struct(request)
+	err = validation.Struct(request)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
@@ -141,6 +229,91 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// jumlah penumpang per jenis (dewasa, anak, lansia) untuk aturan harga trip
+	passengers, quantity, err := bookingPassengers(request.CounterQty, request.Passengers)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	request.CounterQty = quantity
+
+	// kursi yang sedang ditawarkan ke antrean waitlist user lain tidak bisa dibooking
+	trip, err := h.TransactionRepository.GetTrip(request.TripId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	held, err := h.TransactionRepository.HeldWaitlistSeats(trip.Id, userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if request.CounterQty > trip.Quota-held {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "not enough seats left, join the waitlist to be notified when seats free up"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// traveler tersimpan yang dipilih, datanya disalin ke booking
+	var travelers []models.TransactionTraveler
+	if len(request.TravelerIds) > 0 {
+		if len(request.TravelerIds) > request.CounterQty {
+			w.WriteHeader(http.StatusBadRequest)
+			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "number of travelers exceeds counter_qty"}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		saved, err := h.TransactionRepository.FindTravelersByIds(userId, request.TravelerIds)
+		if err != nil || len(saved) != len(uniqueIds(request.TravelerIds)) {
+			w.WriteHeader(http.StatusBadRequest)
+			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "traveler not found"}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		for _, t := range saved {
+			travelers = append(travelers, models.TransactionTraveler{
+				TravelerId:     t.Id,
+				FullName:       t.FullName,
+				Gender:         t.Gender,
+				Nationality:    t.Nationality,
+				DateOfBirth:    t.DateOfBirth,
+				DocumentType:   t.DocumentType,
+				DocumentNumber: t.DocumentNumber,
+				DocumentExpiry: t.DocumentExpiry,
+			})
+		}
+	}
+
+	// harga dihitung di server dari aturan harga trip, add-on dan promo code
+	price, err := priceBooking(h.TransactionRepository, userId, trip, bookingOrder{
+		Passengers: passengers,
+		Quantity:   quantity,
+		AddOns:     addOnSelections(request.AddOns, parseAddOns(r.FormValue("add_ons"))),
+		PromoCode:  request.PromoCode,
+	})
+	var checkoutErr pricing.Error
+	if errors.As(err, &checkoutErr) {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: checkoutErr.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	// membuat id unik, dan melakukan pengecekan dengan looping
 	var TrxIdMatch = false
 	var TrxId int
@@ -156,15 +329,31 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 	newTransaction := models.Transaction{
 		Id:          TrxId,
 		CounterQty:  request.CounterQty,
-		Total:       request.Total,
-		Status:      request.Status,
+		Total:       money.IDR(int64(price.Total)),
+		Subtotal:    money.IDR(int64(price.Subtotal)),
+		Discount:    money.IDR(int64(price.Discount)),
+		Fees:        money.IDR(int64(price.Fees)),
+		Tax:         money.IDR(int64(price.Tax)),
+		PromoCodeId: price.PromoCode.Id,
+		PromoCode:   price.PromoCode.Code,
+		Items:       price.Items,
+		// status tidak diterima dari client, booking baru selalu pending sampai ada notifikasi pembayaran
+		Status:      "pending",
 		TripId:      request.TripId,
 		UserId:      userId,
 		BookingDate: timeIn("Asia/Jakarta"),
+		Travelers:   travelers,
 	}
 
 	// mengirim data Transaction baru ke database
-	transaction, err := h.TransactionRepository.CreateTransaction(newTransaction)
+	// kursi langsung ditahan dan dilepas lagi jika booking tidak dibayar sampai batas waktu
+	transaction, err := h.TransactionRepository.CreateTransaction(newTransaction, held)
+	if errors.As(err, &checkoutErr) {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: checkoutErr.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
@@ -172,6 +361,18 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	recordEvent(h.TransactionRepository, r, transaction, models.TransactionEvent{Type: models.EventCreated, ToStatus: transaction.Status}, map[string]interface{}{
+		"trip_id":     transaction.TripId,
+		"counter_qty": transaction.CounterQty,
+		"total":       transaction.Total,
+		"promo_code":  transaction.PromoCode,
+	})
+
+	// antrean waitlist user untuk trip ini selesai karena sudah booking
+	if err := h.TransactionRepository.MarkWaitlistBooked(userId, transaction.TripId, transaction.Id); err != nil {
+		log.Println("waitlist:", err)
+	}
+
 	// mengambil data transaction yang baru ditambahkan
 	TransactionAdded, err := h.TransactionRepository.GetTransaction(transaction.Id)
 	if err != nil {
@@ -184,24 +385,11 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 	var s = snap.Client{}
 	s.New(os.Getenv("SERVER_KEY"), midtrans.Sandbox)
 
-	req := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  strconv.Itoa(TransactionAdded.Id),
-			GrossAmt: int64(TransactionAdded.Total),
-		},
-		CreditCard: &snap.CreditCardDetails{
-			Secure: true,
-		},
-		CustomerDetail: &midtrans.CustomerDetails{
-			FName: TransactionAdded.User.Name,
-			Email: TransactionAdded.User.Email,
-		},
-	}
-
-	snapResp, _ := s.CreateTransaction(req)
+	snapResp, _ := s.CreateTransaction(bookingSnapRequest(TransactionAdded))
 
 	// mengupdate token di database
 	updateTransaction, _ := h.TransactionRepository.UpdateTokenTransaction(snapResp.Token, TransactionAdded.Id)
+	recordEvent(h.TransactionRepository, r, TransactionAdded, models.TransactionEvent{Type: models.EventTokenIssued}, nil)
 
 	// mengambil data transaction yang baru diupdate
 	transactionUpdated, _ := h.TransactionRepository.GetTransaction(updateTransaction.Id)
@@ -215,32 +403,33 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 }
 
 func (h *handlerTransaction) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
 	id, _ := strconv.Atoi(mux.Vars(r)["id_transaction"])
 
-	// mengambil data transaction yang baru ditambahkan
-	transaction, _ := h.TransactionRepository.GetTransaction(id)
+	// mengambil data transaction, hanya pemilik atau admin yang boleh membuat ulang token pembayaran
+	transaction, err := h.authorizeTransaction(r, id, policy.TransactionUpdateAny)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	// token hanya dibuat ulang untuk booking yang masih menunggu pembayaran, kursi booking yang gagal sudah dilepas
+	if transaction.Status != "pending" {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "booking is no longer awaiting payment"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
 
 	var s = snap.Client{}
 	s.New(os.Getenv("SERVER_KEY"), midtrans.Sandbox)
 
-	req := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  strconv.Itoa(transaction.Id),
-			GrossAmt: int64(transaction.Total),
-		},
-		CreditCard: &snap.CreditCardDetails{
-			Secure: true,
-		},
-		CustomerDetail: &midtrans.CustomerDetails{
-			FName: transaction.User.Name,
-			Email: transaction.User.Email,
-		},
-	}
-
-	snapResp, _ := s.CreateTransaction(req)
+	snapResp, _ := s.CreateTransaction(bookingSnapRequest(transaction))
 
 	// mengupdate token di database
 	transaction, _ = h.TransactionRepository.UpdateTokenTransaction(snapResp.Token, id)
+	recordEvent(h.TransactionRepository, r, transaction, models.TransactionEvent{Type: models.EventTokenRegenerated}, nil)
 
 	// mengambil data transaction yang baru diupdate
 	transactionUpdated, _ := h.TransactionRepository.GetTransaction(id)
@@ -255,22 +444,40 @@ func (h *handlerTransaction) UpdateTransaction(w http.ResponseWriter, r *http.Re
 	json.NewEncoder(w).Encode(response)
 }
 
-// function send email
-func SendEmail(status string, transaction models.Transaction) {
-	var CONFIG_SMTP_HOST = "smtp.gmail.com"
-	var CONFIG_SMTP_PORT = 587
-	var CONFIG_SENDER_NAME = "dewetour <rafialfian770@gmail.com>"
-	var CONFIG_AUTH_EMAIL = os.Getenv("SYSTEM_EMAIL")
-	var CONFIG_AUTH_PASSWORD = os.Getenv("SYSTEM_PASSWORD")
+// function bookingSnapRequest membuat request pembayaran booking. batas bayar dihitung dari waktu booking
+// sehingga token yang dibuat ulang tidak memperpanjang kursi yang ditahan
+func bookingSnapRequest(transaction models.Transaction) *snap.Request {
+	return &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  strconv.Itoa(transaction.Id),
+			GrossAmt: transaction.Total.Amount,
+		},
+		Items: midtransItems(transaction.Items),
+		CreditCard: &snap.CreditCardDetails{
+			Secure: true,
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			FName: transaction.User.Name,
+			Email: transaction.User.Email,
+		},
+		Expiry: &snap.ExpiryDetails{
+			StartTime: transaction.BookingDate.Format("2006-01-02 15:04:05 -0700"),
+			Unit:      "minute",
+			Duration:  int64(models.BookingPaymentTTL / time.Minute),
+		},
+	}
+}
 
+// function send email, email konfirmasi pembayaran melampirkan invoice dan e-ticket
+func SendEmail(status string, transaction models.Transaction, attachments ...mail.Attachment) error {
 	var tripName = transaction.User.Name
-	var price = strconv.Itoa(transaction.Total)
+	var price = transaction.Total.String()
 
-	mailer := gomail.NewMessage()
-	mailer.SetHeader("From", CONFIG_SENDER_NAME)
-	mailer.SetHeader("To", transaction.User.Email)
-	mailer.SetHeader("Subject", "Status Transaction")
-	mailer.SetBody("text/html", fmt.Sprintf(`<!DOCTYPE html>
+	err := mail.Send(mail.Message{
+		To:          transaction.User.Email,
+		Subject:     "Status Transaction",
+		Attachments: attachments,
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
     <html lang="en">
       <head>
       <meta charset="UTF-8" />
@@ -287,26 +494,26 @@ func SendEmail(status string, transaction models.Transaction) {
       <h2>Product payment :</h2>
       <ul style="list-style-type:none;">
         <li>Name : %s</li>
-        <li>Total payment: Rp.%s</li>
+        <li>Total payment: %s</li>
         <li>Status : %s</li>
 		<li>Iklan : %s</li>
       </ul>
+      <h3>Price details :</h3>
+      <ul style="list-style-type:none;">%s</ul>
+      <ul style="list-style-type:none;">%s</ul>
       </body>
-    </html>`, tripName, price, status, "Terima kasih"))
-
-	dialer := gomail.NewDialer(
-		CONFIG_SMTP_HOST,
-		CONFIG_SMTP_PORT,
-		CONFIG_AUTH_EMAIL,
-		CONFIG_AUTH_PASSWORD,
-	)
-
-	dialer.TLSConfig = &tls.Config{InsecureSkipVerify: true}
-
-	err := dialer.DialAndSend(mailer)
+    </html>`, tripName, price, status, "Terima kasih", itemsHTML(transaction.Items), totalsHTML(transaction)),
+	})
 	if err != nil {
-		log.Fatal(err.Error())
+		log.Println(err.Error())
 	}
+	return err
+}
+
+// function sendEmail mengirim email status transaksi lalu mencatatnya di riwayat booking
+func (h *handlerTransaction) sendEmail(status string, transaction models.Transaction, attachments ...mail.Attachment) {
+	err := SendEmail(status, transaction, attachments...)
+	recordEmail(h.TransactionRepository, transaction, status, attachments, err)
 }
 
 // function notification (mengixinkan mitrans untuk mengupdate status transaksi)
@@ -321,61 +528,153 @@ func (h *handlerTransaction) Notification(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// transaksi status
-	transactionStatus := notificationPayload["transaction_status"].(string)
-	fraudStatus := notificationPayload["fraud_status"].(string)
-	orderId := notificationPayload["order_id"].(int)
-	// orderIdInt, _ := strconv.Atoi(orderId)
+	// notifikasi palsu ditolak, signature_key hanya bisa dibuat oleh midtrans dengan server key
+	if !validSignature(notificationPayload, os.Getenv("SERVER_KEY")) {
+		w.WriteHeader(http.StatusUnauthorized)
+		response := dto.ErrorResult{Code: http.StatusUnauthorized, Message: "invalid signature"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// transaksi status. fraud_status hanya dikirim untuk pembayaran kartu
+	transactionStatus, _ := notificationPayload["transaction_status"].(string)
+	fraudStatus, _ := notificationPayload["fraud_status"].(string)
+	grossAmount := fmt.Sprint(notificationPayload["gross_amount"])
+	orderId := fmt.Sprint(notificationPayload["order_id"])
+
+	// pembayaran selisih harga reschedule memakai order id sendiri
+	if strings.HasPrefix(orderId, rescheduleOrderPrefix) {
+		err := h.rescheduleNotification(orderId, transactionStatus, fraudStatus, grossAmount)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			response := dto.ErrorResult{Code: http.StatusNotFound, Message: "reschedule not found"}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	orderIdInt, _ := strconv.Atoi(orderId)
 
 	// panggil function get transaction
-	transaction, _ := h.TransactionRepository.GetTransaction(orderId)
+	transaction, err := h.TransactionRepository.GetTransaction(orderIdInt)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "transaction not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	// nominal yang dibayar harus sama dengan total booking
+	if !grossAmountEquals(grossAmount, transaction.Total.Amount) {
+		log.Printf("notification %s: gross_amount %s does not match total %d", orderId, grossAmount, transaction.Total.Amount)
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: errGrossAmount.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
 	fmt.Println(transactionStatus, fraudStatus, orderId, transaction)
 
+	previousStatus := transaction.Status
+	notification := map[string]interface{}{
+		"transaction_status": transactionStatus,
+		"fraud_status":       fraudStatus,
+		"payment_type":       notificationPayload["payment_type"],
+		"gross_amount":       grossAmount,
+	}
+	recordEvent(h.TransactionRepository, nil, transaction, models.TransactionEvent{Type: models.EventNotificationReceived, ActorType: models.ActorGateway}, notification)
+
 	// kondisi transaksi
 	if transactionStatus == "capture" {
 		if fraudStatus == "challenge" {
-			SendEmail("Transaction Failed", transaction)
+			h.sendEmail("Transaction Failed", transaction)
 			transaction.Status = "failed"
-			h.TransactionRepository.UpdateTransaction("pending", transaction.Id)
+			h.updateStatus(transaction, previousStatus, "pending", notification)
 		} else if fraudStatus == "accept" {
-			SendEmail("Transaction Success", transaction)
 			transaction.Status = "success"
-			h.TransactionRepository.UpdateTransaction("success", transaction.Id)
+			if h.updateStatus(transaction, previousStatus, "success", notification) {
+				h.sendEmail("Transaction Success", transaction, h.confirmationAttachments(transaction)...)
+			}
 		}
 	} else if transactionStatus == "settlement" {
-		SendEmail("Transaction Success", transaction)
 		transaction.Status = "success"
-		h.TransactionRepository.UpdateTransaction("success", transaction.Id)
+		if h.updateStatus(transaction, previousStatus, "success", notification) {
+			h.sendEmail("Transaction Success", transaction, h.confirmationAttachments(transaction)...)
+		}
 	} else if transactionStatus == "deny" {
-		SendEmail("Transaction Failed", transaction)
+		h.sendEmail("Transaction Failed", transaction)
 		transaction.Status = "failed"
-		h.TransactionRepository.UpdateTransaction("failed", transaction.Id)
+		h.updateStatus(transaction, previousStatus, "failed", notification)
 	} else if transactionStatus == "cancel" || transactionStatus == "expire" {
-		SendEmail("Transaction Failed", transaction)
+		h.sendEmail("Transaction Failed", transaction)
 		transaction.Status = "failed"
-		h.TransactionRepository.UpdateTransaction("failed", transaction.Id)
+		h.updateStatus(transaction, previousStatus, "failed", notification)
 	} else if transactionStatus == "pending" {
-		SendEmail("Transaction Pending", transaction)
+		h.sendEmail("Transaction Pending", transaction)
 		transaction.Status = "pending"
-		h.TransactionRepository.UpdateTransaction("pending", transaction.Id)
+		h.updateStatus(transaction, previousStatus, "pending", notification)
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+var errGrossAmount = errors.New("gross_amount does not match the order")
+
+// function validSignature memeriksa signature_key notifikasi midtrans, yaitu
+// SHA512(order_id + status_code + gross_amount + server key) dalam hex
+func validSignature(payload map[string]interface{}, serverKey string) bool {
+	signature, _ := payload["signature_key"].(string)
+	if serverKey == "" || signature == "" {
+		return false
+	}
+	sum := sha512.Sum512([]byte(fmt.Sprint(payload["order_id"]) + fmt.Sprint(payload["status_code"]) + fmt.Sprint(payload["gross_amount"]) + serverKey))
+	return subtle.ConstantTimeCompare([]byte(strings.ToLower(signature)), []byte(hex.EncodeToString(sum[:]))) == 1
+}
+
+// function grossAmountEquals membandingkan gross_amount midtrans (misal "150000.00") dengan nominal rupiah
+func grossAmountEquals(grossAmount string, amount int64) bool {
+	gross, ok := new(big.Rat).SetString(grossAmount)
+	return ok && gross.Cmp(new(big.Rat).SetInt64(amount)) == 0
+}
+
+// function updateStatus mengubah status transaksi dari notifikasi midtrans lalu mencatat perubahannya di riwayat booking
+// dengan isi notifikasi sebagai alasan, misal deny atau expire. false jika status gagal diubah, termasuk pembayaran
+// terlambat yang kursinya sudah habis (booking ditandai untuk refund dan pemesan diberi tahu)
+func (h *handlerTransaction) updateStatus(transaction models.Transaction, previousStatus string, status string, notification map[string]interface{}) bool {
+	updated, confirmed, err := h.TransactionRepository.UpdateTransaction(status, transaction.Id)
+	if err != nil {
+		log.Printf("transaction %d: %v", transaction.Id, err)
+		return false
+	}
+	if !confirmed {
+		h.sendEmail("Transaction Failed - Refund In Progress", updated)
+		return false
+	}
+	if status != previousStatus {
+		event := models.TransactionEvent{Type: models.EventStatusChanged, FromStatus: previousStatus, ToStatus: status, ActorType: models.ActorGateway}
+		recordEvent(h.TransactionRepository, nil, transaction, event, notification)
+	}
+	return true
+}
+
 func (h *handlerTransaction) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
-	user, err := h.TransactionRepository.GetTransaction(id)
+
+	// hanya pemilik transaction atau admin yang boleh menghapus transaction
+	transaction, err := h.authorizeTransaction(r, id, policy.TransactionDeleteAny)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		policy.Deny(w, err)
 		return
 	}
 
-	data, err := h.TransactionRepository.DeleteTransaction(user)
+	data, err := h.TransactionRepository.DeleteTransaction(transaction)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
@@ -383,17 +682,44 @@ func (h *handlerTransaction) DeleteTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// riwayat booking tetap disimpan setelah transaction dihapus
+	recordEvent(h.TransactionRepository, r, transaction, models.TransactionEvent{Type: models.EventDeleted, FromStatus: transaction.Status}, nil)
+
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseTransaction(data)}
 	json.NewEncoder(w).Encode(response)
 }
 
+// function authorizeTransaction mengambil transaction berdasarkan id lalu memastikan user yang login
+// adalah pemilik transaction atau memiliki permission. transaction yang tidak ada menghasilkan policy.ErrNotFound
+func (h *handlerTransaction) authorizeTransaction(r *http.Request, id int, permission policy.Permission) (models.Transaction, error) {
+	transaction, err := h.TransactionRepository.GetTransaction(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return transaction, policy.ErrNotFound
+	}
+	if err != nil {
+		return transaction, err
+	}
+
+	if err := policy.Authorize(policy.FromRequest(r), permission, transaction.UserId); err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
 // membuat fungsi konversi data yang akan disajikan sebagai response sesuai requirement
 func convertResponseTransaction(t models.Transaction) dto.TransactionResponse {
 	return dto.TransactionResponse{
 		Id:         t.Id,
 		CounterQty: t.CounterQty,
 		Total:      t.Total,
+		Subtotal:   t.Subtotal,
+		Discount:   t.Discount,
+		Fees:       t.Fees,
+		Tax:        t.Tax,
+		PromoCode:  t.PromoCode,
+		Items:      t.Items,
 		Status:     t.Status,
 		Token:      t.Token,
 		Trip: dto.TripResponse{
@@ -401,6 +727,7 @@ func convertResponseTransaction(t models.Transaction) dto.TransactionResponse {
 			Title:          t.Trip.Title,
 			Country:        t.Trip.Country,
 			Accomodation:   t.Trip.Accomodation,
+			Type:           t.Trip.Type,
 			Transportation: t.Trip.Transportation,
 			Eat:            t.Trip.Eat,
 			Day:            t.Trip.Day,
@@ -409,7 +736,8 @@ func convertResponseTransaction(t models.Transaction) dto.TransactionResponse {
 			Quota:          t.Trip.Quota,
 			Description:    t.Trip.Description,
 		},
-		User: t.User,
+		User:      t.User,
+		Travelers: convertTransactionTravelers(t.Travelers),
 		// Image:      u.Image,
 	}
 }
@@ -420,14 +748,22 @@ func convertOneTransactionResponse(t models.Transaction) dto.TransactionResponse
 		Id:         t.Id,
 		CounterQty: t.CounterQty,
 		Total:      t.Total,
+		Subtotal:   t.Subtotal,
+		Discount:   t.Discount,
+		Fees:       t.Fees,
+		Tax:        t.Tax,
+		PromoCode:  t.PromoCode,
+		Items:      t.Items,
 		Status:     t.Status,
 		Token:      t.Token,
 		User:       t.User,
+		Travelers:  convertTransactionTravelers(t.Travelers),
 		Trip: dto.TripResponse{
 			Id:             t.Trip.Id,
 			Title:          t.Trip.Title,
 			Country:        t.Trip.Country,
 			Accomodation:   t.Trip.Accomodation,
+			Type:           t.Trip.Type,
 			Transportation: t.Trip.Transportation,
 			Eat:            t.Trip.Eat,
 			Day:            t.Trip.Day,
@@ -456,14 +792,22 @@ func convertMultipleTransactionResponse(t []models.Transaction) []dto.Transactio
 			Id:         t.Id,
 			CounterQty: t.CounterQty,
 			Total:      t.Total,
+			Subtotal:   t.Subtotal,
+			Discount:   t.Discount,
+			Fees:       t.Fees,
+			Tax:        t.Tax,
+			PromoCode:  t.PromoCode,
+			Items:      t.Items,
 			Status:     t.Status,
 			Token:      t.Token,
 			User:       t.User,
+			Travelers:  convertTransactionTravelers(t.Travelers),
 			Trip: dto.TripResponse{
 				Id:             t.Trip.Id,
 				Title:          t.Trip.Title,
 				Country:        t.Trip.Country,
 				Accomodation:   t.Trip.Accomodation,
+				Type:           t.Trip.Type,
 				Transportation: t.Trip.Transportation,
 				Eat:            t.Trip.Eat,
 				Day:            t.Trip.Day,
@@ -484,6 +828,29 @@ func convertMultipleTransactionResponse(t []models.Transaction) []dto.Transactio
 	return result
 }
 
+// function parseIds membaca daftar id dari form, misal "1,2,3"
+func parseIds(value string) []int {
+	var ids []int
+	for _, part := range strings.Split(value, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func uniqueIds(ids []int) []int {
+	seen := map[int]bool{}
+	var result []int
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
 // fungsi untuk mendapatkan waktu sesuai zona indonesia
 func timeIn(name string) time.Time {
 	loc, err := time.LoadLocation(name)
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/mail"
+	"project/pkg/policy"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// function GetTransactionHistory menampilkan riwayat booking untuk pemilik booking dan admin. riwayat booking yang sudah
+// dihapus tetap bisa dibaca, pemiliknya diambil dari event
+func (h *handlerTransaction) GetTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	_, err := h.authorizeTransaction(r, id, policy.TransactionReadAny)
+	if err != nil && !errors.Is(err, policy.ErrNotFound) {
+		policy.Deny(w, err)
+		return
+	}
+	deleted := err != nil
+
+	events, err := h.TransactionRepository.FindTransactionEvents(id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if deleted {
+		if len(events) == 0 {
+			policy.Deny(w, policy.ErrNotFound)
+			return
+		}
+		if err := policy.Authorize(policy.FromRequest(r), policy.TransactionReadAny, eventOwner(events)); err != nil {
+			policy.Deny(w, err)
+			return
+		}
+	}
+
+	result := []dto.TransactionEventResponse{}
+	for _, event := range events {
+		item := dto.TransactionEventResponse{
+			Id:             event.Id,
+			Type:           event.Type,
+			FromStatus:     event.FromStatus,
+			ToStatus:       event.ToStatus,
+			ActorId:        event.ActorId,
+			ActorType:      event.ActorType,
+			ImpersonatorId: event.ImpersonatorId,
+			CreatedAt:      event.CreatedAt,
+		}
+		if event.Detail != "" {
+			item.Detail = json.RawMessage(event.Detail)
+		}
+		result = append(result, item)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function eventOwner mengembalikan pemilik booking yang tersimpan di riwayat, 0 jika tidak ada
+func eventOwner(events []models.TransactionEvent) int {
+	for _, event := range events {
+		if event.UserId != 0 {
+			return event.UserId
+		}
+	}
+	return 0
+}
+
+// transactionEventWriter dipenuhi oleh repository yang memiliki method CreateTransactionEvent
+type transactionEventWriter interface {
+	CreateTransactionEvent(event models.TransactionEvent) error
+}
+
+// function recordEvent mencatat event ke riwayat booking. actor diambil dari r, r nil berarti event dari midtrans
+// atau sistem sesuai event.ActorType. kegagalan mencatat hanya dilog agar tidak menggagalkan request
+func recordEvent(repo transactionEventWriter, r *http.Request, transaction models.Transaction, event models.TransactionEvent, detail interface{}) {
+	event.TransactionId = transaction.Id
+	event.UserId = transaction.UserId
+	if r != nil {
+		subject := policy.FromRequest(r)
+		event.ActorId = subject.Id
+		event.ImpersonatorId = subject.ImpersonatorId
+		event.ActorType = models.ActorUser
+		if subject.Id != transaction.UserId {
+			event.ActorType = subject.Role
+		}
+	}
+	if event.ActorType == "" {
+		event.ActorType = models.ActorSystem
+	}
+	if detail != nil {
+		if b, err := json.Marshal(detail); err == nil {
+			event.Detail = string(b)
+		}
+	}
+
+	if err := repo.CreateTransactionEvent(event); err != nil {
+		log.Println("transaction event:", err)
+	}
+}
+
+// function recordEmail mencatat email yang dikirim ke pemesan beserta lampirannya, alamat email tidak disimpan
+func recordEmail(repo transactionEventWriter, transaction models.Transaction, subject string, attachments []mail.Attachment, err error) {
+	event := models.TransactionEvent{Type: models.EventEmailSent}
+	filenames := []string{}
+	for _, attachment := range attachments {
+		filenames = append(filenames, attachment.Filename)
+	}
+	detail := map[string]interface{}{"subject": subject, "attachments": filenames}
+	if err != nil {
+		event.Type = models.EventEmailFailed
+		detail["error"] = err.Error()
+	}
+
+	recordEvent(repo, nil, transaction, event, detail)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"project/models"
+	"project/pkg/policy"
+	"project/repositories"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// fakeTransactionRepository menyimpan transaction dan riwayatnya di memory, method lain tidak dipakai oleh riwayat booking
+type fakeTransactionRepository struct {
+	repositories.TransactionRepository
+	transactions map[int]models.Transaction
+	events       []models.TransactionEvent
+}
+
+func (f *fakeTransactionRepository) GetTransaction(id int) (models.Transaction, error) {
+	transaction, ok := f.transactions[id]
+	if !ok {
+		return models.Transaction{}, gorm.ErrRecordNotFound
+	}
+	return transaction, nil
+}
+
+func (f *fakeTransactionRepository) FindTransactionEvents(TransactionId int) ([]models.TransactionEvent, error) {
+	var events []models.TransactionEvent
+	for _, event := range f.events {
+		if event.TransactionId == TransactionId {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func TestGetTransactionHistory(t *testing.T) {
+	repo := &fakeTransactionRepository{
+		transactions: map[int]models.Transaction{1: {Id: 1, UserId: 2, Status: "success"}},
+		events: []models.TransactionEvent{
+			{Id: 1, TransactionId: 1, UserId: 2, Type: models.EventCreated},
+			// booking 5 sudah dihapus, riwayatnya tetap ada
+			{Id: 2, TransactionId: 5, UserId: 2, Type: models.EventCreated},
+			{Id: 3, TransactionId: 5, UserId: 2, Type: models.EventDeleted},
+			// booking 6 dihapus sebelum pemilik disimpan di riwayat
+			{Id: 4, TransactionId: 6, Type: models.EventDeleted},
+		},
+	}
+	h := HandlerTransaction(repo)
+
+	admin := policy.Subject{Id: 1, Role: policy.RoleAdmin}
+	owner := policy.Subject{Id: 2, Role: policy.RoleUser}
+	other := policy.Subject{Id: 3, Role: policy.RoleUser}
+
+	tests := []struct {
+		name       string
+		subject    policy.Subject
+		id         string
+		wantCode   int
+		wantEvents int
+	}{
+		{"owner", owner, "1", http.StatusOK, 1},
+		{"other user", other, "1", http.StatusForbidden, 0},
+		{"owner of deleted booking", owner, "5", http.StatusOK, 2},
+		{"admin reads deleted booking", admin, "5", http.StatusOK, 2},
+		{"other user of deleted booking", other, "5", http.StatusForbidden, 0},
+		{"admin reads deleted booking without owner", admin, "6", http.StatusOK, 1},
+		{"owner cannot read deleted booking without owner", owner, "6", http.StatusForbidden, 0},
+		{"unknown booking", admin, "9", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{"id": float64(tt.subject.Id), "role": tt.subject.Role}
+			req := httptest.NewRequest("GET", "/api/v1/transaction/"+tt.id+"/history", nil)
+			req = mux.SetURLVars(req.WithContext(context.WithValue(req.Context(), "userInfo", claims)), map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+			h.GetTransactionHistory(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var response struct {
+				Data []json.RawMessage `json:"data"`
+			}
+			json.NewDecoder(w.Body).Decode(&response)
+			if len(response.Data) != tt.wantEvents {
+				t.Errorf("events = %d, want %d", len(response.Data), tt.wantEvents)
+			}
+		})
+	}
+}
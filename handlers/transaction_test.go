@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testServerKey = "SB-Mid-server-test"
+	// SHA512("12" + "200" + "150000.00" + testServerKey)
+	testSignature = "92d18cc3b428aa114d2b663dd4485c36bd3e0d03fbfc2ec81da29d7e80be1443e5282b65299bd3d18f75bfe8006c049cfd8b847f1685471c99fcfcb1f978cf5f"
+)
+
+func TestValidSignature(t *testing.T) {
+	payload := func(change func(map[string]interface{})) map[string]interface{} {
+		p := map[string]interface{}{
+			"order_id":      "12",
+			"status_code":   "200",
+			"gross_amount":  "150000.00",
+			"signature_key": testSignature,
+		}
+		change(p)
+		return p
+	}
+
+	tests := []struct {
+		name      string
+		payload   map[string]interface{}
+		serverKey string
+		want      bool
+	}{
+		{"valid", payload(func(p map[string]interface{}) {}), testServerKey, true},
+		{"uppercase hex", payload(func(p map[string]interface{}) { p["signature_key"] = strings.ToUpper(testSignature) }), testServerKey, true},
+		{"other server key", payload(func(p map[string]interface{}) {}), "SB-Mid-server-other", false},
+		{"empty server key", payload(func(p map[string]interface{}) {}), "", false},
+		{"missing signature", payload(func(p map[string]interface{}) { delete(p, "signature_key") }), testServerKey, false},
+		{"tampered order id", payload(func(p map[string]interface{}) { p["order_id"] = "13" }), testServerKey, false},
+		{"tampered status code", payload(func(p map[string]interface{}) { p["status_code"] = "201" }), testServerKey, false},
+		{"tampered gross amount", payload(func(p map[string]interface{}) { p["gross_amount"] = "1.00" }), testServerKey, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature(tt.payload, tt.serverKey); got != tt.want {
+				t.Errorf("validSignature() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrossAmountEquals(t *testing.T) {
+	tests := []struct {
+		gross  string
+		amount int64
+		want   bool
+	}{
+		{"150000.00", 150000, true},
+		{"150000", 150000, true},
+		{"150000.50", 150000, false},
+		{"149999.00", 150000, false},
+		{"", 0, false},
+		{"<nil>", 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := grossAmountEquals(tt.gross, tt.amount); got != tt.want {
+			t.Errorf("grossAmountEquals(%q, %d) = %t, want %t", tt.gross, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestNotificationRejectsInvalidSignature(t *testing.T) {
+	t.Setenv("SERVER_KEY", testServerKey)
+	// repository tidak dipakai karena notifikasi ditolak sebelum mencari transaction
+	h := HandlerTransaction(nil)
+
+	body := `{"order_id":"12","status_code":"200","gross_amount":"150000.00","transaction_status":"settlement","signature_key":"forged"}`
+	w := httptest.NewRecorder()
+	h.Notification(w, httptest.NewRequest("POST", "/api/v1/notification", bytes.NewBufferString(body)))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
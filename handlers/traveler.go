@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/encryption"
+	"project/pkg/policy"
+	"project/repositories"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerTraveler struct {
+	TravelerRepository repositories.TravelerRepository
+}
+
+func HandlerTraveler(TravelerRepository repositories.TravelerRepository) *handlerTraveler {
+	return &handlerTraveler{TravelerRepository}
+}
+
+// function FindTravelers menampilkan traveler tersimpan milik user yang login, nomor dokumen disamarkan
+func (h *handlerTraveler) FindTravelers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	travelers, err := h.TravelerRepository.FindTravelersByUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.TravelerResponse{}
+	for _, t := range travelers {
+		result = append(result, convertResponseTraveler(t, false))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerTraveler) GetTraveler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	traveler, err := h.authorizeTraveler(r)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseTraveler(traveler, true)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerTraveler) CreateTraveler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	request := new(dto.CreateTravelerRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	traveler := models.Traveler{
+		UserId:         userId,
+		FullName:       strings.TrimSpace(request.FullName),
+		Relationship:   request.Relationship,
+		Gender:         request.Gender,
+		Nationality:    request.Nationality,
+		DateOfBirth:    encryption.EncryptedString(request.DateOfBirth),
+		DocumentType:   request.DocumentType,
+		DocumentNumber: encryption.EncryptedString(strings.TrimSpace(request.DocumentNumber)),
+		DocumentExpiry: encryption.EncryptedString(request.DocumentExpiry),
+	}
+
+	traveler, err := h.TravelerRepository.CreateTraveler(traveler)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseTraveler(traveler, true)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerTraveler) UpdateTraveler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdateTravelerRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	traveler, err := h.authorizeTraveler(r)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if request.FullName != "" {
+		traveler.FullName = strings.TrimSpace(request.FullName)
+	}
+	if request.Relationship != "" {
+		traveler.Relationship = request.Relationship
+	}
+	if request.Gender != "" {
+		traveler.Gender = request.Gender
+	}
+	if request.Nationality != "" {
+		traveler.Nationality = request.Nationality
+	}
+	if request.DateOfBirth != "" {
+		traveler.DateOfBirth = encryption.EncryptedString(request.DateOfBirth)
+	}
+	if request.DocumentType != "" {
+		traveler.DocumentType = request.DocumentType
+	}
+	if request.DocumentNumber != "" {
+		traveler.DocumentNumber = encryption.EncryptedString(strings.TrimSpace(request.DocumentNumber))
+	}
+	if request.DocumentExpiry != "" {
+		traveler.DocumentExpiry = encryption.EncryptedString(request.DocumentExpiry)
+	}
+
+	traveler, err = h.TravelerRepository.UpdateTraveler(traveler)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseTraveler(traveler, true)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function DeleteTraveler menghapus profil tersimpan, salinan traveler di booking lama tidak ikut terhapus
+func (h *handlerTraveler) DeleteTraveler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	traveler, err := h.authorizeTraveler(r)
+	if err != nil {
+		policy.Deny(w, err)
+		return
+	}
+
+	if _, err := h.TravelerRepository.DeleteTraveler(traveler); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseTraveler(traveler, false)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function authorizeTraveler mengambil traveler dari parameter {id}. traveler milik user lain dianggap tidak ada
+func (h *handlerTraveler) authorizeTraveler(r *http.Request) (models.Traveler, error) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	traveler, err := h.TravelerRepository.GetTraveler(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return traveler, policy.ErrNotFound
+	}
+	if err != nil {
+		return traveler, err
+	}
+
+	if traveler.UserId != policy.FromRequest(r).Id {
+		return traveler, policy.ErrNotFound
+	}
+	return traveler, nil
+}
+
+func convertResponseTraveler(t models.Traveler, reveal bool) dto.TravelerResponse {
+	documentNumber := string(t.DocumentNumber)
+	if !reveal {
+		documentNumber = maskDocumentNumber(documentNumber)
+	}
+
+	return dto.TravelerResponse{
+		Id:             t.Id,
+		FullName:       t.FullName,
+		Relationship:   t.Relationship,
+		Gender:         t.Gender,
+		Nationality:    t.Nationality,
+		DateOfBirth:    string(t.DateOfBirth),
+		DocumentType:   t.DocumentType,
+		DocumentNumber: documentNumber,
+		DocumentExpiry: string(t.DocumentExpiry),
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func convertTransactionTravelers(travelers []models.TransactionTraveler) []dto.TravelerResponse {
+	result := []dto.TravelerResponse{}
+	for _, t := range travelers {
+		result = append(result, dto.TravelerResponse{
+			Id:             t.TravelerId,
+			FullName:       t.FullName,
+			Gender:         t.Gender,
+			Nationality:    t.Nationality,
+			DateOfBirth:    string(t.DateOfBirth),
+			DocumentType:   t.DocumentType,
+			DocumentNumber: maskDocumentNumber(string(t.DocumentNumber)),
+			DocumentExpiry: string(t.DocumentExpiry),
+		})
+	}
+	return result
+}
+
+// function maskDocumentNumber hanya menampilkan 4 karakter terakhir nomor dokumen
+func maskDocumentNumber(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
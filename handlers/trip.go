@@ -8,8 +8,10 @@ import (
 	"os"
 	dto "project/dto"
 	"project/models"
+	"project/pkg/money"
 	"project/repositories"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
@@ -31,6 +33,15 @@ func HandlerTrip(TripRepository repositories.TripRepository) *handlerTrip {
 func (h *handlerTrip) FindTrips(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json") // Header berfungsi untuk menampilkan data.(text-html /json)
 
+	// mata uang tampilan, misal ?currency=USD. harga asli tetap dalam IDR
+	converter, err := displayConverter(h.TripRepository, r.URL.Query().Get("currency"))
+	if err != nil {
+		w.WriteHeader(currencyErrorStatus(err))
+		response := dto.ErrorResult{Code: currencyErrorStatus(err), Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	// panggil function FindTrip didalam handlerTrip
 	trips, err := h.TripRepository.FindTrips()
 	if err != nil {
@@ -42,6 +53,7 @@ func (h *handlerTrip) FindTrips(w http.ResponseWriter, r *http.Request) {
 	for i, p := range trips {
 		imagePath := os.Getenv("PATH_FILE") + p.Image
 		trips[i].Image = imagePath
+		setDisplayPrice(&trips[i], converter)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -55,6 +67,14 @@ func (h *handlerTrip) GetTrip(w http.ResponseWriter, r *http.Request) {
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
+	converter, err := displayConverter(h.TripRepository, r.URL.Query().Get("currency"))
+	if err != nil {
+		w.WriteHeader(currencyErrorStatus(err))
+		response := dto.ErrorResult{Code: currencyErrorStatus(err), Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	// panggil function GetTrip didalam handlerTrip dengan index tertentu
 	trip, err := h.TripRepository.GetTrip(id)
 	if err != nil {
@@ -66,6 +86,7 @@ func (h *handlerTrip) GetTrip(w http.ResponseWriter, r *http.Request) {
 
 	// jika tidak ada error maka image akan di isi dengan path image
 	trip.Image = os.Getenv("PATH_FILE") + trip.Image
+	setDisplayPrice(&trip, converter)
 
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: trip}
@@ -92,6 +113,8 @@ func (h *handlerTrip) CreateTrip(w http.ResponseWriter, r *http.Request) {
 		Title:          r.FormValue("title"),
 		CountryId:      CountryId,
 		Accomodation:   r.FormValue("accomodation"),
+		Type:           strings.ToLower(strings.TrimSpace(r.FormValue("type"))),
+		GroupKey:       strings.TrimSpace(r.FormValue("group_key")),
 		Transportation: r.FormValue("transportation"),
 		Eat:            r.FormValue("eat"),
 		Day:            day,
@@ -131,17 +154,29 @@ func (h *handlerTrip) CreateTrip(w http.ResponseWriter, r *http.Request) {
 	// parse DateTrip menjadi string
 	dateTrip, _ := time.Parse("2006-01-02", r.FormValue("datetrip"))
 
+	// trip tanpa group key menjadi grup sendiri, keberangkatan lain dari paket yang sama dibuat dengan group key ini
+	if request.GroupKey == "" {
+		if request.GroupKey, err = randomToken(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+	}
+
 	// struct trip di isi dengan request
 	trip := models.Trip{
 		Title:          request.Title,
 		CountryId:      request.CountryId,
 		Accomodation:   request.Accomodation,
+		Type:           request.Type,
+		GroupKey:       request.GroupKey,
 		Transportation: request.Transportation,
 		Eat:            request.Eat,
 		Day:            request.Day,
 		Night:          request.Night,
 		DateTrip:       dateTrip,
-		Price:          request.Price,
+		Price:          money.IDR(int64(request.Price)),
 		Quota:          request.Quota,
 		Description:    request.Description,
 		Image:          resp.SecureURL,
@@ -230,6 +265,16 @@ func (h *handlerTrip) UpdateTrip(w http.ResponseWriter, r *http.Request) {
 		trip.Accomodation = r.FormValue("accomodation")
 	}
 
+	// type
+	if r.FormValue("type") != "" {
+		trip.Type = strings.ToLower(strings.TrimSpace(r.FormValue("type")))
+	}
+
+	// group key
+	if groupKey := strings.TrimSpace(r.FormValue("group_key")); groupKey != "" {
+		trip.GroupKey = groupKey
+	}
+
 	// transportation
 	if r.FormValue("transportation") != "" {
 		trip.Transportation = r.FormValue("transportation")
@@ -262,7 +307,7 @@ func (h *handlerTrip) UpdateTrip(w http.ResponseWriter, r *http.Request) {
 	// parse price
 	price, _ := strconv.Atoi(r.FormValue("price"))
 	if price != 0 {
-		trip.Price = price
+		trip.Price = money.IDR(int64(price))
 	}
 
 	// parse quota
@@ -341,6 +386,15 @@ func (h *handlerTrip) DeleteTrip(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// function setDisplayPrice mengisi harga dalam mata uang tampilan, converter nil berarti tanpa konversi
+func setDisplayPrice(trip *models.Trip, converter *money.Converter) {
+	if converter == nil {
+		return
+	}
+	price := converter.Convert(trip.Price)
+	trip.DisplayPrice = &price
+}
+
 // function convert response trip
 func convertResponseTrip(u models.Trip) dto.TripResponse {
 	return dto.TripResponse{
@@ -348,6 +402,8 @@ func convertResponseTrip(u models.Trip) dto.TripResponse {
 		Title:          u.Title,
 		CountryId:      u.CountryId,
 		Accomodation:   u.Accomodation,
+		Type:           u.Type,
+		GroupKey:       u.GroupKey,
 		Transportation: u.Transportation,
 		Eat:            u.Eat,
 		Day:            u.Day,
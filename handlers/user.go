@@ -3,18 +3,25 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	dto "project/dto"
 	"project/models"
+	"project/pkg/policy"
 	"project/repositories"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 type handlerUser struct {
@@ -28,14 +35,33 @@ func HandlerUser(UserRepository repositories.UserRepository) *handlerUser {
 func (h *handlerUser) FindUsers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	users, err := h.UserRepository.FindUsers()
+	// filter pencarian untuk admin, tanpa query ?page= semua user dikirim seperti sebelumnya
+	page, limit := pagination(r, 0)
+	filter := repositories.UserFilter{
+		Query:  strings.TrimSpace(r.URL.Query().Get("q")),
+		Role:   r.URL.Query().Get("role"),
+		Status: r.URL.Query().Get("status"),
+		Offset: (page - 1) * limit,
+		Limit:  limit,
+	}
+
+	users, total, err := h.UserRepository.FindUsers(filter)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(err.Error())
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// password hash tidak ikut dikirim
+	var usersResponse []dto.UserResponse
+	for _, u := range users {
+		usersResponse = append(usersResponse, convertResponseUser(u))
 	}
 
+	setTotalCount(w, total)
 	w.WriteHeader(http.StatusOK)
-	response := dto.SuccessResult{Code: http.StatusOK, Data: users}
+	response := dto.SuccessResult{Code: http.StatusOK, Data: usersResponse}
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -71,7 +97,10 @@ func (h *handlerUser) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
 	user, err := h.UserRepository.GetUser(int(id))
-
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
@@ -79,6 +108,14 @@ func (h *handlerUser) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// password tidak bisa diganti di sini (dulu tersimpan tanpa hash), gunakan POST /password/change
+	if r.FormValue("password") != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "use POST /password/change to change the password"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	// middleware
 	dataContex := r.Context().Value("dataFile")
 	filepath := dataContex.(string)
@@ -104,14 +141,24 @@ func (h *handlerUser) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		user.Name = r.FormValue("name")
 	}
 
-	// email
-	if r.FormValue("email") != "" {
+	// email, jika diganti maka email baru harus diverifikasi ulang
+	emailChanged := false
+	if r.FormValue("email") != "" && r.FormValue("email") != user.Email {
+		// email tidak boleh sama dengan email user lain
+		if existing, err := h.UserRepository.GetUserByEmail(r.FormValue("email")); err == nil && existing.Id != user.Id {
+			w.WriteHeader(http.StatusConflict)
+			response := dto.ErrorResult{Code: http.StatusConflict, Message: "email already registered"}
+			json.NewEncoder(w).Encode(response)
+			return
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
 		user.Email = r.FormValue("email")
-	}
-
-	// password
-	if r.FormValue("password") != "" {
-		user.Password = r.FormValue("password")
+		user.EmailVerifiedAt = nil
+		emailChanged = true
 	}
 
 	// phone
@@ -138,6 +185,16 @@ func (h *handlerUser) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if emailChanged {
+		if err := h.UserRepository.SetEmailVerified(newUser.Id, nil); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		go sendVerificationEmail(newUser)
+	}
+
 	newUserResponse, err := h.UserRepository.GetUser(newUser.Id)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -157,6 +214,10 @@ func (h *handlerUser) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 	user, err := h.UserRepository.GetUser(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy.Deny(w, policy.ErrNotFound)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
@@ -172,11 +233,248 @@ func (h *handlerUser) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	audit(h.UserRepository, r, "user.deleted", user.Id, map[string]interface{}{"email": user.Email})
+
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseUser(data)}
 	json.NewEncoder(w).Encode(response)
 }
 
+// function targetUser mengambil user dari parameter {id} untuk endpoint admin, response 404 sudah dikirim jika tidak ada
+func (h *handlerUser) targetUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	user, err := h.UserRepository.GetUser(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy.Deny(w, policy.ErrNotFound)
+		return user, false
+	}
+	if err != nil {
+		policy.Deny(w, err)
+		return user, false
+	}
+	return user, true
+}
+
+// function SuspendUser menonaktifkan akun user, semua sesinya langsung dicabut
+func (h *handlerUser) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.SuspendUserRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	// admin tidak boleh mengunci dirinya sendiri
+	if user.Id == policy.FromRequest(r).Id {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "cannot suspend your own account"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.UserRepository.SuspendUser(user.Id, request.Reason); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.UserRepository, r, "user.suspended", user.Id, map[string]interface{}{"reason": request.Reason})
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "user suspended"}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerUser) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.SuspendedAt == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "user is not suspended"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.UserRepository.ReactivateUser(user.Id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.UserRepository, r, "user.reactivated", user.Id, nil)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "user reactivated"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function UpdateRole mengganti role user, user harus login ulang agar token memakai role baru
+func (h *handlerUser) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdateRoleRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.Id == policy.FromRequest(r).Id {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "cannot change your own role"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if user.Role != request.Role {
+		if err := h.UserRepository.UpdateRole(user.Id, request.Role); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		audit(h.UserRepository, r, "user.role_changed", user.Id, map[string]interface{}{"from": user.Role, "to": request.Role})
+		user.Role = request.Role
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseUser(user)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function ForcePasswordReset mencabut semua sesi user dan mengirim link reset password.
+// user tidak bisa login sampai password diganti
+func (h *handlerUser) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	reset := models.PasswordReset{
+		UserId:    user.Id,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if _, err := h.UserRepository.CreatePasswordReset(reset); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.UserRepository.RequirePasswordReset(user.Id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.UserRepository, r, "user.password_reset_forced", user.Id, nil)
+	go sendPasswordResetEmail(user, token)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: "password reset link sent"}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function FindUserTransactions menampilkan semua booking milik user untuk admin
+func (h *handlerUser) FindUserTransactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.UserRepository.FindTransactionsByUser(user.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.UserRepository, r, "user.transactions_viewed", user.Id, nil)
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertMultipleTransactionResponse(transactions)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function FindUserActivity menampilkan audit log user (aksi oleh user maupun terhadap user), terbaru lebih dulu
+func (h *handlerUser) FindUserActivity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	page, limit := pagination(r, 50)
+	logs, total, err := h.UserRepository.FindAuditLogsByUser(user.Id, (page-1)*limit, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	audit(h.UserRepository, r, "user.activity_viewed", user.Id, nil)
+
+	setTotalCount(w, total)
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: logs}
+	json.NewEncoder(w).Encode(response)
+}
+
 func convertResponseUser(u models.User) dto.UserResponse {
 	return dto.UserResponse{
 		Id:      u.Id,
@@ -186,5 +484,22 @@ func convertResponseUser(u models.User) dto.UserResponse {
 		Phone:   u.Phone,
 		Address: u.Address,
 		Image:   u.Image,
+		Role:    u.Role,
+		Status:  userStatus(u),
+	}
+}
+
+// function userStatus menentukan status akun, urutannya sama dengan prioritas tampilan di dashboard admin
+func userStatus(u models.User) string {
+	switch {
+	case u.AnonymizedAt != nil:
+		return repositories.UserStatusDeleted
+	case u.SuspendedAt != nil:
+		return repositories.UserStatusSuspended
+	case u.DeletionScheduledAt != nil:
+		return repositories.UserStatusPendingDeletion
+	case u.EmailVerifiedAt == nil:
+		return repositories.UserStatusUnverified
 	}
+	return repositories.UserStatusActive
 }
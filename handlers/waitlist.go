@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/repositories"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerWaitlist struct {
+	WaitlistRepository repositories.WaitlistRepository
+}
+
+func HandlerWaitlist(WaitlistRepository repositories.WaitlistRepository) *handlerWaitlist {
+	return &handlerWaitlist{WaitlistRepository}
+}
+
+// function JoinWaitlist memasukkan user ke antrean trip yang kursinya tidak cukup.
+// jika user sudah mengantre, jumlah kursi yang diminta diubah tanpa mengubah urutan
+func (h *handlerWaitlist) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	request := new(dto.JoinWaitlistRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	trip, err := h.WaitlistRepository.GetTrip(tripId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if !trip.DateTrip.After(time.Now()) {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "trip has already departed"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	entry, err := h.WaitlistRepository.GetActiveWaitlistEntry(userId, tripId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if entry.Status == models.WaitlistOffered {
+		w.WriteHeader(http.StatusConflict)
+		response := dto.ErrorResult{Code: http.StatusConflict, Message: "you already have a booking offer for this trip"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// waitlist hanya untuk trip yang kursinya tidak cukup, kursi yang ditahan untuk penawaran lain ikut dihitung
+	held, err := h.WaitlistRepository.HeldWaitlistSeats(tripId, userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if entry.Id == 0 && trip.Quota-held >= request.Seats {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "seats are available, book the trip directly"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if entry.Id != 0 {
+		entry.Seats = request.Seats
+		entry, err = h.WaitlistRepository.UpdateWaitlistEntry(entry)
+	} else {
+		entry, err = h.WaitlistRepository.CreateWaitlistEntry(models.WaitlistEntry{
+			UserId: userId,
+			TripId: tripId,
+			Seats:  request.Seats,
+			Status: models.WaitlistWaiting,
+		})
+		if err == nil {
+			entry, err = h.WaitlistRepository.GetActiveWaitlistEntry(userId, tripId)
+		}
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: h.convertResponseWaitlist(entry)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function LeaveWaitlist mengeluarkan user dari antrean. penawaran yang sedang berjalan ikut dibatalkan
+// sehingga kursinya langsung ditawarkan ke antrean berikutnya
+func (h *handlerWaitlist) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	entry, err := h.WaitlistRepository.GetActiveWaitlistEntry(userId, tripId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "you are not on the waitlist for this trip"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	entry.Status = models.WaitlistCancelled
+	entry, err = h.WaitlistRepository.UpdateWaitlistEntry(entry)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: h.convertResponseWaitlist(entry)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function FindWaitlist menampilkan semua antrean milik user yang login
+func (h *handlerWaitlist) FindWaitlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	entries, err := h.WaitlistRepository.FindWaitlistByUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.WaitlistResponse{}
+	for _, entry := range entries {
+		result = append(result, h.convertResponseWaitlist(entry))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function FindTripWaitlist menampilkan antrean aktif sebuah trip untuk admin
+func (h *handlerWaitlist) FindTripWaitlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tripId, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	entries, err := h.WaitlistRepository.FindWaitlistByTrip(tripId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.TripWaitlistResponse{}
+	for _, entry := range entries {
+		result = append(result, dto.TripWaitlistResponse{
+			Id:             entry.Id,
+			UserId:         entry.UserId,
+			Name:           entry.User.Name,
+			Email:          entry.User.Email,
+			Seats:          entry.Seats,
+			Status:         entry.Status,
+			OfferExpiresAt: entry.OfferExpiresAt,
+			CreatedAt:      entry.CreatedAt,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerWaitlist) convertResponseWaitlist(entry models.WaitlistEntry) dto.WaitlistResponse {
+	response := dto.WaitlistResponse{
+		Id:             entry.Id,
+		Trip:           convertRelatedTrip(entry.Trip),
+		Seats:          entry.Seats,
+		Status:         entry.Status,
+		OfferedAt:      entry.OfferedAt,
+		OfferExpiresAt: entry.OfferExpiresAt,
+		TransactionId:  entry.TransactionId,
+		CreatedAt:      entry.CreatedAt,
+	}
+	if entry.Status == models.WaitlistWaiting {
+		response.Position, _ = h.WaitlistRepository.WaitlistPosition(entry)
+	}
+	return response
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+	"project/models"
+	"project/pkg/money"
+	"project/repositories"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerWishlist struct {
+	WishlistRepository repositories.WishlistRepository
+}
+
+func HandlerWishlist(WishlistRepository repositories.WishlistRepository) *handlerWishlist {
+	return &handlerWishlist{WishlistRepository}
+}
+
+// function FindWishlist menampilkan wishlist user yang login dengan harga dan sisa kuota terbaru
+func (h *handlerWishlist) FindWishlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	wishlists, err := h.WishlistRepository.FindWishlistsByUser(userId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	result := []dto.WishlistResponse{}
+	for _, wishlist := range wishlists {
+		result = append(result, convertResponseWishlist(wishlist))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: result}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function AddWishlist menyimpan trip ke wishlist. jika trip sudah ada hanya pilihan notifikasinya yang diubah
+func (h *handlerWishlist) AddWishlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	request := new(dto.CreateWishlistRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	validation := validator.New()
+	if err := validation.Struct(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	trip, err := h.WishlistRepository.GetTrip(request.TripId)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip not found"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	wishlist, err := h.WishlistRepository.GetWishlist(userId, trip.Id)
+	if err == nil {
+		setWishlistNotifications(&wishlist, &request.NotifyPriceDrop, &request.NotifySellOut)
+		wishlist, err = h.WishlistRepository.UpdateWishlist(wishlist)
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		wishlist, err = h.WishlistRepository.CreateWishlist(models.Wishlist{
+			UserId:          userId,
+			TripId:          trip.Id,
+			NotifyPriceDrop: request.NotifyPriceDrop,
+			NotifySellOut:   request.NotifySellOut,
+			PriceAtAdded:    int(trip.Price.Amount),
+			PriceBaseline:   int(trip.Price.Amount),
+		})
+		if err == nil {
+			wishlist, err = h.WishlistRepository.GetWishlist(userId, trip.Id)
+		}
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseWishlist(wishlist)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function UpdateWishlist mengubah pilihan notifikasi untuk trip di wishlist
+func (h *handlerWishlist) UpdateWishlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(dto.UpdateWishlistRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	wishlist, err := h.findWishlist(r)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip is not in your wishlist"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	setWishlistNotifications(&wishlist, request.NotifyPriceDrop, request.NotifySellOut)
+
+	wishlist, err = h.WishlistRepository.UpdateWishlist(wishlist)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseWishlist(wishlist)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerWishlist) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wishlist, err := h.findWishlist(r)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		response := dto.ErrorResult{Code: http.StatusNotFound, Message: "trip is not in your wishlist"}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := h.WishlistRepository.DeleteWishlist(wishlist); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseWishlist(wishlist)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// function findWishlist mengambil wishlist milik user yang login berdasarkan parameter {trip_id}
+func (h *handlerWishlist) findWishlist(r *http.Request) (models.Wishlist, error) {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	tripId, _ := strconv.Atoi(mux.Vars(r)["trip_id"])
+
+	return h.WishlistRepository.GetWishlist(userId, tripId)
+}
+
+// function setWishlistNotifications mengubah pilihan notifikasi. saat notifikasi harga turun baru diaktifkan,
+// harga acuan diisi harga sekarang agar penurunan harga yang lama tidak ikut dikirim
+func setWishlistNotifications(wishlist *models.Wishlist, priceDrop *bool, sellOut *bool) {
+	if priceDrop != nil {
+		if *priceDrop && !wishlist.NotifyPriceDrop {
+			wishlist.PriceBaseline = int(wishlist.Trip.Price.Amount)
+		}
+		wishlist.NotifyPriceDrop = *priceDrop
+	}
+	if sellOut != nil {
+		wishlist.NotifySellOut = *sellOut
+	}
+}
+
+func convertResponseWishlist(wishlist models.Wishlist) dto.WishlistResponse {
+	trip := wishlist.Trip
+	return dto.WishlistResponse{
+		Trip:            convertRelatedTrip(trip),
+		PriceAtAdded:    money.IDR(int64(wishlist.PriceAtAdded)),
+		PriceDropped:    trip.Price.Amount < int64(wishlist.PriceAtAdded),
+		Available:       trip.Quota > 0 && trip.DateTrip.After(time.Now()),
+		NotifyPriceDrop: wishlist.NotifyPriceDrop,
+		NotifySellOut:   wishlist.NotifySellOut,
+		CreatedAt:       wishlist.CreatedAt,
+	}
+}
+
+// function convertRelatedTrip mengubah trip yang dipreload lewat relasi (models.TripResponse) ke dto
+func convertRelatedTrip(trip models.TripResponse) dto.TripResponse {
+	return dto.TripResponse{
+		Id:             trip.Id,
+		Title:          trip.Title,
+		CountryId:      trip.CountryId,
+		Country:        trip.Country,
+		Accomodation:   trip.Accomodation,
+		Type:           trip.Type,
+		Transportation: trip.Transportation,
+		Eat:            trip.Eat,
+		Day:            trip.Day,
+		Night:          trip.Night,
+		DateTrip:       trip.DateTrip.Format("2 January 2006"),
+		Price:          trip.Price,
+		Quota:          trip.Quota,
+		Description:    trip.Description,
+		Image:          trip.Image,
+	}
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"project/models"
+	"project/pkg/bcrypt"
+	"project/pkg/mysql"
+	"project/pkg/vault"
+	"project/repositories"
+	"time"
+)
+
+// function AnonymizeDueAccounts menganonimkan akun yang masa tunggu penghapusannya sudah lewat
+func AnonymizeDueAccounts() {
+	accountRepository := repositories.RepositoryAccount(mysql.DB)
+	store := vault.FromEnv()
+
+	users, err := accountRepository.FindDueDeletions(time.Now())
+	if err != nil {
+		log.Println("account deletion:", err)
+		return
+	}
+
+	for _, user := range users {
+		// password diganti dengan nilai acak agar akun tidak bisa dipakai login lagi
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			log.Println("account deletion:", err)
+			return
+		}
+		password, err := bcrypt.HashingPassword(hex.EncodeToString(b))
+		if err != nil {
+			log.Println("account deletion:", err)
+			return
+		}
+
+		// file dokumen identitas di vault dihapus sebelum datanya dihapus dari database
+		documents, err := accountRepository.FindDocumentsByUser(user.Id)
+		if err != nil {
+			log.Printf("account deletion user %d: %v", user.Id, err)
+			continue
+		}
+		for _, document := range documents {
+			if err := store.Delete(document.StorageKey); err != nil {
+				log.Printf("account deletion user %d: %v", user.Id, err)
+			}
+		}
+
+		if err := accountRepository.AnonymizeUser(user.Id, password); err != nil {
+			log.Printf("account deletion user %d: %v", user.Id, err)
+			continue
+		}
+
+		accountRepository.CreateAuditLog(models.AuditLog{
+			SubjectUserId: user.Id,
+			Action:        "account.anonymized",
+		})
+	}
+}
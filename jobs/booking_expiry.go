@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"log"
+	"project/models"
+	"project/pkg/mysql"
+	"project/repositories"
+	"time"
+)
+
+// notifikasi expire dari midtrans biasanya datang lebih dulu, job ini menangkap booking yang tidak pernah mendapat notifikasi
+const bookingExpiryGrace = 15 * time.Minute
+
+// function ExpireBookings menggagalkan booking yang tidak dibayar sampai batas waktu pembayaran lalu melepas kursinya
+func ExpireBookings() {
+	transactionRepository := repositories.RepositoryTransaction(mysql.DB)
+
+	transactions, err := transactionRepository.FindExpiredBookings(time.Now().Add(-models.BookingPaymentTTL - bookingExpiryGrace))
+	if err != nil {
+		log.Println("booking expiry:", err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		expired, err := transactionRepository.ExpireBooking(transaction)
+		if err != nil {
+			log.Printf("booking expiry %d: %v", transaction.Id, err)
+			continue
+		}
+		if !expired {
+			continue
+		}
+
+		err = transactionRepository.CreateTransactionEvent(models.TransactionEvent{
+			TransactionId: transaction.Id,
+			UserId:        transaction.UserId,
+			Type:          models.EventStatusChanged,
+			FromStatus:    transaction.Status,
+			ToStatus:      "failed",
+			ActorType:     models.ActorSystem,
+			Detail:        `{"reason":"payment_expired"}`,
+		})
+		if err != nil {
+			log.Printf("booking expiry %d: %v", transaction.Id, err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"log"
+	"project/models"
+	"project/pkg/mysql"
+	"project/pkg/vault"
+	"project/repositories"
+	"time"
+)
+
+// function DeleteExpiredDocuments menghapus dokumen vault yang masa simpannya sudah habis
+func DeleteExpiredDocuments() {
+	documentRepository := repositories.RepositoryDocument(mysql.DB)
+	store := vault.FromEnv()
+
+	documents, err := documentRepository.FindExpiredDocuments(time.Now())
+	if err != nil {
+		log.Println("document retention:", err)
+		return
+	}
+
+	for _, document := range documents {
+		if err := store.Delete(document.StorageKey); err != nil {
+			log.Printf("document retention %d: %v", document.Id, err)
+			continue
+		}
+		if err := documentRepository.DeleteDocument(document); err != nil {
+			log.Printf("document retention %d: %v", document.Id, err)
+			continue
+		}
+		documentRepository.CreateDocumentAccessLog(models.DocumentAccessLog{
+			DocumentId: document.Id,
+			Action:     "expired",
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// function every menjalankan job secara berkala di goroutine terpisah. panic di dalam job tidak mematikan server
+func every(name string, interval time.Duration, job func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			run(name, job)
+			<-ticker.C
+		}
+	}()
+}
+
+func run(name string, job func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("job %s: %v", name, err)
+		}
+	}()
+	job()
+}
+
+// function Start menjalankan semua job background, dipanggil sekali dari main
+func Start() {
+	every("account_deletion", time.Hour, AnonymizeDueAccounts)
+	every("document_retention", time.Hour, DeleteExpiredDocuments)
+	every("wishlist_alerts", 15*time.Minute, NotifyWishlists)
+	every("booking_expiry", 5*time.Minute, ExpireBookings)
+	every("reschedule_expiry", 5*time.Minute, ExpireReschedules)
+	every("waitlist_promotion", time.Minute, PromoteWaitlists)
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"encoding/json"
+	"log"
+	"project/handlers"
+	"project/models"
+	"project/pkg/mysql"
+	"project/repositories"
+	"time"
+)
+
+// function ExpireReschedules menggagalkan reschedule yang selisihnya tidak dibayar sampai batas waktu pembayaran, melepas
+// kursi yang ditahan di keberangkatan baru lalu memberi tahu pemesan. booking tetap di keberangkatan lama
+func ExpireReschedules() {
+	transactionRepository := repositories.RepositoryTransaction(mysql.DB)
+
+	reschedules, err := transactionRepository.FindExpiredReschedules(time.Now().Add(-models.ReschedulePaymentTTL - bookingExpiryGrace))
+	if err != nil {
+		log.Println("reschedule expiry:", err)
+		return
+	}
+
+	for _, reschedule := range reschedules {
+		reschedule, failed, err := transactionRepository.FailReschedule(reschedule)
+		if err != nil {
+			log.Printf("reschedule expiry %d: %v", reschedule.Id, err)
+			continue
+		}
+		if !failed {
+			continue
+		}
+
+		detail, _ := json.Marshal(map[string]interface{}{
+			"reschedule_id": reschedule.Id,
+			"from_trip_id":  reschedule.FromTripId,
+			"to_trip_id":    reschedule.ToTripId,
+			"difference":    reschedule.Difference,
+			"reason":        "payment_expired",
+		})
+		err = transactionRepository.CreateTransactionEvent(models.TransactionEvent{
+			TransactionId: reschedule.TransactionId,
+			UserId:        reschedule.UserId,
+			Type:          models.EventRescheduleFailed,
+			ActorType:     models.ActorSystem,
+			Detail:        string(detail),
+		})
+		if err != nil {
+			log.Printf("reschedule expiry %d: %v", reschedule.Id, err)
+		}
+
+		transaction, err := transactionRepository.GetTransaction(reschedule.TransactionId)
+		if err != nil {
+			log.Printf("reschedule expiry %d: %v", reschedule.Id, err)
+			continue
+		}
+		handlers.SendRescheduleEmail(transaction, reschedule)
+	}
+}
@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"project/models"
+	"project/pkg/mail"
+	"project/pkg/mysql"
+	"project/repositories"
+	"strconv"
+	"time"
+)
+
+// function PromoteWaitlists menutup penawaran waitlist yang sudah lewat lalu menawarkan kursi kosong
+// ke antrean berikutnya. kursi kosong bisa berasal dari penawaran yang lewat, booking yang dibatalkan,
+// atau admin yang menambah kuota trip
+func PromoteWaitlists() {
+	waitlistRepository := repositories.RepositoryWaitlist(mysql.DB)
+
+	expired, err := waitlistRepository.ExpireWaitlistOffers(time.Now())
+	if err != nil {
+		log.Println("waitlist:", err)
+		return
+	}
+	for _, entry := range expired {
+		sendWaitlistEmail(entry, "Your booking offer for "+entry.Trip.Title+" has expired", fmt.Sprintf(
+			"Your reserved seats on <b>%s</b> were released because the booking was not completed in time. Join the waitlist again if you are still interested.",
+			html.EscapeString(entry.Trip.Title)))
+	}
+
+	tripIds, err := waitlistRepository.FindWaitlistedTripIds()
+	if err != nil {
+		log.Println("waitlist:", err)
+		return
+	}
+
+	for _, tripId := range tripIds {
+		trip, err := waitlistRepository.GetTrip(tripId)
+		if err != nil || !trip.DateTrip.After(time.Now()) {
+			continue
+		}
+
+		held, err := waitlistRepository.HeldWaitlistSeats(tripId, 0)
+		if err != nil {
+			log.Printf("waitlist trip %d: %v", tripId, err)
+			continue
+		}
+		free := trip.Quota - held
+		if free <= 0 {
+			continue
+		}
+
+		entries, err := waitlistRepository.FindWaitingEntries(tripId)
+		if err != nil {
+			log.Printf("waitlist trip %d: %v", tripId, err)
+			continue
+		}
+
+		// antrean yang meminta kursi lebih banyak dari yang tersedia dilewati, tetapi tetap di urutannya
+		for _, entry := range entries {
+			if entry.Seats > free {
+				continue
+			}
+
+			now := time.Now()
+			expiresAt := now.Add(waitlistOfferTTL())
+			entry.Status = models.WaitlistOffered
+			entry.OfferedAt = &now
+			entry.OfferExpiresAt = &expiresAt
+			if _, err := waitlistRepository.UpdateWaitlistEntry(entry); err != nil {
+				log.Printf("waitlist entry %d: %v", entry.Id, err)
+				continue
+			}
+			free -= entry.Seats
+
+			sendWaitlistEmail(entry, "Seats are available on "+trip.Title, fmt.Sprintf(
+				"%d seat(s) on <b>%s</b> are reserved for you until %s. Log in to dewetour and complete your booking before then, otherwise the seats go to the next person in line.",
+				entry.Seats, html.EscapeString(trip.Title), expiresAt.Format("2 January 2006 15:04 MST")))
+		}
+	}
+}
+
+func sendWaitlistEmail(entry models.WaitlistEntry, subject string, body string) {
+	err := mail.Send(mail.Message{
+		To:      entry.User.Email,
+		Subject: subject,
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Hi %s,</h2>
+      <p>%s</p>
+      </body>
+    </html>`, html.EscapeString(entry.User.Name), body),
+	})
+	if err != nil {
+		log.Printf("waitlist entry %d: %v", entry.Id, err)
+	}
+}
+
+// function waitlistOfferTTL mengambil lama penawaran dari env WAITLIST_OFFER_HOURS (default 24 jam)
+func waitlistOfferTTL() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("WAITLIST_OFFER_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"project/models"
+	"project/pkg/mail"
+	"project/pkg/money"
+	"project/pkg/mysql"
+	"project/repositories"
+	"strconv"
+	"time"
+)
+
+// function NotifyWishlists mengirim email ke user yang meminta notifikasi saat trip di wishlistnya turun harga
+// atau kuotanya hampir habis. setiap kejadian hanya dikirim sekali
+func NotifyWishlists() {
+	wishlistRepository := repositories.RepositoryWishlist(mysql.DB)
+	threshold := sellOutThreshold()
+
+	wishlists, err := wishlistRepository.FindWishlistAlerts()
+	if err != nil {
+		log.Println("wishlist alerts:", err)
+		return
+	}
+
+	for _, wishlist := range wishlists {
+		trip := wishlist.Trip
+		price := int(trip.Price.Amount)
+		if !trip.DateTrip.After(time.Now()) {
+			continue
+		}
+
+		// harga acuan selalu mengikuti harga terbaru, notifikasi hanya dikirim jika harga turun
+		if price != wishlist.PriceBaseline {
+			if wishlist.NotifyPriceDrop && price < wishlist.PriceBaseline {
+				sendWishlistEmail(wishlist, "Price drop on "+trip.Title, fmt.Sprintf(
+					"The price of <b>%s</b> dropped from %s to %s.", html.EscapeString(trip.Title), money.IDR(int64(wishlist.PriceBaseline)), trip.Price))
+			}
+			if err := wishlistRepository.SetWishlistPriceBaseline(wishlist.Id, price); err != nil {
+				log.Printf("wishlist alerts %d: %v", wishlist.Id, err)
+			}
+		}
+
+		// notifikasi hampir habis bisa dikirim lagi jika kuota sempat bertambah di atas batas
+		switch {
+		case trip.Quota > threshold && wishlist.SellOutNotifiedAt != nil:
+			if err := wishlistRepository.SetWishlistSellOutNotified(wishlist.Id, nil); err != nil {
+				log.Printf("wishlist alerts %d: %v", wishlist.Id, err)
+			}
+		case wishlist.NotifySellOut && trip.Quota > 0 && trip.Quota <= threshold && wishlist.SellOutNotifiedAt == nil:
+			sendWishlistEmail(wishlist, trip.Title+" is almost sold out", fmt.Sprintf(
+				"Only %d seats are left on <b>%s</b>.", trip.Quota, html.EscapeString(trip.Title)))
+			now := time.Now()
+			if err := wishlistRepository.SetWishlistSellOutNotified(wishlist.Id, &now); err != nil {
+				log.Printf("wishlist alerts %d: %v", wishlist.Id, err)
+			}
+		}
+	}
+}
+
+func sendWishlistEmail(wishlist models.Wishlist, subject string, body string) {
+	err := mail.Send(mail.Message{
+		To:      wishlist.User.Email,
+		Subject: subject,
+		HTML: fmt.Sprintf(`<!DOCTYPE html>
+    <html lang="en">
+      <body>
+      <h2>Hi %s,</h2>
+      <p>%s</p>
+      <p>You are receiving this because the trip is on your dewetour wishlist. You can turn these notifications off from your wishlist.</p>
+      </body>
+    </html>`, html.EscapeString(wishlist.User.Name), body),
+	})
+	if err != nil {
+		log.Printf("wishlist alerts %d: %v", wishlist.Id, err)
+	}
+}
+
+// function sellOutThreshold mengambil batas sisa kuota "hampir habis" dari env WISHLIST_SELL_OUT_THRESHOLD (default 5)
+func sellOutThreshold() int {
+	threshold, err := strconv.Atoi(os.Getenv("WISHLIST_SELL_OUT_THRESHOLD"))
+	if err != nil || threshold <= 0 {
+		threshold = 5
+	}
+	return threshold
+}
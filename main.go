@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"project/database"
+	"project/jobs"
 	"project/pkg/mysql"
 	"project/routes"
 
@@ -30,6 +31,9 @@ func main() {
 	// run migration
 	database.RunMigration()
 
+	// job background (misal anonimisasi akun yang sudah lewat masa tunggu)
+	jobs.Start()
+
 	// route untuk menginisialisasi folder dengan file, image css, js agar dapat diakses kedalam project
 	route.PathPrefix("/uploads").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
 
@@ -40,11 +44,12 @@ func main() {
 	var AllowedHeaders = handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
 	var AllowedOrigins = handlers.AllowedOrigins([]string{"*"})
 	var AllowedMethods = handlers.AllowedMethods([]string{"HEAD", "OPTIONS", "GET", "POST", "PUT", "PATCH", "DELETE"})
+	var ExposedHeaders = handlers.ExposedHeaders([]string{"X-Total-Count"})
 
 	var PORT = os.Getenv("PORT")
 
 	fmt.Println("server running localhost:5000")
-	http.ListenAndServe(":"+PORT, handlers.CORS(AllowedHeaders, AllowedOrigins, AllowedMethods)(route))
+	http.ListenAndServe(":"+PORT, handlers.CORS(AllowedHeaders, AllowedOrigins, AllowedMethods, ExposedHeaders)(route))
 }
 
 // lifecycle: models ---> koneksi mysql ---> database migration ---> repositories ---> dto ---> handlers ---> routers
@@ -60,6 +65,21 @@ func main() {
 // CLIENT_KEY=your_midtrans_client_key
 // EMAIL_SYSTEM=email_here...
 // PASSWORD_SYSTEM=password_app...
+// BASE_URL=http://localhost:5000 (dipakai untuk link verifikasi email)
+// SMTP_HOST=smtp.gmail.com, SMTP_PORT=587, SENDER_NAME=dewetour <email_here...> (opsional)
+// FRONTEND_URL=http://localhost:3000 (dipakai untuk link reset password)
+// MFA_REQUIRED_ROLES=admin (role yang wajib 2FA, pisahkan dengan koma, kosongkan jika tidak ada)
+// LOGIN_GUARD_STORE=memory (atau database jika server lebih dari satu)
+// OIDC_GOOGLE_ISSUER=https://accounts.google.com, OIDC_GOOGLE_CLIENT_ID=..., OIDC_GOOGLE_CLIENT_SECRET=...,
+// OIDC_GOOGLE_REDIRECT_URL=http://localhost:5000/api/v1/oidc/google/callback (nama provider bebas, misal OIDC_KEYCLOAK_*)
+// ACCOUNT_DELETION_GRACE_DAYS=14 (masa tunggu sebelum akun yang dihapus user dianonimkan)
+// IMPERSONATION_TTL_MINUTES=30 (batas lama token impersonation admin)
+// BCRYPT_COST=10, PASSWORD_MIN_LENGTH=8, PASSWORD_REQUIRE_UPPER/LOWER/DIGIT/SYMBOL=false,
+// PASSWORD_BREACHED_FILE=path daftar password bocor tambahan (satu per baris, opsional)
+// ENCRYPTION_MASTER_KEY=... (32 byte base64, buat dengan `openssl rand -base64 32`) untuk data identitas traveler dan dokumen
+// DOCUMENT_STORAGE_DIR=./storage/documents, DOCUMENT_URL_TTL_SECONDS=300, DOCUMENT_RETENTION_DAYS=365
+// WAITLIST_OFFER_HOURS=24 (lama penawaran booking untuk antrean waitlist sebelum dialihkan ke antrean berikutnya)
+// WISHLIST_SELL_OUT_THRESHOLD=5 (sisa kuota trip yang dianggap hampir habis untuk notifikasi wishlist)
 
 // SNAP adalah portal pembayaran yang memungkinkan merchant menampilkan halaman pembayaran Midtrans langsung di website. Permintaan API harus dilakukan dari backend merchant untuk mendapatkan token transaksi Snap dengan memberikan informasi pembayaran dan Server Key. Setidaknya ada tiga komponen yang diperlukan untuk mendapatkan token Snap
 
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// produk tambahan yang bisa dipilih saat booking (asuransi, jemputan bandara, kamar single, dsb).
+// harga per orang jika PerPerson true, jika tidak harga per booking. Stock 0 berarti tidak dibatasi
+type AddOn struct {
+	Id          int       `json:"id" gorm:"primary_key:auto_increment"`
+	TripId      int       `json:"trip_id" gorm:"index"`
+	Name        string    `json:"name" gorm:"type: varchar(100)"`
+	Description string    `json:"description" gorm:"type: varchar(255)"`
+	Price       int       `json:"price" gorm:"type: int"`
+	PerPerson   bool      `json:"per_person" gorm:"default:false"`
+	Stock       int       `json:"stock" gorm:"type: int;default:0"`
+	Sold        int       `json:"sold" gorm:"type: int;default:0"`
+	Active      bool      `json:"active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// api key pribadi untuk integrasi partner, yang disimpan hanya prefix dan hash dari key
+type APIKey struct {
+	Id         int        `json:"id" gorm:"primary_key:auto_increment"`
+	UserId     int        `json:"user_id" gorm:"index"`
+	Name       string     `json:"name" gorm:"type: varchar(255)"`
+	Prefix     string     `json:"prefix" gorm:"type: varchar(32);uniqueIndex"`
+	KeyHash    string     `json:"-" gorm:"type: varchar(64)"`
+	Scopes     string     `json:"scopes" gorm:"type: varchar(255)"` // dipisahkan koma
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" gorm:"type: varchar(64)"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
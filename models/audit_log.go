@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// catatan audit untuk aksi penting (export data, hapus akun, aksi admin). tidak pernah diubah atau dihapus
+type AuditLog struct {
+	Id int `json:"id" gorm:"primary_key:auto_increment"`
+	// user yang melakukan aksi (0 untuk sistem)
+	ActorId int `json:"actor_id" gorm:"index"`
+	// admin yang sedang impersonate ActorId (0 jika bukan impersonation)
+	ImpersonatorId int `json:"impersonator_id" gorm:"index"`
+	// user yang terkena aksi
+	SubjectUserId int       `json:"subject_user_id" gorm:"index"`
+	Action        string    `json:"action" gorm:"type: varchar(100);index"`
+	Detail        string    `json:"detail" gorm:"type: text"`
+	IP            string    `json:"ip" gorm:"type: varchar(64)"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// sumber check-in: langsung dipindai guide atau disinkronkan belakangan dari hp guide yang offline
+const (
+	CheckInOnline  = "online"
+	CheckInOffline = "offline"
+)
+
+// penumpang yang sudah check-in pada hari keberangkatan. satu kursi (transaction + seat) hanya bisa check-in sekali,
+// dijaga oleh unique index sehingga dua guide yang memindai tiket yang sama tidak membuat dua data
+type CheckIn struct {
+	Id            int       `json:"id" gorm:"primary_key:auto_increment"`
+	TransactionId int       `json:"transaction_id" gorm:"uniqueIndex:idx_check_in_seat"`
+	Seat          int       `json:"seat" gorm:"uniqueIndex:idx_check_in_seat"`
+	TripId        int       `json:"trip_id" gorm:"index"`
+	Passenger     string    `json:"passenger" gorm:"type: varchar(255)"`
+	GuideId       int       `json:"guide_id"`
+	Source        string    `json:"source" gorm:"type: varchar(16)"`
+	DeviceId      string    `json:"device_id" gorm:"type: varchar(64)"`
+	ScannedAt     time.Time `json:"scanned_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// dokumen identitas (scan paspor, visa) yang disimpan terenkripsi di vault
+type Document struct {
+	Id          int    `json:"id" gorm:"primary_key:auto_increment"`
+	UserId      int    `json:"user_id" gorm:"index"`
+	TravelerId  int    `json:"traveler_id" gorm:"default:0"`
+	Kind        string `json:"kind" gorm:"type: varchar(32)"`
+	FileName    string `json:"file_name" gorm:"type: varchar(255)"`
+	ContentType string `json:"content_type" gorm:"type: varchar(100)"`
+	Size        int64  `json:"size"`
+	// nama file di vault dan data key yang dienkripsi dengan master key
+	StorageKey string    `json:"-" gorm:"type: varchar(64);uniqueIndex"`
+	WrappedKey []byte    `json:"-" gorm:"type: varbinary(255)"`
+	CreatedAt  time.Time `json:"created_at"`
+	// dokumen dihapus otomatis setelah masa simpan habis
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+}
+
+// catatan setiap akses ke dokumen (upload, pembuatan link, download, hapus)
+type DocumentAccessLog struct {
+	Id             int       `json:"id" gorm:"primary_key:auto_increment"`
+	DocumentId     int       `json:"document_id" gorm:"index"`
+	UserId         int       `json:"user_id"`
+	ImpersonatorId int       `json:"impersonator_id"`
+	Action         string    `json:"action" gorm:"type: varchar(32)"`
+	IP             string    `json:"ip" gorm:"type: varchar(64)"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// kurs yang diisi manual oleh admin (tidak mengambil dari layanan luar). Rate adalah harga 1 unit Currency dalam IDR,
+// dipakai untuk menampilkan harga dalam mata uang lain. pembayaran tetap dalam IDR
+type ExchangeRate struct {
+	Id          int       `json:"id" gorm:"primary_key:auto_increment"`
+	Currency    string    `json:"currency" gorm:"type: varchar(3);uniqueIndex"`
+	Rate        string    `json:"rate" gorm:"type: decimal(20,8)"`
+	UpdatedById int       `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
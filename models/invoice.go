@@ -0,0 +1,45 @@
+package models
+
+import (
+	"project/pkg/money"
+	"time"
+)
+
+// jenis invoice. adjustment menagih selisih harga reschedule yang lebih mahal, credit note mencatat selisih yang dikembalikan
+const (
+	InvoiceStandard   = "invoice"
+	InvoiceAdjustment = "adjustment"
+	InvoiceCreditNote = "credit_note"
+)
+
+// invoice dibuat sekali saat transaction berhasil dibayar, ditambah satu adjustment atau credit note untuk setiap reschedule
+// dengan selisih harga. nomornya berurutan per tahun tanpa lompatan (INV/2026/000001) dan rinciannya disalin dari
+// transaction agar tidak berubah jika transaction diubah
+type Invoice struct {
+	Id            int    `json:"id" gorm:"primary_key:auto_increment"`
+	Number        string `json:"number" gorm:"type: varchar(32);uniqueIndex"`
+	Year          int    `json:"year" gorm:"uniqueIndex:idx_invoice_sequence"`
+	Sequence      int    `json:"sequence" gorm:"uniqueIndex:idx_invoice_sequence"`
+	Kind          string `json:"kind" gorm:"type: varchar(16)"`
+	TransactionId int    `json:"transaction_id" gorm:"uniqueIndex:idx_invoice_document"`
+	// 0 untuk invoice booking, selain itu reschedule yang menerbitkan adjustment atau credit note
+	RescheduleId int `json:"reschedule_id" gorm:"uniqueIndex:idx_invoice_document"`
+	// nomor invoice booking yang dikoreksi oleh adjustment atau credit note
+	ReferenceNumber string      `json:"reference_number" gorm:"type: varchar(32)"`
+	BilledName      string      `json:"billed_name" gorm:"type: varchar(255)"`
+	BilledEmail     string      `json:"billed_email" gorm:"type: varchar(255)"`
+	Subtotal        money.Money `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_"`
+	Discount        money.Money `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	Fees            money.Money `json:"fees" gorm:"embedded;embeddedPrefix:fees_"`
+	Tax             money.Money `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	Total           money.Money `json:"total" gorm:"embedded;embeddedPrefix:total_"`
+	IssuedAt        time.Time   `json:"issued_at"`
+	// salinan rincian harga (json []TransactionItem) saat invoice dibuat, karena reschedule mengganti Transaction.Items
+	Items string `json:"-" gorm:"type: text"`
+}
+
+// nomor invoice terakhir per tahun. baris dikunci saat membuat invoice agar dua invoice tidak mendapat nomor yang sama
+type InvoiceSequence struct {
+	Year       int `json:"year" gorm:"primaryKey;autoIncrement:false"`
+	LastNumber int `json:"last_number"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// catatan gagal login per akun atau ip, dipakai pkg/loginguard jika memakai penyimpanan database
+type LoginAttempt struct {
+	Id          int        `json:"id" gorm:"primary_key:auto_increment"`
+	Key         string     `json:"key" gorm:"type: varchar(255);uniqueIndex"`
+	Failures    int        `json:"failures" gorm:"type: int"`
+	LastFailure time.Time  `json:"last_failure"`
+	LockedUntil *time.Time `json:"locked_until"`
+}
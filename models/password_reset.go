@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// token reset password, yang disimpan hanya hash sha256 dari token yang dikirim ke email
+type PasswordReset struct {
+	Id        int        `json:"id" gorm:"primary_key:auto_increment"`
+	UserId    int        `json:"user_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"type: varchar(64);uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
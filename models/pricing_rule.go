@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// jenis aturan harga trip
+const (
+	RulePassengerType = "passenger_type"
+	RuleEarlyBird     = "early_bird"
+	RuleQuantityTier  = "quantity_tier"
+	RulePeakSurcharge = "peak_surcharge"
+)
+
+// jenis penumpang, penumpang tanpa aturan passenger_type membayar Trip.Price
+const (
+	PassengerAdult  = "adult"
+	PassengerChild  = "child"
+	PassengerSenior = "senior"
+)
+
+// aturan harga yang melekat pada trip.
+//   - passenger_type: harga per orang (Amount) untuk PassengerType
+//   - early_bird: potongan jika booking dibuat antara StartsAt dan EndsAt
+//   - quantity_tier: potongan jika jumlah penumpang minimal MinQuantity
+//   - peak_surcharge: tambahan harga jika tanggal keberangkatan antara StartsAt dan EndsAt
+//
+// potongan dan tambahan memakai Percent dari harga tiket, atau Amount per orang jika Percent 0
+type PricingRule struct {
+	Id            int        `json:"id" gorm:"primary_key:auto_increment"`
+	TripId        int        `json:"trip_id" gorm:"index"`
+	Kind          string     `json:"kind" gorm:"type: varchar(32)"`
+	Description   string     `json:"description" gorm:"type: varchar(255)"`
+	PassengerType string     `json:"passenger_type" gorm:"type: varchar(16)"`
+	Percent       int        `json:"percent" gorm:"type: int"`
+	Amount        int        `json:"amount" gorm:"type: int"`
+	MinQuantity   int        `json:"min_quantity" gorm:"type: int"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
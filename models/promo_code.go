@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// jenis potongan promo code
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+)
+
+// promo code yang dibuat admin untuk kampanye. nilai 0 pada batas (MinSpend, UsageLimit, PerUserLimit, MaxDiscount)
+// berarti tidak dibatasi. TripIds dan CountryIds berisi daftar id dipisah koma, kosong berarti berlaku untuk semua
+type PromoCode struct {
+	Id           int        `json:"id" gorm:"primary_key:auto_increment"`
+	Code         string     `json:"code" gorm:"type: varchar(32);uniqueIndex"`
+	Description  string     `json:"description" gorm:"type: varchar(255)"`
+	DiscountType string     `json:"discount_type" gorm:"type: varchar(16)"`
+	Value        int        `json:"value" gorm:"type: int"`
+	MaxDiscount  int        `json:"max_discount" gorm:"type: int"`
+	MinSpend     int        `json:"min_spend" gorm:"type: int"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   int        `json:"usage_limit" gorm:"type: int"`
+	PerUserLimit int        `json:"per_user_limit" gorm:"type: int"`
+	UsedCount    int        `json:"used_count" gorm:"type: int;default:0"`
+	TripIds      string     `json:"trip_ids" gorm:"type: varchar(1024)"`
+	CountryIds   string     `json:"country_ids" gorm:"type: varchar(1024)"`
+	Active       bool       `json:"active" gorm:"default:true"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// kode cadangan 2FA yang bisa dipakai sekali jika user kehilangan aplikasi authenticator
+type RecoveryCode struct {
+	Id        int        `json:"id" gorm:"primary_key:auto_increment"`
+	UserId    int        `json:"user_id" gorm:"index"`
+	CodeHash  string     `json:"-" gorm:"type: varchar(64);index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"project/pkg/money"
+	"time"
+)
+
+// status reschedule. pending berarti selisih harga belum dibayar, kursi di keberangkatan baru sudah ditahan
+const (
+	ReschedulePending   = "pending"
+	RescheduleCompleted = "completed"
+	RescheduleFailed    = "failed"
+)
+
+// batas waktu pembayaran selisih harga reschedule, setelah itu reschedule gagal dan kursi di keberangkatan baru dilepas
+const ReschedulePaymentTTL = 2 * time.Hour
+
+// status pengembalian selisih harga. manual berarti refund lewat payment gateway gagal dan harus diproses admin
+const (
+	RefundNone     = ""
+	RefundRefunded = "refunded"
+	RefundManual   = "manual"
+)
+
+// pemindahan booking yang sudah dibayar ke keberangkatan lain dari trip yang sama. harga baru dihitung ulang dan disimpan
+// di sini sampai selisihnya dibayar, Difference positif ditagih lewat midtrans dan negatif dikembalikan ke user
+type Reschedule struct {
+	Id            int         `json:"id" gorm:"primary_key:auto_increment"`
+	TransactionId int         `json:"transaction_id" gorm:"index"`
+	UserId        int         `json:"-" gorm:"index"`
+	RequestedById int         `json:"-"`
+	FromTripId    int         `json:"from_trip_id"`
+	ToTripId      int         `json:"to_trip_id"`
+	Seats         int         `json:"seats" gorm:"type: int"`
+	OldTotal      money.Money `json:"old_total" gorm:"embedded;embeddedPrefix:old_total_"`
+	Subtotal      money.Money `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_"`
+	Discount      money.Money `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	Fees          money.Money `json:"fees" gorm:"embedded;embeddedPrefix:fees_"`
+	Tax           money.Money `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	Total         money.Money `json:"total" gorm:"embedded;embeddedPrefix:total_"`
+	Difference    money.Money `json:"difference" gorm:"embedded;embeddedPrefix:difference_"`
+	// rincian harga baru (json []TransactionItem), menggantikan Transaction.Items saat reschedule selesai
+	Items        string     `json:"-" gorm:"type: text"`
+	Reason       string     `json:"reason" gorm:"type: varchar(255)"`
+	Status       string     `json:"status" gorm:"type: varchar(16);index"`
+	Token        string     `json:"token" gorm:"type: varchar(255)"`
+	RefundStatus string     `json:"refund_status" gorm:"type: varchar(16)"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// sesi login user, setiap token login membawa TokenId sesi ini di claim "sid"
+type Session struct {
+	Id         int        `json:"id" gorm:"primary_key:auto_increment"`
+	UserId     int        `json:"-" gorm:"index"`
+	TokenId    string     `json:"-" gorm:"type: varchar(64);uniqueIndex"`
+	Device     string     `json:"device" gorm:"type: varchar(255)"`
+	IP         string     `json:"ip" gorm:"type: varchar(64)"`
+	UserAgent  string     `json:"user_agent" gorm:"type: varchar(512)"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// admin yang membuat sesi ini lewat impersonation (0 untuk login biasa)
+	ImpersonatorId int `json:"impersonator_id" gorm:"default:0"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// jenis biaya tambahan di luar harga trip
+const (
+	ChargeServiceFee = "service_fee"
+	ChargeTax        = "tax"
+)
+
+// aturan pajak (misal PPN) dan biaya layanan platform yang diatur admin. TripType kosong dan CountryId 0
+// berarti berlaku untuk semua trip. RateBps adalah persentase dalam basis point (1100 = 11%), FixedAmount
+// adalah biaya tetap per booking atau per orang jika PerPerson. keduanya boleh diisi bersamaan
+type TaxRule struct {
+	Id          int       `json:"id" gorm:"primary_key:auto_increment"`
+	Name        string    `json:"name" gorm:"type: varchar(100)"`
+	Kind        string    `json:"kind" gorm:"type: varchar(16)"`
+	TripType    string    `json:"trip_type" gorm:"type: varchar(32)"`
+	CountryId   int       `json:"country_id" gorm:"index"`
+	RateBps     int       `json:"rate_bps" gorm:"type: int"`
+	FixedAmount int       `json:"fixed_amount" gorm:"type: int"`
+	PerPerson   bool      `json:"per_person"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -1,11 +1,17 @@
 package models
 
-import "time"
+import (
+	"project/pkg/money"
+	"time"
+)
+
+// batas waktu pembayaran booking sejak dibuat, setelah itu booking gagal dan kursinya dilepas
+const BookingPaymentTTL = 24 * time.Hour
 
 type Transaction struct {
 	Id          int          `json:"id" gorm:"primary_key:auto_increment"`
 	CounterQty  int          `json:"counter_qty" gorm:"type: int"`
-	Total       int          `json:"total" gorm:"type: int"`
+	Total       money.Money  `json:"total" gorm:"embedded;embeddedPrefix:total_"`
 	BookingDate time.Time    `json:"booking_date"`
 	Status      string       `json:"status" form:"status" gorm:"type: varchar(255)"`
 	Token       string       `json:"token" gorm:"type: varchar(255)"`
@@ -14,12 +20,26 @@ type Transaction struct {
 	UserId      int          `json:"-"`
 	Trip        TripResponse `json:"trip"`
 	User        UserResponse `json:"user"`
+	// rincian total yang disimpan saat booking (Total = Subtotal - Discount + Fees + Tax), baris lengkapnya ada di Items
+	Subtotal    money.Money `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_"`
+	Discount    money.Money `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	Fees        money.Money `json:"fees" gorm:"embedded;embeddedPrefix:fees_"`
+	Tax         money.Money `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	PromoCodeId int         `json:"-" gorm:"index"`
+	PromoCode   string      `json:"promo_code" gorm:"type: varchar(32)"`
+	// traveler yang dipilih saat booking, dikirim lewat dto agar nomor dokumen bisa disamarkan
+	Travelers []TransactionTraveler `json:"-" gorm:"foreignKey:TransactionId"`
+	Items     []TransactionItem     `json:"items" gorm:"foreignKey:TransactionId"`
+	// kursi booking sedang ditahan dari kuota trip, dilepas saat booking gagal, kadaluarsa atau dihapus
+	SeatsReserved bool `json:"-"`
+	// manual jika pembayaran masuk setelah booking gagal dan kursinya sudah habis, admin harus mengembalikan dananya
+	RefundStatus string `json:"refund_status" gorm:"type: varchar(16)"`
 }
 
 type TransactionResponse struct {
 	Id          int          `json:"id"`
 	CounterQty  int          `json:"counter_qty" gorm:"type: int"`
-	Total       int          `json:"total" gorm:"type: int"`
+	Total       money.Money  `json:"total" gorm:"embedded;embeddedPrefix:total_"`
 	BookingDate time.Time    `json:"booking_date"`
 	Status      string       `json:"status" gorm:"type: varchar(255)"`
 	Token       string       `json:"token" gorm:"type: varchar(255)"`
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// jenis event riwayat booking
+const (
+	EventCreated              = "created"
+	EventStatusChanged        = "status_changed"
+	EventTokenIssued          = "token_issued"
+	EventTokenRegenerated     = "token_regenerated"
+	EventNotificationReceived = "notification_received"
+	EventEmailSent            = "email_sent"
+	EventEmailFailed          = "email_failed"
+	EventRescheduleRequested  = "reschedule_requested"
+	EventRescheduled          = "rescheduled"
+	EventRescheduleFailed     = "reschedule_failed"
+	EventRefundRequired       = "refund_required"
+	EventDeleted              = "deleted"
+)
+
+// siapa yang menyebabkan event
+const (
+	ActorUser    = "user"
+	ActorAdmin   = "admin"
+	ActorGateway = "midtrans"
+	ActorSystem  = "system"
+)
+
+// riwayat booking. hanya ditambah, tidak pernah diubah atau dihapus (juga saat transaction dihapus)
+type TransactionEvent struct {
+	Id            int `json:"id" gorm:"primary_key:auto_increment"`
+	TransactionId int `json:"transaction_id" gorm:"index"`
+	// pemilik booking, disimpan agar pemilik masih bisa membaca riwayat setelah transaction dihapus
+	UserId int    `json:"-" gorm:"index"`
+	Type   string `json:"type" gorm:"type: varchar(32);index"`
+	// status transaction sebelum dan sesudah event, kosong jika status tidak berubah
+	FromStatus string `json:"from_status" gorm:"type: varchar(32)"`
+	ToStatus   string `json:"to_status" gorm:"type: varchar(32)"`
+	// user yang melakukan aksi (0 untuk midtrans atau sistem)
+	ActorId int `json:"actor_id"`
+	// admin yang sedang impersonate ActorId (0 jika bukan impersonation)
+	ImpersonatorId int       `json:"impersonator_id"`
+	ActorType      string    `json:"actor_type" gorm:"type: varchar(16)"`
+	Detail         string    `json:"detail" gorm:"type: text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
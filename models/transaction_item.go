@@ -0,0 +1,28 @@
+package models
+
+// jenis baris rincian harga transaction
+const (
+	ItemFare      = "fare"
+	ItemSurcharge = "surcharge"
+	ItemEarlyBird = "early_bird"
+	ItemGroup     = "group_discount"
+	ItemAddOn     = "add_on"
+	ItemDiscount  = "discount"
+	ItemFee       = "service_fee"
+	ItemTax       = "tax"
+)
+
+// rincian harga yang dihitung server saat booking dibuat. potongan disimpan dengan Amount negatif
+// sehingga jumlah semua Amount sama dengan Transaction.Total. semua nilai dalam IDR dan Amount selalu sama dengan UnitAmount x Quantity
+type TransactionItem struct {
+	Id            int    `json:"id" gorm:"primary_key:auto_increment"`
+	TransactionId int    `json:"-" gorm:"index"`
+	Kind          string `json:"kind" gorm:"type: varchar(16)"`
+	Code          string `json:"code" gorm:"type: varchar(64)"`
+	Description   string `json:"description" gorm:"type: varchar(255)"`
+	Quantity      int    `json:"quantity" gorm:"type: int"`
+	UnitAmount    int    `json:"unit_amount" gorm:"type: int"`
+	Amount        int    `json:"amount" gorm:"type: int"`
+	// diisi untuk baris add_on, dipakai untuk mengembalikan stok jika booking gagal
+	AddOnId int `json:"add_on_id,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"project/pkg/encryption"
+	"time"
+)
+
+// profil traveler (pasangan, anak, dsb) yang disimpan user agar tidak perlu diisi ulang setiap booking.
+// data identitas dienkripsi di database
+type Traveler struct {
+	Id             int                        `json:"id" gorm:"primary_key:auto_increment"`
+	UserId         int                        `json:"-" gorm:"index"`
+	FullName       string                     `json:"full_name" gorm:"type: varchar(255)"`
+	Relationship   string                     `json:"relationship" gorm:"type: varchar(32)"`
+	Gender         string                     `json:"gender" gorm:"type: varchar(32)"`
+	Nationality    string                     `json:"nationality" gorm:"type: varchar(64)"`
+	DateOfBirth    encryption.EncryptedString `json:"date_of_birth"`
+	DocumentType   string                     `json:"document_type" gorm:"type: varchar(32)"`
+	DocumentNumber encryption.EncryptedString `json:"document_number"`
+	DocumentExpiry encryption.EncryptedString `json:"document_expiry"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	UpdatedAt      time.Time                  `json:"updated_at"`
+}
+
+// salinan data traveler saat booking dibuat, perubahan profil setelahnya tidak mengubah data booking
+type TransactionTraveler struct {
+	Id             int                        `json:"id" gorm:"primary_key:auto_increment"`
+	TransactionId  int                        `json:"-" gorm:"index"`
+	TravelerId     int                        `json:"traveler_id"`
+	FullName       string                     `json:"full_name" gorm:"type: varchar(255)"`
+	Gender         string                     `json:"gender" gorm:"type: varchar(32)"`
+	Nationality    string                     `json:"nationality" gorm:"type: varchar(64)"`
+	DateOfBirth    encryption.EncryptedString `json:"date_of_birth"`
+	DocumentType   string                     `json:"document_type" gorm:"type: varchar(32)"`
+	DocumentNumber encryption.EncryptedString `json:"document_number"`
+	DocumentExpiry encryption.EncryptedString `json:"document_expiry"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"project/pkg/money"
+	"time"
+)
 
 type Trip struct {
 	Id             int                   `json:"id"  gorm:"primary_key:auto_increment"`
@@ -13,11 +16,17 @@ type Trip struct {
 	Day            int                   `json:"day" form:"day" gorm:"type: int"`
 	Night          int                   `json:"night" form:"night" gorm:"type: int"`
 	DateTrip       time.Time             `json:"datetrip"`
-	Price          int                   `json:"price" form:"price" gorm:"type: int"`
+	Price          money.Money           `json:"price" gorm:"embedded;embeddedPrefix:price_"`
 	Quota          int                   `json:"quota" form:"quota" gorm:"type: int"`
 	Description    string                `json:"description" form:"description" gorm:"type: varchar(255)"`
 	Image          string                `json:"image" form:"image" gorm:"type: varchar(255)"`
 	Transaction    []TransactionResponse `json:"transactions" gorm:"foreignKey: TripId"`
+	// jenis trip (misal domestic, international), dipakai untuk memilih aturan pajak
+	Type string `json:"type" form:"type" gorm:"type: varchar(32)"`
+	// keberangkatan dari paket trip yang sama memiliki group key yang sama, booking hanya bisa di-reschedule di dalam grup
+	GroupKey string `json:"group_key" form:"group_key" gorm:"type: varchar(255);index"`
+	// harga dalam mata uang pilihan user (parameter currency), hanya untuk tampilan
+	DisplayPrice *money.Money `json:"display_price,omitempty" gorm:"-"`
 }
 
 // relation database (to transaction)
@@ -27,12 +36,14 @@ type TripResponse struct {
 	CountryId      int             `json:"-"`
 	Country        CountryResponse `json:"country"`
 	Accomodation   string          `json:"accomodation"`
+	Type           string          `json:"type"`
+	GroupKey       string          `json:"group_key"`
 	Transportation string          `json:"transportation"`
 	Eat            string          `json:"eat"`
 	Day            int             `json:"day"`
 	Night          int             `json:"night"`
 	DateTrip       time.Time       `json:"datetrip"`
-	Price          int             `json:"price"`
+	Price          money.Money     `json:"price" gorm:"embedded;embeddedPrefix:price_"`
 	Quota          int             `json:"quota"`
 	Description    string          `json:"description"`
 	Image          string          `json:"image"`
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// akun login eksternal (OpenID Connect) yang terhubung ke user
+type UserIdentity struct {
+	Id        int       `json:"id" gorm:"primary_key:auto_increment"`
+	UserId    int       `json:"user_id" gorm:"index"`
+	Provider  string    `json:"provider" gorm:"type: varchar(64);uniqueIndex:idx_provider_subject"`
+	Subject   string    `json:"subject" gorm:"type: varchar(255);uniqueIndex:idx_provider_subject"`
+	Email     string    `json:"email" gorm:"type: varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+}
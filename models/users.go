@@ -1,15 +1,33 @@
 package models
 
+import "time"
+
 type User struct {
 	Id       int    `json:"id"`
 	Name     string `json:"name" gorm:"type: varchar(255)"`
-	Email    string `json:"email" gorm:"type: varchar(255)"`
+	Email    string `json:"email" gorm:"type: varchar(255);uniqueIndex"`
 	Password string `json:"password" gorm:"type: varchar(255)"`
 	Gender   string `json:"gender" gorm:"type: varchar(255)"`
 	Phone    string `json:"phone" gorm:"type: varchar(255)"`
 	Address  string `json:"address" gorm:"type: varchar(255)"`
 	Image    string `json:"image" gorm:"type: varchar(255)"`
 	Role     string `json:"role" gorm:"type: varchar(255)"`
+	// nil berarti email belum diverifikasi
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// dinaikkan setiap kali semua sesi user dicabut (misal setelah reset password)
+	TokenVersion int `json:"-" gorm:"type: int;default:0"`
+	// two-factor authentication (TOTP), secret baru dipakai setelah TotpEnabled true
+	TotpSecret   string `json:"-" gorm:"type: varchar(64)"`
+	TotpEnabled  bool   `json:"totp_enabled" gorm:"default:false"`
+	TotpLastStep int64  `json:"-" gorm:"default:0"`
+	// penghapusan akun dijadwalkan user (masa tunggu), setelah lewat data pribadi dianonimkan
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	AnonymizedAt        *time.Time `json:"anonymized_at"`
+	// diisi admin, user yang disuspend tidak bisa login dan semua sesinya dicabut
+	SuspendedAt     *time.Time `json:"suspended_at"`
+	SuspendedReason string     `json:"suspended_reason" gorm:"type: varchar(255)"`
+	// user wajib mengganti password lewat link reset sebelum bisa login lagi
+	PasswordResetRequired bool `json:"password_reset_required" gorm:"default:false"`
 }
 
 // relasi dengan tabel lain
@@ -17,7 +35,7 @@ type UserResponse struct {
 	Id       int    `json:"id"`
 	Name     string `json:"name"`
 	Email    string `json:"email"`
-	Password string `json:"password"`
+	Password string `json:"-"` // password hash tidak pernah dikirim di response
 	Gender   string `json:"gender"`
 	Phone    string `json:"phone"`
 	Address  string `json:"address"`
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// status antrean waitlist
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"
+	WaitlistBooked    = "booked"
+	WaitlistExpired   = "expired"
+	WaitlistCancelled = "cancelled"
+)
+
+// antrean user untuk trip yang kuotanya habis. saat kursi kosong, user paling awal yang jumlah kursinya cukup
+// mendapat penawaran booking dengan batas waktu. kursi yang ditawarkan tidak bisa dibooking user lain
+type WaitlistEntry struct {
+	Id             int          `json:"id" gorm:"primary_key:auto_increment"`
+	UserId         int          `json:"-" gorm:"index"`
+	User           UserResponse `json:"-"`
+	TripId         int          `json:"-" gorm:"index"`
+	Trip           TripResponse `json:"trip"`
+	Seats          int          `json:"seats" gorm:"type: int"`
+	Status         string       `json:"status" gorm:"type: varchar(16);index"`
+	OfferedAt      *time.Time   `json:"offered_at"`
+	OfferExpiresAt *time.Time   `json:"offer_expires_at"`
+	TransactionId  int          `json:"transaction_id"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
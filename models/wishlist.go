@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// trip yang disimpan user untuk dibeli nanti. PriceBaseline adalah harga terakhir yang sudah diketahui user,
+// notifikasi harga turun dikirim jika harga trip lebih rendah dari nilai ini
+type Wishlist struct {
+	Id                int          `json:"id" gorm:"primary_key:auto_increment"`
+	UserId            int          `json:"-" gorm:"uniqueIndex:idx_wishlist_user_trip"`
+	User              UserResponse `json:"-"`
+	TripId            int          `json:"-" gorm:"uniqueIndex:idx_wishlist_user_trip;index"`
+	Trip              TripResponse `json:"trip"`
+	NotifyPriceDrop   bool         `json:"notify_price_drop" gorm:"default:false"`
+	NotifySellOut     bool         `json:"notify_sell_out" gorm:"default:false"`
+	PriceAtAdded      int          `json:"price_at_added" gorm:"type: int"`
+	PriceBaseline     int          `json:"-" gorm:"type: int"`
+	SellOutNotifiedAt *time.Time   `json:"-"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// semua api key diawali "dwt_" lalu prefix publik 8 karakter (untuk identifikasi dan pencarian di database),
+// lalu secret 32 byte. contoh: dwt_1a2b3c4d_<64 karakter hex>
+const keyPrefix = "dwt_"
+
+// function Generate membuat api key baru dan mengembalikan key lengkap (hanya ditampilkan sekali), prefix dan hash yang disimpan
+func Generate() (key string, prefix string, hash string, err error) {
+	public := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err = rand.Read(public); err != nil {
+		return
+	}
+	if _, err = rand.Read(secret); err != nil {
+		return
+	}
+
+	prefix = keyPrefix + hex.EncodeToString(public)
+	key = prefix + "_" + hex.EncodeToString(secret)
+	hash = Hash(key)
+	return
+}
+
+// function Hash menghitung hash sha256 dari key, hanya hash ini yang disimpan di database
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// function Looks mengecek apakah token berbentuk api key (bukan jwt)
+func Looks(token string) bool {
+	return strings.HasPrefix(token, keyPrefix)
+}
+
+// function Prefix mengambil prefix publik dari key, ok false jika format key salah
+func Prefix(key string) (string, bool) {
+	if !Looks(key) {
+		return "", false
+	}
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || len(parts[1]) != 8 || len(parts[2]) != 64 {
+		return "", false
+	}
+	return parts[0] + "_" + parts[1], true
+}
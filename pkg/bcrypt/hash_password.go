@@ -1,9 +1,26 @@
 package bcrypt
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cost default jika env BCRYPT_COST kosong atau tidak valid
+const DefaultCost = 10
+
+// function Cost mengambil cost bcrypt dari env BCRYPT_COST (4 sampai 31). makin tinggi makin aman tetapi makin lambat
+func Cost() int {
+	cost, err := strconv.Atoi(os.Getenv("BCRYPT_COST"))
+	if err != nil || cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return DefaultCost
+	}
+	return cost
+}
 
 func HashingPassword(password string) (string, error) {
-	hashedByte, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	hashedByte, err := bcrypt.GenerateFromPassword([]byte(password), Cost())
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,220 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// awalan nilai terenkripsi di database, versi dipakai jika nanti format atau kunci diganti
+const prefix = "enc:v1:"
+
+var (
+	ErrNoMasterKey = errors.New("ENCRYPTION_MASTER_KEY is not set")
+	ErrInvalidKey  = errors.New("ENCRYPTION_MASTER_KEY must be 32 bytes encoded as base64")
+	ErrCiphertext  = errors.New("invalid ciphertext")
+)
+
+var (
+	masterOnce sync.Once
+	master     []byte
+	masterErr  error
+)
+
+// function MasterKey membaca master key dari env ENCRYPTION_MASTER_KEY (32 byte base64, misal dari `openssl rand -base64 32`)
+func MasterKey() ([]byte, error) {
+	masterOnce.Do(func() {
+		value := strings.TrimSpace(os.Getenv("ENCRYPTION_MASTER_KEY"))
+		if value == "" {
+			masterErr = ErrNoMasterKey
+			return
+		}
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(key) != 32 {
+			masterErr = ErrInvalidKey
+			return
+		}
+		master = key
+	})
+	return master, masterErr
+}
+
+// function Seal mengenkripsi data dengan AES-256-GCM, hasilnya nonce diikuti ciphertext
+func Seal(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// function Open membuka data hasil Seal, gagal jika data diubah atau kunci salah
+func Open(key []byte, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrCiphertext
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrCiphertext
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// function EncryptString mengenkripsi teks dengan master key. string kosong tidak dienkripsi
+func EncryptString(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	key, err := MasterKey()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := Seal(key, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// function DecryptString membuka teks hasil EncryptString. nilai tanpa awalan dianggap belum dienkripsi
+func DecryptString(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	key, err := MasterKey()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", ErrCiphertext
+	}
+	plaintext, err := Open(key, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// EncryptedString adalah kolom gorm yang otomatis dienkripsi saat disimpan dan didekripsi saat dibaca
+type EncryptedString string
+
+func (s EncryptedString) Value() (driver.Value, error) {
+	return EncryptString(string(s))
+}
+
+func (s *EncryptedString) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		raw = ""
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("encryption: cannot scan %T", value)
+	}
+
+	plaintext, err := DecryptString(raw)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// GormDataType membuat kolom dengan tipe text karena ciphertext lebih panjang dari teks aslinya
+func (EncryptedString) GormDataType() string {
+	return "text"
+}
+
+// function SealEnvelope mengenkripsi data dengan data key acak, lalu data key dienkripsi dengan master key (envelope encryption).
+// yang disimpan hanya ciphertext dan wrappedKey, sehingga master key bisa diganti tanpa mengenkripsi ulang semua file
+func SealEnvelope(plaintext []byte) (ciphertext []byte, wrappedKey []byte, err error) {
+	key, err := MasterKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, err = Seal(dataKey, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrappedKey, err = Seal(key, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ciphertext, wrappedKey, nil
+}
+
+// function OpenEnvelope membuka data hasil SealEnvelope
+func OpenEnvelope(ciphertext []byte, wrappedKey []byte) ([]byte, error) {
+	key, err := MasterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := Open(key, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return Open(dataKey, ciphertext)
+}
+
+// function Sign membuat tanda tangan HMAC-SHA256 (hex) dengan kunci turunan master key untuk keperluan purpose,
+// misal signed url download dokumen
+func Sign(purpose string, message string) (string, error) {
+	key, err := MasterKey()
+	if err != nil {
+		return "", err
+	}
+
+	derived := hmac.New(sha256.New, key)
+	derived.Write([]byte(purpose))
+
+	mac := hmac.New(sha256.New, derived.Sum(nil))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// function Verify mengecek tanda tangan dari Sign dengan perbandingan waktu konstan
+func Verify(purpose string, message string, signature string) bool {
+	expected, err := Sign(purpose, message)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
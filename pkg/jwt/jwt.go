@@ -8,6 +8,17 @@ import (
 
 var SecretKey = "SECRET_KEY"
 
+// purpose untuk token khusus yang dibuat GeneratePurposeToken
+const (
+	PurposeVerifyEmail = "verify_email"
+	// token sementara setelah password benar, ditukar dengan token login lewat /login/2fa
+	PurposeMFALogin = "mfa_login"
+	// token sementara untuk role yang wajib 2FA tapi belum mengaktifkannya
+	PurposeMFAEnroll = "mfa_enroll"
+	// state login OpenID Connect yang disimpan di cookie selama user berada di halaman provider
+	PurposeOIDCState = "oidc_state"
+)
+
 // function GenerateToken untuk membuat token
 func GenerateToken(claims *jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -43,6 +54,10 @@ func DecodeToken(tokenString string) (jwt.MapClaims, error) {
 
 	claims, isOk := token.Claims.(jwt.MapClaims)
 	if isOk && token.Valid {
+		// token khusus (verifikasi email dsb) tidak boleh dipakai sebagai token login
+		if _, hasPurpose := claims["purpose"]; hasPurpose {
+			return nil, fmt.Errorf("invalid token")
+		}
 		return claims, nil
 	}
 
@@ -50,3 +65,25 @@ func DecodeToken(tokenString string) (jwt.MapClaims, error) {
 }
 
 // function DecodeToken berfungsi ketika request masuk, middleware akan mengecek apakah ada auth?, jika ada maka token akan diambil lalu dikirim ke fungsi decodeToken didalam decode token. lalu token akan diperiksa menggunakan function verifyToken, apabila token valid maka function decodeToken akan mengambil data yang disisipkan kedalam token
+
+// function GeneratePurposeToken membuat token untuk satu keperluan saja (misal verifikasi email).
+// token ini ditolak oleh DecodeToken sehingga tidak bisa dipakai untuk login
+func GeneratePurposeToken(purpose string, claims jwt.MapClaims) (string, error) {
+	claims["purpose"] = purpose
+	return GenerateToken(&claims)
+}
+
+// function DecodePurposeToken memvalidasi token yang dibuat GeneratePurposeToken dengan keperluan yang sama
+func DecodePurposeToken(purpose string, tokenString string) (jwt.MapClaims, error) {
+	token, err := VerifyToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, isOk := token.Claims.(jwt.MapClaims)
+	if isOk && token.Valid && claims["purpose"] == purpose {
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("invalid token")
+}
@@ -0,0 +1,76 @@
+package loginguard
+
+import (
+	"errors"
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DatabaseStore menyimpan catatan gagal login di tabel login_attempts sehingga bisa dipakai bersama oleh beberapa server
+type DatabaseStore struct {
+	db *gorm.DB
+}
+
+func NewDatabaseStore(db *gorm.DB) *DatabaseStore {
+	return &DatabaseStore{db}
+}
+
+func (s *DatabaseStore) Get(key string) (Record, error) {
+	var attempt models.LoginAttempt
+	err := s.db.First(&attempt, "`key` = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Record{Key: key}, nil
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	return toRecord(attempt), nil
+}
+
+func (s *DatabaseStore) Increment(key string, now time.Time, window time.Duration) (Record, error) {
+	// upsert atomic: gagal lama (di luar window) dihitung ulang dari 1
+	attempt := models.LoginAttempt{Key: key, Failures: 1, LastFailure: now}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":     gorm.Expr("CASE WHEN last_failure < ? THEN 1 ELSE failures + 1 END", now.Add(-window)),
+			"last_failure": now,
+		}),
+	}).Create(&attempt).Error
+	if err != nil {
+		return Record{}, err
+	}
+
+	return s.Get(key)
+}
+
+func (s *DatabaseStore) Lock(key string, until time.Time) error {
+	attempt := models.LoginAttempt{Key: key, LastFailure: time.Now(), LockedUntil: &until}
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":     0,
+			"locked_until": until,
+		}),
+	}).Create(&attempt).Error
+}
+
+func (s *DatabaseStore) Reset(key string) error {
+	return s.db.Where("`key` = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+func toRecord(attempt models.LoginAttempt) Record {
+	record := Record{
+		Key:         attempt.Key,
+		Failures:    attempt.Failures,
+		LastFailure: attempt.LastFailure,
+	}
+	if attempt.LockedUntil != nil {
+		record.LockedUntil = *attempt.LockedUntil
+	}
+	return record
+}
@@ -0,0 +1,137 @@
+package loginguard
+
+import (
+	"errors"
+	"time"
+)
+
+// Record adalah catatan gagal login untuk satu key (akun atau ip)
+type Record struct {
+	Key         string
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Store menyimpan catatan gagal login. MemoryStore cukup untuk satu server,
+// DatabaseStore dipakai jika aplikasi berjalan di beberapa server sekaligus
+type Store interface {
+	Get(key string) (Record, error)
+	// Increment menambah jumlah gagal secara atomic. gagal yang lebih lama dari window tidak dihitung lagi
+	Increment(key string, now time.Time, window time.Duration) (Record, error)
+	// Lock mengunci key sampai waktu until dan mengosongkan jumlah gagal
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+// Policy mengatur kapan backoff dan lockout terjadi
+type Policy struct {
+	// setelah BackoffAfter kali gagal, percobaan berikutnya harus menunggu BackoffBase * 2^(gagal-BackoffAfter)
+	BackoffAfter int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	// setelah LockAfter kali gagal, key dikunci selama LockFor
+	LockAfter int
+	LockFor   time.Duration
+	// gagal yang lebih lama dari Window tidak dihitung lagi
+	Window time.Duration
+}
+
+// batas default untuk akun dan ip. ip diberi batas lebih longgar karena bisa dipakai banyak user (NAT, kantor)
+var (
+	AccountPolicy = Policy{BackoffAfter: 3, BackoffBase: time.Second, BackoffMax: time.Minute, LockAfter: 10, LockFor: time.Minute * 15, Window: time.Hour}
+	IPPolicy      = Policy{BackoffAfter: 20, BackoffBase: time.Second, BackoffMax: time.Minute, LockAfter: 100, LockFor: time.Minute * 15, Window: time.Hour}
+)
+
+// ErrBlocked dikembalikan Check jika key sedang backoff atau terkunci
+type ErrBlocked struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *ErrBlocked) Error() string {
+	if e.Locked {
+		return "too many failed login attempts, the account is temporarily locked"
+	}
+	return "too many failed login attempts, please wait before trying again"
+}
+
+// Guard menerapkan Policy di atas Store
+type Guard struct {
+	store Store
+	now   func() time.Time
+}
+
+func New(store Store) *Guard {
+	return &Guard{store: store, now: time.Now}
+}
+
+// Check mengembalikan *ErrBlocked jika key belum boleh mencoba login
+func (g *Guard) Check(policy Policy, key string) error {
+	record, err := g.store.Get(key)
+	if err != nil {
+		return err
+	}
+
+	now := g.now()
+	if now.Before(record.LockedUntil) {
+		return &ErrBlocked{RetryAfter: record.LockedUntil.Sub(now), Locked: true}
+	}
+
+	if record.Failures >= policy.BackoffAfter && now.Sub(record.LastFailure) < policy.Window {
+		wait := policy.backoff(record.Failures)
+		if next := record.LastFailure.Add(wait); now.Before(next) {
+			return &ErrBlocked{RetryAfter: next.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// Fail mencatat satu gagal login dan mengembalikan true jika gagal ini membuat key terkunci
+func (g *Guard) Fail(policy Policy, key string) (bool, error) {
+	now := g.now()
+	record, err := g.store.Increment(key, now, policy.Window)
+	if err != nil {
+		return false, err
+	}
+
+	// jumlah gagal dikosongkan saat dikunci agar setelah kunci dibuka user mendapat kesempatan baru
+	if record.Failures >= policy.LockAfter {
+		return true, g.store.Lock(key, now.Add(policy.LockFor))
+	}
+
+	return false, nil
+}
+
+// Reset menghapus catatan gagal dan membuka kunci key (setelah login berhasil atau dibuka admin)
+func (g *Guard) Reset(key string) error {
+	return g.store.Reset(key)
+}
+
+// IsBlocked memudahkan pengecekan error dari Check
+func IsBlocked(err error) (*ErrBlocked, bool) {
+	var blocked *ErrBlocked
+	ok := errors.As(err, &blocked)
+	return blocked, ok
+}
+
+func (p Policy) backoff(failures int) time.Duration {
+	wait := p.BackoffBase
+	for i := p.BackoffAfter; i < failures; i++ {
+		wait *= 2
+		if wait >= p.BackoffMax {
+			return p.BackoffMax
+		}
+	}
+	return wait
+}
+
+// key untuk akun dan ip
+func AccountKey(email string) string {
+	return "account:" + email
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,138 @@
+package loginguard
+
+import (
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{BackoffAfter: 3, BackoffBase: time.Second, BackoffMax: 10 * time.Second, LockAfter: 6, LockFor: time.Minute, Window: time.Hour}
+
+// function newTestGuard membuat Guard dengan jam yang bisa dimajukan oleh test
+func newTestGuard() (*Guard, *time.Time) {
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	guard := New(NewMemoryStore())
+	guard.now = func() time.Time { return now }
+	return guard, &now
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{6, 8 * time.Second},
+		{7, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := testPolicy.backoff(tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		// jarak antara gagal terakhir dan Check
+		elapsed    time.Duration
+		wantRetry  time.Duration
+		wantLocked bool
+	}{
+		{"no failures", 0, 0, 0, false},
+		{"below backoff", 2, 0, 0, false},
+		{"backoff starts", 3, 0, time.Second, false},
+		{"backoff grows", 5, time.Second, 3 * time.Second, false},
+		{"backoff elapsed", 5, 4 * time.Second, 0, false},
+		{"locked", 6, 0, time.Minute, true},
+		{"lock elapsed", 6, time.Minute, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard, now := newTestGuard()
+			for i := 0; i < tt.failures; i++ {
+				if _, err := guard.Fail(testPolicy, "account:a"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			*now = now.Add(tt.elapsed)
+
+			err := guard.Check(testPolicy, "account:a")
+			blocked, ok := IsBlocked(err)
+			if tt.wantRetry == 0 {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Check() = %v, want ErrBlocked", err)
+			}
+			if blocked.RetryAfter != tt.wantRetry || blocked.Locked != tt.wantLocked {
+				t.Errorf("Check() = %+v, want retry %v locked %t", blocked, tt.wantRetry, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestFailLocksAndResets(t *testing.T) {
+	guard, now := newTestGuard()
+
+	for i := 1; i <= testPolicy.LockAfter; i++ {
+		locked, err := guard.Fail(testPolicy, "account:a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i == testPolicy.LockAfter; locked != want {
+			t.Fatalf("Fail() #%d locked = %t, want %t", i, locked, want)
+		}
+	}
+
+	// setelah kunci terbuka jumlah gagal mulai dari nol
+	*now = now.Add(testPolicy.LockFor)
+	if err := guard.Check(testPolicy, "account:a"); err != nil {
+		t.Fatalf("Check() after lock = %v, want nil", err)
+	}
+	if locked, _ := guard.Fail(testPolicy, "account:a"); locked {
+		t.Fatal("Fail() after lock locked again")
+	}
+
+	// key lain tidak terpengaruh
+	if err := guard.Check(testPolicy, "account:b"); err != nil {
+		t.Fatalf("Check() other key = %v, want nil", err)
+	}
+
+	if err := guard.Reset("account:a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(testPolicy, "account:a"); err != nil {
+		t.Fatalf("Check() after reset = %v, want nil", err)
+	}
+}
+
+func TestFailWindow(t *testing.T) {
+	guard, now := newTestGuard()
+
+	for i := 0; i < testPolicy.LockAfter-1; i++ {
+		guard.Fail(testPolicy, "account:a")
+	}
+
+	// gagal yang lebih lama dari window tidak dihitung, jadi gagal berikutnya tidak mengunci
+	*now = now.Add(testPolicy.Window + time.Second)
+	locked, err := guard.Fail(testPolicy, "account:a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked {
+		t.Fatal("Fail() after window locked the key")
+	}
+	if err := guard.Check(testPolicy, "account:a"); err != nil {
+		t.Fatalf("Check() = %v, want nil", err)
+	}
+}
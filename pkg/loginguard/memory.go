@@ -0,0 +1,90 @@
+package loginguard
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore menyimpan catatan gagal login di memory, hanya cocok untuk satu server.
+// catatan yang window dan kuncinya sudah lewat dihapus saat Increment agar memory tidak terus bertambah
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	// window terpanjang yang pernah dipakai Increment dan waktu terakhir semua catatan diperiksa
+	window  time.Duration
+	sweptAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+func (s *MemoryStore) Get(key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		record.Key = key
+	}
+	return record, nil
+}
+
+func (s *MemoryStore) Increment(key string, now time.Time, window time.Duration) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if window > s.window {
+		s.window = window
+	}
+	if now.Sub(s.sweptAt) >= s.window {
+		s.sweep(now)
+	}
+
+	record := s.records[key]
+	record.Key = key
+	if now.Sub(record.LastFailure) > window {
+		record.Failures = 0
+	}
+	record.Failures++
+	record.LastFailure = now
+	s.records[key] = record
+
+	return record, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	record.Key = key
+	record.Failures = 0
+	record.LockedUntil = until
+	s.records[key] = record
+
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// expired bernilai true jika gagal terakhir sudah di luar window dan kunci sudah terbuka
+func (s *MemoryStore) expired(record Record, now time.Time) bool {
+	return s.window > 0 && now.Sub(record.LastFailure) > s.window && !now.Before(record.LockedUntil)
+}
+
+// sweep menghapus semua catatan yang sudah expired, dipanggil paling sering sekali per window
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, record := range s.records {
+		if s.expired(record, now) {
+			delete(s.records, key)
+		}
+	}
+	s.sweptAt = now
+}
@@ -0,0 +1,44 @@
+package loginguard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreEviction(t *testing.T) {
+	window := time.Hour
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	start := now.Add(-3 * window)
+
+	store := NewMemoryStore()
+	store.Increment("ip:old", start, window)
+	store.Increment("ip:locked", start, window)
+	store.Lock("ip:locked", now.Add(time.Minute))
+	store.Increment("ip:recent", now.Add(-time.Minute), window)
+
+	// Increment berikutnya menyapu catatan yang window dan kuncinya sudah lewat
+	store.Increment("ip:new", now, window)
+
+	tests := []struct {
+		key  string
+		kept bool
+	}{
+		{"ip:old", false},
+		{"ip:locked", true},
+		{"ip:recent", true},
+		{"ip:new", true},
+	}
+	for _, tt := range tests {
+		if _, kept := store.records[tt.key]; kept != tt.kept {
+			t.Errorf("record %s kept = %t, want %t", tt.key, kept, tt.kept)
+		}
+	}
+
+	// sapuan berikutnya baru terjadi setelah satu window
+	store.Increment("ip:old", now.Add(time.Minute), window)
+	store.Lock("ip:locked", now)
+	store.Increment("ip:new", now.Add(2*time.Minute), window)
+	if _, kept := store.records["ip:locked"]; !kept {
+		t.Error("record swept before the next window")
+	}
+}
@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Message adalah email yang akan dikirim oleh sistem
+type Message struct {
+	To          string
+	Subject     string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Attachment adalah file yang dilampirkan ke email, misal invoice pdf
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// function Send mengirim email lewat smtp memakai akun sistem (SYSTEM_EMAIL & SYSTEM_PASSWORD).
+// jika akun sistem belum diisi (misal di local) email hanya ditampilkan di console
+func Send(message Message) error {
+	var CONFIG_SMTP_HOST = getEnv("SMTP_HOST", "smtp.gmail.com")
+	var CONFIG_SMTP_PORT, _ = strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	var CONFIG_SENDER_NAME = getEnv("SENDER_NAME", "dewetour <rafialfian770@gmail.com>")
+	var CONFIG_AUTH_EMAIL = os.Getenv("SYSTEM_EMAIL")
+	var CONFIG_AUTH_PASSWORD = os.Getenv("SYSTEM_PASSWORD")
+
+	if CONFIG_AUTH_EMAIL == "" || CONFIG_AUTH_PASSWORD == "" {
+		// alamat penerima tidak ditulis ke log
+		log.Printf("mail: smtp not configured, email %q (%d attachments) not sent", message.Subject, len(message.Attachments))
+		return nil
+	}
+
+	mailer := gomail.NewMessage()
+	mailer.SetHeader("From", CONFIG_SENDER_NAME)
+	mailer.SetHeader("To", message.To)
+	mailer.SetHeader("Subject", message.Subject)
+	mailer.SetBody("text/html", message.HTML)
+	for _, attachment := range message.Attachments {
+		data := attachment.Data
+		mailer.Attach(attachment.Filename,
+			gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}),
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(data)
+				return err
+			}))
+	}
+
+	dialer := gomail.NewDialer(
+		CONFIG_SMTP_HOST,
+		CONFIG_SMTP_PORT,
+		CONFIG_AUTH_EMAIL,
+		CONFIG_AUTH_PASSWORD,
+	)
+
+	dialer.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+
+	return dialer.DialAndSend(mailer)
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
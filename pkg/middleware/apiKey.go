@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"project/pkg/apikey"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errInvalidAPIKey = errors.New("invalid api key")
+	errAPIKeyScope   = errors.New("api key scope does not allow this endpoint")
+)
+
+// function authenticateAPIKey memvalidasi api key lalu membuat claims yang sama bentuknya dengan claims jwt login,
+// sehingga handler tidak perlu membedakan keduanya. route harus terdaftar di policy.RouteScope dan key harus memiliki scope-nya
+func authenticateAPIKey(r *http.Request, key string) (jwt.MapClaims, error) {
+	prefix, ok := apikey.Prefix(key)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+
+	apiKeyRepository := repositories.RepositoryAPIKey(mysql.DB)
+	record, err := apiKeyRepository.GetAPIKeyByPrefix(prefix)
+	if err != nil {
+		return nil, errInvalidAPIKey
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(record.KeyHash), []byte(apikey.Hash(key))) != 1 ||
+		record.RevokedAt != nil ||
+		(record.ExpiresAt != nil && now.After(*record.ExpiresAt)) {
+		return nil, errInvalidAPIKey
+	}
+
+	user, err := apiKeyRepository.GetUser(record.UserId)
+	if err != nil || user.SuspendedAt != nil {
+		return nil, errInvalidAPIKey
+	}
+
+	// route yang dipanggil harus diizinkan oleh salah satu scope key
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return nil, errAPIKeyScope
+	}
+	template, _ := route.GetPathTemplate()
+	required, ok := policy.RouteScope(r.Method, template)
+	if !ok || !hasScope(record.Scopes, required) {
+		return nil, errAPIKeyScope
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) > sessionTouchInterval {
+		apiKeyRepository.TouchAPIKey(record.Id, now, ClientIP(r))
+	}
+
+	claims := jwt.MapClaims{
+		"id":         float64(user.Id),
+		"role":       user.Role,
+		"email":      user.Email,
+		"api_key_id": float64(record.Id),
+	}
+	return claims, nil
+}
+
+// function apiKeyFromRequest mengambil api key dari header X-API-Key atau Authorization: Bearer dwt_...
+func apiKeyFromRequest(r *http.Request) (string, bool) {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key, true
+	}
+	if token := bearerToken(r); apikey.Looks(token) {
+		return token, true
+	}
+	return "", false
+}
+
+func hasScope(scopes string, required policy.Scope) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		if strings.TrimSpace(s) == string(required) {
+			return true
+		}
+	}
+	return false
+}
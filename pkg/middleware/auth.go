@@ -3,9 +3,11 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	dto "project/dto"
 	jwtToken "project/pkg/jwt"
+	"project/pkg/policy"
 	"strings"
 )
 
@@ -20,19 +22,40 @@ func Auth(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		token := r.Header.Get("Authorization")
+		// api key partner diterima sebagai pengganti token login
+		if key, ok := apiKeyFromRequest(r); ok {
+			claims, err := authenticateAPIKey(r, key)
+			if errors.Is(err, errAPIKeyScope) {
+				policy.Deny(w, policy.ErrForbidden)
+				return
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				response := dto.ErrorResult{Code: http.StatusUnauthorized, Message: "unauthorized"}
+				json.NewEncoder(w).Encode(response)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "userInfo", claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		token := bearerToken(r)
 
 		// jika token kosong maka panggil ErrorResult
 		if token == "" {
 			w.WriteHeader(http.StatusUnauthorized)
-			response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "unauthorized"}
+			response := dto.ErrorResult{Code: http.StatusUnauthorized, Message: "unauthorized"}
 			json.NewEncoder(w).Encode(response)
 			return
 		}
 
-		// token akan displit dan diambil index ke 1 dan token akan dipanggil di DecodeToken
-		token = strings.Split(token, " ")[1]
+		// token akan dipanggil di DecodeToken
 		claims, err := jwtToken.DecodeToken(token)
+		if err == nil {
+			err = SessionCheck(claims)
+		}
 
 		// jika ada error maka panggil Result dan tampilkan pesan
 		if err != nil {
@@ -42,9 +65,53 @@ func Auth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		if !impersonationGuard(w, r, claims) {
+			return
+		}
+
 		//
 		ctx := context.WithValue(r.Context(), "userInfo", claims)
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// function bearerToken mengambil token dari header "Authorization: Bearer <token>"
+func bearerToken(r *http.Request) string {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// function AuthOrPurpose sama seperti Auth, tetapi juga menerima token khusus dengan purpose tertentu
+// (misal token enroll 2FA yang didapat saat login sebelum 2FA aktif)
+func AuthOrPurpose(purpose string, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		token := bearerToken(r)
+		claims, err := jwtToken.DecodeToken(token)
+		if err != nil {
+			claims, err = jwtToken.DecodePurposeToken(purpose, token)
+		}
+		if err == nil {
+			err = SessionCheck(claims)
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			response := dto.ErrorResult{Code: http.StatusUnauthorized, Message: "unauthorized"}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		if !impersonationGuard(w, r, claims) {
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "userInfo", claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
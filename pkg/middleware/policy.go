@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"project/pkg/policy"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// function Can dipanggil setelah Auth, request hanya diteruskan jika role user memiliki permission
+func Can(permission policy.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject := policy.FromRequest(r)
+		if subject.Id == 0 || !subject.Has(permission) {
+			policy.Deny(w, policy.ErrForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// function OwnerOr dipanggil setelah Auth untuk route yang id usernya ada di path (misal /user/{id}).
+// request diteruskan jika id di path milik user itu sendiri, atau user memiliki permission
+func OwnerOr(permission policy.Permission, param string, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ownerId, err := strconv.Atoi(mux.Vars(r)[param])
+		if err != nil {
+			policy.Deny(w, policy.ErrNotFound)
+			return
+		}
+
+		if err := policy.Authorize(policy.FromRequest(r), permission, ownerId); err != nil {
+			policy.Deny(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	dto "project/dto"
+	"project/pkg/ratelimit"
+)
+
+// function RateLimit membatasi jumlah request per ip memakai limiter yang diberikan
+func RateLimit(limiter *ratelimit.Limiter, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.Allow(ClientIP(r)) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			response := dto.ErrorResult{Code: http.StatusTooManyRequests, Message: "too many requests, please try again later"}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// function ClientIP mengambil ip address pemanggil dari koneksi
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// last_seen_at sesi hanya diupdate paling sering sekali per menit agar tidak menulis ke database di setiap request
+const sessionTouchInterval = time.Minute
+
+// SessionCheck dipanggil Auth untuk memastikan sesi token masih berlaku. test route menggantinya agar tidak
+// membutuhkan database
+var SessionCheck = checkSession
+
+// function checkSession memastikan sesi token belum dicabut, yaitu claim "ver" masih sama dengan token_version user
+// dan sesi pada claim "sid" masih aktif
+func checkSession(claims jwt.MapClaims) error {
+	userId, _ := claims["id"].(float64)
+	user, err := repositories.RepositoryAuth(mysql.DB).Getuser(int(userId))
+	if err != nil {
+		return err
+	}
+
+	if user.SuspendedAt != nil {
+		return errors.New("account suspended")
+	}
+
+	version, _ := claims["ver"].(float64)
+	if int(version) != user.TokenVersion {
+		return errors.New("session revoked")
+	}
+
+	// token khusus (misal enroll 2FA) tidak memiliki sesi
+	tokenId, ok := claims["sid"].(string)
+	if !ok {
+		return nil
+	}
+
+	sessionRepository := repositories.RepositorySession(mysql.DB)
+	session, err := sessionRepository.GetSessionByTokenId(tokenId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if session.UserId != user.Id || session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return errors.New("session revoked")
+	}
+
+	if session.ImpersonatorId != policy.ImpersonatorId(claims) {
+		return errors.New("session revoked")
+	}
+	if err := checkImpersonator(claims); err != nil {
+		return err
+	}
+
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		sessionRepository.TouchSession(session.Id, now)
+	}
+
+	return nil
+}
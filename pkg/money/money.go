@@ -0,0 +1,175 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Settlement adalah mata uang pembayaran. harga trip, total transaksi dan pembayaran midtrans selalu dalam IDR
+const Settlement = "IDR"
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidRate         = errors.New("exchange rate must be a positive decimal number")
+	ErrNoRate              = errors.New("exchange rate is not available for this currency")
+)
+
+// jumlah digit minor unit per mata uang. IDR memakai 0 digit karena midtrans hanya menerima rupiah bulat
+var exponents = map[string]int{
+	"IDR": 0,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"AUD": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"THB": 2,
+	"CNY": 2,
+	"SAR": 2,
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// Money adalah jumlah uang dalam minor unit (misal sen untuk USD) beserta kode mata uang ISO 4217.
+// di database disimpan sebagai dua kolom lewat gorm embedded, misal price_amount dan price_currency
+type Money struct {
+	Amount   int64  `json:"amount" gorm:"type: bigint"`
+	Currency string `json:"currency" gorm:"type: varchar(3)"`
+}
+
+// function IDR membuat Money dalam mata uang settlement
+func IDR(amount int64) Money {
+	return Money{Amount: amount, Currency: Settlement}
+}
+
+// function Normalize mengubah kode mata uang menjadi huruf besar lalu memastikan mata uang didukung
+func Normalize(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if _, ok := exponents[currency]; !ok {
+		return "", ErrUnsupportedCurrency
+	}
+	return currency, nil
+}
+
+// function Exponent mengembalikan jumlah digit minor unit, mata uang yang tidak dikenal dianggap 2 digit
+func Exponent(currency string) int {
+	if exponent, ok := exponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// function Decimal menampilkan jumlah dalam major unit tanpa pemisah ribuan, misal "95.12"
+func (m Money) Decimal() string {
+	exponent := Exponent(m.Currency)
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// function String menampilkan jumlah dengan kode mata uang dan pemisah ribuan, misal "IDR 1,500,000" atau "USD 95.12"
+func (m Money) String() string {
+	value := m.Decimal()
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign = "-"
+		value = value[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	if hasFraction {
+		grouped.WriteString("." + fraction)
+	}
+	return m.Currency + " " + sign + grouped.String()
+}
+
+// json menyertakan jumlah desimal dan teks siap tampil agar client tidak perlu tahu digit minor unit
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount    int64  `json:"amount"`
+		Currency  string `json:"currency"`
+		Decimal   string `json:"decimal"`
+		Formatted string `json:"formatted"`
+	}{m.Amount, m.Currency, m.Decimal(), m.String()})
+}
+
+// function ParseRate membaca kurs desimal, misal "15650.25" artinya 1 unit mata uang asing = Rp 15.650,25
+func ParseRate(rate string) (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || value.Sign() <= 0 || strings.ContainsAny(rate, "/eE") {
+		return nil, ErrInvalidRate
+	}
+	return value, nil
+}
+
+// Converter mengubah jumlah IDR menjadi mata uang tampilan. hasilnya hanya untuk ditampilkan, pembayaran tetap dalam IDR
+type Converter struct {
+	Currency string
+	Rate     string
+	rate     *big.Rat
+}
+
+// function NewConverter membuat Converter dari kurs IDR per 1 unit mata uang tampilan
+func NewConverter(currency string, rate string) (Converter, error) {
+	currency, err := Normalize(currency)
+	if err != nil {
+		return Converter{}, err
+	}
+	value, err := ParseRate(rate)
+	if err != nil {
+		return Converter{}, err
+	}
+	return Converter{Currency: currency, Rate: value.FloatString(8), rate: value}, nil
+}
+
+// function Convert mengonversi jumlah IDR, dibulatkan ke minor unit terdekat (setengah menjauhi nol)
+func (c Converter) Convert(m Money) Money {
+	if c.rate == nil || m.Currency == c.Currency {
+		return m
+	}
+
+	// amount / 10^exp(IDR) / rate * 10^exp(tujuan)
+	value := new(big.Rat).SetInt64(m.Amount)
+	value.Mul(value, new(big.Rat).SetInt(pow10(Exponent(c.Currency))))
+	value.Quo(value, new(big.Rat).SetInt(pow10(Exponent(m.Currency))))
+	value.Quo(value, c.rate)
+
+	return Money{Amount: roundHalfAway(value), Currency: c.Currency}
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+func roundHalfAway(value *big.Rat) int64 {
+	num := new(big.Int).Abs(value.Num())
+	quotient, remainder := new(big.Int).QuoRem(num, value.Denom(), new(big.Int))
+	if remainder.Mul(remainder, big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	return quotient.Int64()
+}
@@ -0,0 +1,122 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		rate     string
+		amount   int64
+		want     Money
+	}{
+		{"exponent 2", "USD", "15000", 1500000, Money{10000, "USD"}},
+		{"exponent 2 exact minor unit", "USD", "15000", 150, Money{1, "USD"}},
+		{"exponent 2 half rounds up", "USD", "15000", 75, Money{1, "USD"}},
+		{"exponent 2 below half rounds down", "USD", "15000", 74, Money{0, "USD"}},
+		{"exponent 2 negative half rounds away from zero", "USD", "15000", -75, Money{-1, "USD"}},
+		{"exponent 2 decimal rate", "EUR", "16500.50", 1650050, Money{10000, "EUR"}},
+		{"exponent 0", "JPY", "100", 15000, Money{150, "JPY"}},
+		{"exponent 0 half rounds up", "JPY", "100", 150, Money{2, "JPY"}},
+		{"exponent 0 odd half rounds up", "JPY", "100", 250, Money{3, "JPY"}},
+		{"exponent 0 below half rounds down", "JPY", "100", 149, Money{1, "JPY"}},
+		{"exponent 0 negative half rounds away from zero", "JPY", "100", -150, Money{-2, "JPY"}},
+		{"lowercase currency", "usd", "15000", 3000000, Money{20000, "USD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(tt.currency, tt.rate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := converter.Convert(IDR(tt.amount)); got != tt.want {
+				t.Errorf("Convert(%d) = %+v, want %+v", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertUnchanged(t *testing.T) {
+	amount := IDR(1500000)
+	if got := (Converter{}).Convert(amount); got != amount {
+		t.Errorf("zero Converter changed %v to %v", amount, got)
+	}
+
+	converter, _ := NewConverter("USD", "15000")
+	usd := Money{Amount: 995, Currency: "USD"}
+	if got := converter.Convert(usd); got != usd {
+		t.Errorf("Convert() of the same currency = %v, want %v", got, usd)
+	}
+}
+
+func TestNewConverterErrors(t *testing.T) {
+	tests := []struct {
+		currency string
+		rate     string
+		want     error
+	}{
+		{"XYZ", "1", ErrUnsupportedCurrency},
+		{"", "1", ErrUnsupportedCurrency},
+		{"USD", "0", ErrInvalidRate},
+		{"USD", "-15000", ErrInvalidRate},
+		{"USD", "1/3", ErrInvalidRate},
+		{"USD", "1e4", ErrInvalidRate},
+		{"USD", "abc", ErrInvalidRate},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewConverter(tt.currency, tt.rate); err != tt.want {
+			t.Errorf("NewConverter(%q, %q) error = %v, want %v", tt.currency, tt.rate, err, tt.want)
+		}
+	}
+
+	converter, err := NewConverter(" usd ", " 15650.25 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converter.Currency != "USD" || converter.Rate != "15650.25000000" {
+		t.Errorf("NewConverter() = %s %s, want USD 15650.25000000", converter.Currency, converter.Rate)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		money       Money
+		wantDecimal string
+		wantString  string
+	}{
+		{IDR(0), "0", "IDR 0"},
+		{IDR(100), "100", "IDR 100"},
+		{IDR(1500000), "1500000", "IDR 1,500,000"},
+		{IDR(-250000), "-250000", "IDR -250,000"},
+		{Money{9512, "USD"}, "95.12", "USD 95.12"},
+		{Money{5, "USD"}, "0.05", "USD 0.05"},
+		{Money{-123456, "USD"}, "-1234.56", "USD -1,234.56"},
+		{Money{1000, "JPY"}, "1000", "JPY 1,000"},
+		{Money{150, "XYZ"}, "1.50", "XYZ 1.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.wantDecimal {
+			t.Errorf("%+v Decimal() = %q, want %q", tt.money, got, tt.wantDecimal)
+		}
+		if got := tt.money.String(); got != tt.wantString {
+			t.Errorf("%+v String() = %q, want %q", tt.money, got, tt.wantString)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(Money{Amount: 9512, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"amount":9512,"currency":"USD","decimal":"95.12","formatted":"USD 95.12"}`
+	if string(b) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,197 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"project/pkg/oidc"
+	"project/pkg/oidc/oidctest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const redirectURL = "https://app.example.com/api/v1/oidc/mock/callback"
+
+func TestAuthCodeURL(t *testing.T) {
+	server := oidctest.NewServer("client-1")
+	defer server.Close()
+
+	provider := oidc.NewProvider(server.Config(redirectURL))
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, server.URL+"/authorize?") {
+		t.Fatalf("AuthCodeURL() = %s, want provider authorization endpoint", authURL)
+	}
+
+	u, _ := url.Parse(authURL)
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-1",
+		"redirect_uri":          redirectURL,
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        oidc.CodeChallenge("verifier-1"),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := u.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// base64url sha256 dari verifier tanpa padding "="
+	got := oidc.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW-gFrFWEjXk")
+	if want := "cNDv_FcHE0x2alQTkj7qnUtMEsrC5qm5cLOLavG2Ko8"; got != want {
+		t.Errorf("CodeChallenge() = %s, want %s", got, want)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		// verifier yang dikirim saat menukar code, kosong berarti verifier yang benar
+		verifier string
+		// nonce yang diharapkan client, kosong berarti nonce yang benar
+		nonce     string
+		want      oidc.Identity
+		wantError string
+	}{
+		{
+			name: "valid",
+			want: oidc.Identity{Subject: "subject-1", Email: "user@example.com", EmailVerified: true},
+		},
+		{
+			name:   "email_verified as string",
+			claims: jwt.MapClaims{"email_verified": "true", "name": "Budi"},
+			want:   oidc.Identity{Subject: "subject-1", Email: "user@example.com", EmailVerified: true, Name: "Budi"},
+		},
+		{
+			name:   "unverified email",
+			claims: jwt.MapClaims{"email_verified": false},
+			want:   oidc.Identity{Subject: "subject-1", Email: "user@example.com"},
+		},
+		{
+			name:      "PKCE verifier mismatch",
+			verifier:  "another-verifier",
+			wantError: "status 400",
+		},
+		{
+			name:      "nonce mismatch",
+			nonce:     "another-nonce",
+			wantError: "nonce mismatch",
+		},
+		{
+			name:      "missing nonce",
+			claims:    jwt.MapClaims{"nonce": nil},
+			wantError: "nonce mismatch",
+		},
+		{
+			name:      "audience mismatch",
+			claims:    jwt.MapClaims{"aud": "client-2"},
+			wantError: "audience mismatch",
+		},
+		{
+			name:      "authorized party mismatch",
+			claims:    jwt.MapClaims{"aud": []string{"client-1", "client-2"}, "azp": "client-2"},
+			wantError: "authorized party mismatch",
+		},
+		{
+			name:      "issuer mismatch",
+			claims:    jwt.MapClaims{"iss": "https://evil.example.com"},
+			wantError: "issuer mismatch",
+		},
+		{
+			name:      "expired",
+			claims:    jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()},
+			wantError: "expired",
+		},
+		{
+			name:      "missing subject",
+			claims:    jwt.MapClaims{"sub": nil},
+			wantError: "missing subject",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := oidctest.NewServer("client-1")
+			defer server.Close()
+			provider := oidc.NewProvider(server.Config(redirectURL))
+
+			ctx := context.Background()
+			authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, state, err := server.Authorize(authURL, tt.claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state != "state-1" {
+				t.Fatalf("state = %q, want state-1", state)
+			}
+
+			verifier, nonce := "verifier-1", "nonce-1"
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			identity, err := provider.Exchange(ctx, code, verifier, nonce)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("Exchange() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if identity != tt.want {
+				t.Errorf("Exchange() = %+v, want %+v", identity, tt.want)
+			}
+
+			// code hanya bisa ditukar sekali
+			if _, err := provider.Exchange(ctx, code, verifier, nonce); err == nil {
+				t.Error("Exchange() accepted a used code")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsForeignKey(t *testing.T) {
+	server := oidctest.NewServer("client-1")
+	defer server.Close()
+	other := oidctest.NewServer("client-1")
+	defer other.Close()
+
+	provider := oidc.NewProvider(server.Config(redirectURL))
+	claims := jwt.MapClaims{"iss": server.URL, "aud": "client-1", "sub": "subject-1", "nonce": "nonce-1", "exp": time.Now().Add(time.Minute).Unix()}
+
+	if _, err := provider.VerifyIDToken(context.Background(), server.IDToken(claims), "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), other.IDToken(claims), "nonce-1"); err == nil {
+		t.Error("VerifyIDToken() accepted a token signed by another key")
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	server := oidctest.NewServer("client-1")
+	defer server.Close()
+
+	config := server.Config(redirectURL)
+	config.Issuer = strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	if _, err := oidc.NewProvider(config).Discover(context.Background()); err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Errorf("Discover() error = %v, want issuer mismatch", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package oidctest menyediakan provider OpenID Connect palsu (discovery, jwks dan token endpoint) untuk test
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"project/pkg/oidc"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// kid key yang dipakai Server untuk menandatangani id token
+const KeyId = "test"
+
+// Server adalah provider palsu. authorization code dibuat lewat Authorize lalu ditukar di token endpoint
+// dengan pengecekan client_id, redirect_uri dan PKCE seperti provider sungguhan
+type Server struct {
+	*httptest.Server
+	ClientID string
+	Key      *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+type grant struct {
+	challenge   string
+	redirectURI string
+	claims      jwt.MapClaims
+}
+
+func NewServer(clientID string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{ClientID: clientID, Key: key, grants: map[string]grant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Config mengembalikan konfigurasi provider yang mengarah ke Server
+func (s *Server) Config(redirectURL string) oidc.Config {
+	return oidc.Config{Name: "mock", Issuer: s.URL, ClientID: s.ClientID, RedirectURL: redirectURL, Scopes: []string{"openid", "email"}}
+}
+
+// Authorize berperan sebagai halaman login provider untuk authURL dari AuthCodeURL. claims ditambahkan ke id token
+// (nilai nil menghapus claim default). mengembalikan authorization code dan state yang dikirim balik ke callback
+func (s *Server) Authorize(authURL string, claims jwt.MapClaims) (code string, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := u.Query()
+	if query.Get("client_id") != s.ClientID || query.Get("response_type") != "code" {
+		return "", "", errors.New("oidctest: invalid authorization request")
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", "", errors.New("oidctest: missing PKCE challenge")
+	}
+
+	idClaims := jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            s.ClientID,
+		"sub":            "subject-1",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"nonce":          query.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute * 5).Unix(),
+	}
+	for key, value := range claims {
+		if value == nil {
+			delete(idClaims, key)
+			continue
+		}
+		idClaims[key] = value
+	}
+
+	code, err = oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	s.mu.Lock()
+	s.grants[code] = grant{challenge: query.Get("code_challenge"), redirectURI: query.Get("redirect_uri"), claims: idClaims}
+	s.mu.Unlock()
+
+	return code, query.Get("state"), nil
+}
+
+// IDToken menandatangani claims dengan key Server
+func (s *Server) IDToken(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyId
+	signed, err := token.SignedString(s.Key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	key := s.Key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": KeyId,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+}
+
+// token menukar authorization code, setiap code hanya bisa dipakai sekali
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	g, ok := s.grants[r.PostForm.Get("code")]
+	delete(s.grants, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	if !ok || r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("redirect_uri") != g.redirectURI ||
+		oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": s.IDToken(g.claims)})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
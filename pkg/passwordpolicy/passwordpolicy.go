@@ -0,0 +1,158 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// bcrypt hanya memakai 72 byte pertama password
+const MaxLength = 72
+
+var (
+	ErrTooShort = errors.New("password is too short")
+	ErrTooLong  = errors.New("password is too long")
+	ErrTooWeak  = errors.New("password is too weak")
+	ErrBreached = errors.New("password is too common and appears in breached password lists")
+	ErrPersonal = errors.New("password must not contain your name or email")
+)
+
+//go:embed breached.txt
+var builtinBreached string
+
+var (
+	breachedOnce sync.Once
+	breached     map[string]bool
+)
+
+// Policy adalah aturan kekuatan password
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// function FromEnv membaca policy dari env PASSWORD_MIN_LENGTH (default 8) dan
+// PASSWORD_REQUIRE_UPPER, PASSWORD_REQUIRE_LOWER, PASSWORD_REQUIRE_DIGIT, PASSWORD_REQUIRE_SYMBOL (true/false, default false)
+func FromEnv() Policy {
+	policy := Policy{MinLength: 8}
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && n > 0 && n <= MaxLength {
+		policy.MinLength = n
+	}
+	policy.RequireUpper = envBool("PASSWORD_REQUIRE_UPPER")
+	policy.RequireLower = envBool("PASSWORD_REQUIRE_LOWER")
+	policy.RequireDigit = envBool("PASSWORD_REQUIRE_DIGIT")
+	policy.RequireSymbol = envBool("PASSWORD_REQUIRE_SYMBOL")
+	return policy
+}
+
+func envBool(key string) bool {
+	value, _ := strconv.ParseBool(os.Getenv(key))
+	return value
+}
+
+// function Validate mengecek password dengan policy dari env. personal berisi data user (nama, email)
+// yang tidak boleh dipakai di dalam password
+func Validate(password string, personal ...string) error {
+	return FromEnv().Validate(password, personal...)
+}
+
+func (p Policy) Validate(password string, personal ...string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w, use at least %d characters", ErrTooShort, p.MinLength)
+	}
+	if len(password) > MaxLength {
+		return fmt.Errorf("%w, use at most %d bytes", ErrTooLong, MaxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w, add %s", ErrTooWeak, strings.Join(missing, ", "))
+	}
+
+	if IsBreached(password) {
+		return ErrBreached
+	}
+
+	lowered := strings.ToLower(password)
+	for _, value := range personal {
+		// untuk email yang dicek hanya bagian sebelum @
+		value = strings.ToLower(strings.TrimSpace(strings.SplitN(value, "@", 2)[0]))
+		if len(value) >= 3 && strings.Contains(lowered, value) {
+			return ErrPersonal
+		}
+	}
+
+	return nil
+}
+
+// function IsBreached mengecek password terhadap daftar password bocor bawaan dan file PASSWORD_BREACHED_FILE (jika ada)
+func IsBreached(password string) bool {
+	breachedOnce.Do(loadBreached)
+	return breached[strings.ToLower(password)]
+}
+
+func loadBreached() {
+	breached = map[string]bool{}
+	addBreached(bufio.NewScanner(strings.NewReader(builtinBreached)))
+
+	path := os.Getenv("PASSWORD_BREACHED_FILE")
+	if path == "" {
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		log.Println("password breached list:", err)
+		return
+	}
+	defer file.Close()
+
+	addBreached(bufio.NewScanner(file))
+}
+
+func addBreached(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		log.Println("password breached list:", err)
+	}
+}
@@ -0,0 +1,164 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"project/models"
+	"project/pkg/money"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+const brand = "dewetour"
+
+// Ticket adalah e-ticket untuk satu penumpang. Code adalah isi QR code (referensi booking yang ditandatangani)
+type Ticket struct {
+	Reference string
+	Code      string
+	Passenger string
+	Seat      int
+	Seats     int
+}
+
+// function Invoice membuat pdf invoice dari nilai yang tersimpan di invoice dan baris harga transaction
+func Invoice(invoice models.Invoice, transaction models.Transaction) ([]byte, error) {
+	doc := gofpdf.New("P", "mm", "A4", "")
+	text := doc.UnicodeTranslatorFromDescriptor("")
+	title, status := "INVOICE", "PAID"
+	switch invoice.Kind {
+	case models.InvoiceAdjustment:
+		title = "ADJUSTMENT INVOICE"
+	case models.InvoiceCreditNote:
+		title, status = "CREDIT NOTE", "CREDITED"
+	}
+	doc.SetTitle("Invoice "+invoice.Number, true)
+	doc.AddPage()
+
+	header(doc, title)
+	doc.SetFont("Helvetica", "", 10)
+	row(doc, "Invoice number", invoice.Number)
+	if invoice.ReferenceNumber != "" {
+		row(doc, "Adjusts invoice", invoice.ReferenceNumber)
+	}
+	row(doc, "Issued", invoice.IssuedAt.Format("2 January 2006"))
+	row(doc, "Booking", fmt.Sprintf("#%d", transaction.Id))
+	row(doc, "Billed to", text(invoice.BilledName)+" <"+invoice.BilledEmail+">")
+	row(doc, "Trip", text(transaction.Trip.Title)+", "+transaction.Trip.DateTrip.Format("2 January 2006"))
+	row(doc, "Status", status)
+	doc.Ln(6)
+
+	doc.SetFont("Helvetica", "B", 10)
+	doc.SetFillColor(240, 240, 240)
+	doc.CellFormat(95, 8, "Description", "1", 0, "L", true, 0, "")
+	doc.CellFormat(15, 8, "Qty", "1", 0, "R", true, 0, "")
+	doc.CellFormat(40, 8, "Unit price", "1", 0, "R", true, 0, "")
+	doc.CellFormat(40, 8, "Amount", "1", 1, "R", true, 0, "")
+
+	// invoice lama tanpa salinan rincian memakai rincian transaction
+	items := transaction.Items
+	if invoice.Items != "" {
+		if err := json.Unmarshal([]byte(invoice.Items), &items); err != nil {
+			return nil, err
+		}
+	}
+
+	doc.SetFont("Helvetica", "", 10)
+	for _, item := range items {
+		description := item.Description
+		if item.Code != "" && item.Kind == models.ItemDiscount {
+			description = item.Code + " - " + description
+		}
+		doc.CellFormat(95, 8, text(description), "1", 0, "L", false, 0, "")
+		doc.CellFormat(15, 8, fmt.Sprint(item.Quantity), "1", 0, "R", false, 0, "")
+		doc.CellFormat(40, 8, money.IDR(int64(item.UnitAmount)).String(), "1", 0, "R", false, 0, "")
+		doc.CellFormat(40, 8, money.IDR(int64(item.Amount)).String(), "1", 1, "R", false, 0, "")
+	}
+	doc.Ln(4)
+
+	total(doc, "Subtotal", invoice.Subtotal, false)
+	total(doc, "Discount", money.Money{Amount: -invoice.Discount.Amount, Currency: invoice.Discount.Currency}, false)
+	total(doc, "Service fee", invoice.Fees, false)
+	total(doc, "Tax", invoice.Tax, false)
+	total(doc, "Grand total", invoice.Total, true)
+
+	doc.Ln(10)
+	doc.SetFont("Helvetica", "I", 8)
+	doc.MultiCell(0, 4, "All amounts are in Indonesian Rupiah (IDR). This invoice was generated electronically and is valid without a signature.", "", "L", false)
+
+	return output(doc)
+}
+
+// function Tickets membuat pdf e-ticket, satu halaman untuk setiap penumpang
+func Tickets(transaction models.Transaction, tickets []Ticket) ([]byte, error) {
+	doc := gofpdf.New("P", "mm", "A4", "")
+	text := doc.UnicodeTranslatorFromDescriptor("")
+	doc.SetTitle(fmt.Sprintf("E-ticket booking #%d", transaction.Id), true)
+
+	for i, ticket := range tickets {
+		png, err := qrcode.Encode(ticket.Code, qrcode.Medium, 512)
+		if err != nil {
+			return nil, err
+		}
+		image := fmt.Sprintf("qr-%d", i)
+		doc.RegisterImageOptionsReader(image, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		doc.AddPage()
+		header(doc, "E-TICKET")
+		doc.SetFont("Helvetica", "B", 14)
+		doc.MultiCell(0, 8, text(transaction.Trip.Title), "", "L", false)
+		doc.Ln(2)
+
+		doc.SetFont("Helvetica", "", 10)
+		row(doc, "Passenger", text(ticket.Passenger))
+		row(doc, "Reference", ticket.Reference)
+		row(doc, "Seat", fmt.Sprintf("%d of %d", ticket.Seat, ticket.Seats))
+		row(doc, "Destination", text(transaction.Trip.Country.Name))
+		row(doc, "Departure", transaction.Trip.DateTrip.Format("Monday, 2 January 2006"))
+		row(doc, "Duration", fmt.Sprintf("%d days %d nights", transaction.Trip.Day, transaction.Trip.Night))
+		row(doc, "Transportation", text(transaction.Trip.Transportation))
+		row(doc, "Accomodation", text(transaction.Trip.Accomodation))
+
+		doc.ImageOptions(image, 65, doc.GetY()+8, 80, 80, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		doc.SetY(doc.GetY() + 92)
+		doc.SetFont("Helvetica", "I", 8)
+		doc.CellFormat(0, 4, "Show this QR code to your guide at check-in. Each code is valid for one passenger.", "", 1, "C", false, 0, "")
+	}
+
+	return output(doc)
+}
+
+func header(doc *gofpdf.Fpdf, title string) {
+	doc.SetFont("Helvetica", "B", 20)
+	doc.CellFormat(95, 12, brand, "", 0, "L", false, 0, "")
+	doc.SetFont("Helvetica", "B", 16)
+	doc.CellFormat(95, 12, title, "", 1, "R", false, 0, "")
+	doc.Line(10, doc.GetY()+1, 200, doc.GetY()+1)
+	doc.Ln(6)
+}
+
+func row(doc *gofpdf.Fpdf, label string, value string) {
+	doc.SetFont("Helvetica", "B", 10)
+	doc.CellFormat(40, 6, label, "", 0, "L", false, 0, "")
+	doc.SetFont("Helvetica", "", 10)
+	doc.CellFormat(0, 6, value, "", 1, "L", false, 0, "")
+}
+
+func total(doc *gofpdf.Fpdf, label string, amount money.Money, bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	doc.SetFont("Helvetica", style, 10)
+	doc.CellFormat(150, 7, label, "", 0, "R", false, 0, "")
+	doc.CellFormat(40, 7, amount.String(), "", 1, "R", false, 0, "")
+}
+
+func output(doc *gofpdf.Fpdf) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := doc.Output(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// impersonationRoutes adalah route (method dan path tanpa /api/v1) yang boleh dipanggil dengan token impersonation,
+// yaitu melihat data user dan membantu mengelola traveler, dokumen, wishlist dan waitlist. route yang tidak ada di daftar
+// ini ditolak, termasuk mengganti kredensial (password, email, 2FA, api key, sesi, penghapusan akun), semua route yang
+// memindahkan uang (booking, pembayaran, reschedule, pembatalan) dan route baru yang belum ditinjau
+var impersonationRoutes = map[string]bool{
+	"GET /check_auth":                true,
+	"GET /user":                      true,
+	"POST /resend_verification":      true,
+	"GET /api_keys":                  true,
+	"GET /sessions":                  true,
+	"GET /transactionsbyuser":        true,
+	"GET /transaction/{id}":          true,
+	"GET /transaction/{id}/history":  true,
+	"GET /transaction/{id}/invoice":  true,
+	"GET /transaction/{id}/ticket":   true,
+	"POST /trip/{id}/quote":          true,
+	"GET /travelers":                 true,
+	"POST /traveler":                 true,
+	"GET /traveler/{id}":             true,
+	"PATCH /traveler/{id}":           true,
+	"DELETE /traveler/{id}":          true,
+	"GET /documents":                 true,
+	"POST /documents":                true,
+	"DELETE /document/{id}":          true,
+	"GET /document/{id}/access_logs": true,
+	"GET /wishlist":                  true,
+	"POST /wishlist":                 true,
+	"PATCH /wishlist/{trip_id}":      true,
+	"DELETE /wishlist/{trip_id}":     true,
+	"GET /waitlist":                  true,
+	"POST /trip/{id}/waitlist":       true,
+	"DELETE /trip/{id}/waitlist":     true,
+}
+
+// ImpersonationAllowed mengecek apakah route boleh dipanggil saat admin sedang impersonate user
+func ImpersonationAllowed(method string, pathTemplate string) bool {
+	return impersonationRoutes[method+" "+strings.TrimPrefix(pathTemplate, "/api/v1")]
+}
+
+// ImpersonatorId mengembalikan id admin jika token adalah token impersonation (claim "imp"), selain itu 0
+func ImpersonatorId(claims jwt.MapClaims) int {
+	id, _ := claims["imp"].(float64)
+	return int(id)
+}
@@ -0,0 +1,31 @@
+package policy
+
+import "testing"
+
+func TestImpersonationAllowed(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{"GET", "/api/v1/transaction/{id}", true},
+		{"GET", "/api/v1/transaction/{id}/ticket", true},
+		{"PATCH", "/api/v1/traveler/{id}", true},
+		{"POST", "/api/v1/transaction", false},
+		{"PATCH", "/api/v1/transaction/{id_transaction}", false},
+		{"DELETE", "/api/v1/transaction/{id}", false},
+		{"POST", "/api/v1/transaction/{id}/reschedule", false},
+		{"POST", "/api/v1/password/change", false},
+		{"DELETE", "/api/v1/session/{id}", false},
+		{"POST", "/api/v1/user/{id}/impersonate", false},
+		{"GET", "/api/v1/me/export", false},
+		// route yang belum terdaftar ditolak
+		{"POST", "/api/v1/transaction/{id}/refund", false},
+	}
+
+	for _, tt := range tests {
+		if got := ImpersonationAllowed(tt.method, tt.path); got != tt.want {
+			t.Errorf("ImpersonationAllowed(%s %s) = %t, want %t", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	dto "project/dto"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// role yang dikenal oleh aplikasi
+const (
+	RoleAdmin = "admin"
+	RoleGuide = "guide"
+	RoleUser  = "user"
+)
+
+// Permission adalah hak akses terhadap resource milik user lain
+type Permission string
+
+const (
+	UserReadAny          Permission = "user:read:any"
+	UserUpdateAny        Permission = "user:update:any"
+	UserDeleteAny        Permission = "user:delete:any"
+	UserRoleAny          Permission = "user:role:any"
+	UserImpersonate      Permission = "user:impersonate"
+	AuditReadAny         Permission = "audit:read:any"
+	TransactionReadAny   Permission = "transaction:read:any"
+	TransactionUpdateAny Permission = "transaction:update:any"
+	TransactionDeleteAny Permission = "transaction:delete:any"
+	SessionRevokeAny     Permission = "session:revoke:any"
+	APIKeyManageAny      Permission = "api_key:manage:any"
+	DocumentReadAny      Permission = "document:read:any"
+	DocumentDeleteAny    Permission = "document:delete:any"
+	WaitlistReadAny      Permission = "waitlist:read:any"
+	PromoCodeManage      Permission = "promo_code:manage"
+	ExchangeRateManage   Permission = "exchange_rate:manage"
+	TaxRuleManage        Permission = "tax_rule:manage"
+	CheckInWrite         Permission = "check_in:write"
+	RosterRead           Permission = "roster:read"
+	TripWrite            Permission = "trip:write"
+	CountryWrite         Permission = "country:write"
+)
+
+// daftar permission untuk setiap role. user biasa hanya boleh mengakses resource miliknya sendiri
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		UserReadAny,
+		UserUpdateAny,
+		UserDeleteAny,
+		UserRoleAny,
+		UserImpersonate,
+		AuditReadAny,
+		TransactionReadAny,
+		TransactionUpdateAny,
+		TransactionDeleteAny,
+		SessionRevokeAny,
+		APIKeyManageAny,
+		DocumentReadAny,
+		DocumentDeleteAny,
+		WaitlistReadAny,
+		PromoCodeManage,
+		ExchangeRateManage,
+		TaxRuleManage,
+		TripWrite,
+		CountryWrite,
+		CheckInWrite,
+		RosterRead,
+	},
+	// tour guide hanya boleh check-in penumpang dan melihat daftar penumpang keberangkatan
+	RoleGuide: {
+		CheckInWrite,
+		RosterRead,
+	},
+	RoleUser: {},
+}
+
+var (
+	ErrForbidden = errors.New("forbidden")
+	ErrNotFound  = errors.New("not found")
+)
+
+// Subject adalah identitas pemanggil yang diambil dari jwt claims
+type Subject struct {
+	Id   int
+	Role string
+	// id admin jika request memakai token impersonation
+	ImpersonatorId int
+}
+
+// FromClaims membuat Subject dari claims yang disimpan middleware di context
+func FromClaims(claims jwt.MapClaims) Subject {
+	var subject Subject
+	if id, ok := claims["id"].(float64); ok {
+		subject.Id = int(id)
+	}
+	if role, ok := claims["role"].(string); ok {
+		subject.Role = role
+	}
+	subject.ImpersonatorId = ImpersonatorId(claims)
+	return subject
+}
+
+// FromRequest mengambil Subject dari context "userInfo". jika request tidak melewati middleware Auth maka Subject kosong
+func FromRequest(r *http.Request) Subject {
+	claims, ok := r.Context().Value("userInfo").(jwt.MapClaims)
+	if !ok {
+		return Subject{}
+	}
+	return FromClaims(claims)
+}
+
+// Has mengecek apakah role subject memiliki permission tertentu
+func (s Subject) Has(permission Permission) bool {
+	for _, p := range rolePermissions[s.Role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize mengizinkan pemilik resource, atau subject yang memiliki permission
+func Authorize(s Subject, permission Permission, ownerId int) error {
+	if s.Id == 0 {
+		return ErrForbidden
+	}
+	if s.Id == ownerId || s.Has(permission) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// Deny menulis response penolakan yang seragam untuk semua endpoint.
+// ErrNotFound menjadi 404, ErrForbidden menjadi 403, error lainnya menjadi 500
+func Deny(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	code := http.StatusInternalServerError
+	message := err.Error()
+	if errors.Is(err, ErrNotFound) {
+		code = http.StatusNotFound
+		message = "not found"
+	} else if errors.Is(err, ErrForbidden) {
+		code = http.StatusForbidden
+		message = "forbidden"
+	}
+
+	w.WriteHeader(code)
+	response := dto.ErrorResult{Code: code, Message: message}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,59 @@
+package policy
+
+import "strings"
+
+// Scope membatasi endpoint yang boleh dipanggil dengan api key
+type Scope string
+
+const (
+	ScopeProfileRead       Scope = "profile:read"
+	ScopeTransactionsRead  Scope = "transactions:read"
+	ScopeTransactionsWrite Scope = "transactions:write"
+	ScopeTripsWrite        Scope = "trips:write"
+	ScopeUsersRead         Scope = "users:read"
+)
+
+// Scopes adalah daftar scope yang boleh diminta saat membuat api key
+var Scopes = []Scope{
+	ScopeProfileRead,
+	ScopeTransactionsRead,
+	ScopeTransactionsWrite,
+	ScopeTripsWrite,
+	ScopeUsersRead,
+}
+
+// routeScopes memetakan route (method dan path tanpa /api/v1) ke scope yang dibutuhkan.
+// route yang tidak ada di daftar ini tidak bisa dipanggil dengan api key (misal membuat api key, ganti password)
+var routeScopes = map[string]Scope{
+	"GET /check_auth":                     ScopeProfileRead,
+	"GET /user":                           ScopeProfileRead,
+	"GET /transactionsbyuser":             ScopeTransactionsRead,
+	"GET /transaction/{id}":               ScopeTransactionsRead,
+	"POST /transaction":                   ScopeTransactionsWrite,
+	"PATCH /transaction/{id_transaction}": ScopeTransactionsWrite,
+	"DELETE /transaction/{id}":            ScopeTransactionsWrite,
+	"GET /transactions":                   ScopeTransactionsRead,
+	"POST /trip":                          ScopeTripsWrite,
+	"PATCH /trip/{id}":                    ScopeTripsWrite,
+	"DELETE /trip/{id}":                   ScopeTripsWrite,
+	"POST /country":                       ScopeTripsWrite,
+	"PATCH /country/{id}":                 ScopeTripsWrite,
+	"DELETE /country/{id}":                ScopeTripsWrite,
+	"GET /users":                          ScopeUsersRead,
+}
+
+// RouteScope mengembalikan scope yang dibutuhkan sebuah route, ok false jika route tidak boleh dipanggil dengan api key
+func RouteScope(method string, pathTemplate string) (Scope, bool) {
+	scope, ok := routeScopes[method+" "+strings.TrimPrefix(pathTemplate, "/api/v1")]
+	return scope, ok
+}
+
+// ValidScope mengecek apakah scope dikenal
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if string(s) == scope {
+			return true
+		}
+	}
+	return false
+}
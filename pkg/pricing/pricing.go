@@ -0,0 +1,24 @@
+package pricing
+
+import "project/models"
+
+// Error adalah alasan booking tidak bisa dihitung (promo code, add-on, dsb), pesannya aman ditampilkan ke user
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	ErrAddOnNotFound Error = "add-on not found"
+	ErrAddOnSoldOut  Error = "add-on is sold out"
+)
+
+// function Sum menjumlahkan Amount semua baris harga
+func Sum(items []models.TransactionItem) int {
+	var total int
+	for _, item := range items {
+		total += item.Amount
+	}
+	return total
+}
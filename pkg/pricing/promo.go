@@ -0,0 +1,109 @@
+package pricing
+
+import (
+	"fmt"
+	"project/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ErrPromoNotFound   Error = "promo code not found"
+	ErrPromoInactive   Error = "promo code is not active"
+	ErrPromoNotStarted Error = "promo code is not valid yet"
+	ErrPromoExpired    Error = "promo code has expired"
+	ErrPromoUsedUp     Error = "promo code has reached its usage limit"
+	ErrPromoUserLimit  Error = "you have already used this promo code"
+	ErrPromoNotForTrip Error = "promo code is not valid for this trip"
+)
+
+// PromoContext adalah data booking yang dipakai untuk memeriksa promo code
+type PromoContext struct {
+	TripId    int
+	CountryId int
+	Subtotal  int
+	// jumlah booking user yang sudah memakai promo code ini
+	UserUses int
+	Now      time.Time
+}
+
+// function CheckPromo memastikan promo code boleh dipakai untuk booking
+func CheckPromo(promo models.PromoCode, ctx PromoContext) error {
+	if !promo.Active {
+		return ErrPromoInactive
+	}
+	if promo.StartsAt != nil && ctx.Now.Before(*promo.StartsAt) {
+		return ErrPromoNotStarted
+	}
+	if promo.EndsAt != nil && !ctx.Now.Before(*promo.EndsAt) {
+		return ErrPromoExpired
+	}
+	if promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit {
+		return ErrPromoUsedUp
+	}
+	if promo.PerUserLimit > 0 && ctx.UserUses >= promo.PerUserLimit {
+		return ErrPromoUserLimit
+	}
+	if !inIds(promo.TripIds, ctx.TripId) || !inIds(promo.CountryIds, ctx.CountryId) {
+		return ErrPromoNotForTrip
+	}
+	if ctx.Subtotal < promo.MinSpend {
+		return Error(fmt.Sprintf("spend at least Rp %d to use this promo code", promo.MinSpend))
+	}
+	return nil
+}
+
+// function PromoDiscount menghitung potongan promo dari subtotal. potongan tidak pernah melebihi subtotal
+func PromoDiscount(promo models.PromoCode, subtotal int) int {
+	var discount int
+	switch promo.DiscountType {
+	case models.DiscountPercentage:
+		discount = subtotal * promo.Value / 100
+		if promo.MaxDiscount > 0 && discount > promo.MaxDiscount {
+			discount = promo.MaxDiscount
+		}
+	case models.DiscountFixed:
+		discount = promo.Value
+	}
+
+	if discount > subtotal {
+		discount = subtotal
+	}
+	if discount < 0 {
+		discount = 0
+	}
+	return discount
+}
+
+// function PromoDescription membuat keterangan potongan untuk rincian harga, misal "10% off (max Rp 100000)"
+func PromoDescription(promo models.PromoCode) string {
+	if promo.Description != "" {
+		return promo.Description
+	}
+	if promo.DiscountType == models.DiscountPercentage {
+		if promo.MaxDiscount > 0 {
+			return fmt.Sprintf("%d%% off (max Rp %d)", promo.Value, promo.MaxDiscount)
+		}
+		return fmt.Sprintf("%d%% off", promo.Value)
+	}
+	return fmt.Sprintf("Rp %d off", promo.Value)
+}
+
+// function NormalizeCode menyamakan format promo code, tidak membedakan huruf besar kecil
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// inIds memeriksa id ada di daftar id yang dipisah koma, daftar kosong berarti semua id
+func inIds(list string, id int) bool {
+	if strings.TrimSpace(list) == "" {
+		return true
+	}
+	for _, part := range strings.Split(list, ",") {
+		if value, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && value == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	"project/models"
+	"testing"
+)
+
+func TestPromoDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		promo    models.PromoCode
+		subtotal int
+		want     int
+	}{
+		{"percentage", models.PromoCode{DiscountType: models.DiscountPercentage, Value: 10}, 1500000, 150000},
+		{"percentage rounds down", models.PromoCode{DiscountType: models.DiscountPercentage, Value: 15}, 999, 149},
+		{"percentage capped", models.PromoCode{DiscountType: models.DiscountPercentage, Value: 50, MaxDiscount: 100000}, 1000000, 100000},
+		{"fixed", models.PromoCode{DiscountType: models.DiscountFixed, Value: 50000}, 1000000, 50000},
+		{"fixed larger than subtotal", models.PromoCode{DiscountType: models.DiscountFixed, Value: 50000}, 30000, 30000},
+		{"negative value", models.PromoCode{DiscountType: models.DiscountFixed, Value: -5000}, 30000, 0},
+		{"zero subtotal", models.PromoCode{DiscountType: models.DiscountPercentage, Value: 10}, 0, 0},
+		{"unknown type", models.PromoCode{DiscountType: "bogo", Value: 10}, 1000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PromoDiscount(tt.promo, tt.subtotal); got != tt.want {
+				t.Errorf("PromoDiscount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPromo(t *testing.T) {
+	now := *at(10)
+	valid := models.PromoCode{Active: true, StartsAt: at(1), EndsAt: at(20), UsageLimit: 10, UsedCount: 9, PerUserLimit: 2, MinSpend: 100000, TripIds: "3, 7", CountryIds: ""}
+	ctx := PromoContext{TripId: 7, CountryId: 1, Subtotal: 100000, UserUses: 1, Now: now}
+
+	with := func(change func(*models.PromoCode, *PromoContext)) (models.PromoCode, PromoContext) {
+		promo, c := valid, ctx
+		change(&promo, &c)
+		return promo, c
+	}
+
+	tests := []struct {
+		name   string
+		change func(*models.PromoCode, *PromoContext)
+		want   error
+	}{
+		{"valid", func(p *models.PromoCode, c *PromoContext) {}, nil},
+		{"inactive", func(p *models.PromoCode, c *PromoContext) { p.Active = false }, ErrPromoInactive},
+		{"not started", func(p *models.PromoCode, c *PromoContext) { c.Now = *at(1); p.StartsAt = at(2) }, ErrPromoNotStarted},
+		{"starts now", func(p *models.PromoCode, c *PromoContext) { p.StartsAt = at(10) }, nil},
+		{"ends now", func(p *models.PromoCode, c *PromoContext) { p.EndsAt = at(10) }, ErrPromoExpired},
+		{"used up", func(p *models.PromoCode, c *PromoContext) { p.UsedCount = 10 }, ErrPromoUsedUp},
+		{"no usage limit", func(p *models.PromoCode, c *PromoContext) { p.UsageLimit = 0; p.UsedCount = 1000 }, nil},
+		{"user limit reached", func(p *models.PromoCode, c *PromoContext) { c.UserUses = 2 }, ErrPromoUserLimit},
+		{"no user limit", func(p *models.PromoCode, c *PromoContext) { p.PerUserLimit = 0; c.UserUses = 50 }, nil},
+		{"other trip", func(p *models.PromoCode, c *PromoContext) { c.TripId = 4 }, ErrPromoNotForTrip},
+		{"other country", func(p *models.PromoCode, c *PromoContext) { p.CountryIds = "2,3" }, ErrPromoNotForTrip},
+		{"below min spend", func(p *models.PromoCode, c *PromoContext) { c.Subtotal = 99999 }, Error("spend at least Rp 100000 to use this promo code")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promo, c := with(tt.change)
+			if got := CheckPromo(promo, c); got != tt.want {
+				t.Errorf("CheckPromo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	if got := NormalizeCode("  summer10 "); got != "SUMMER10" {
+		t.Errorf("NormalizeCode() = %q, want SUMMER10", got)
+	}
+}
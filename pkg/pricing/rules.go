@@ -0,0 +1,168 @@
+package pricing
+
+import (
+	"fmt"
+	"project/models"
+	"sort"
+	"time"
+)
+
+// Booking adalah data booking yang dipakai untuk menghitung harga
+type Booking struct {
+	TripPrice  int
+	DepartAt   time.Time
+	BookedAt   time.Time
+	Passengers map[string]int
+}
+
+// function Quantity menghitung jumlah semua penumpang
+func (b Booking) Quantity() int {
+	var total int
+	for _, count := range b.Passengers {
+		total += count
+	}
+	return total
+}
+
+// passengerOrder menentukan urutan baris harga agar hasil perhitungan selalu sama
+var passengerOrder = []string{models.PassengerAdult, models.PassengerChild, models.PassengerSenior}
+
+// function ValidPassengerType memeriksa jenis penumpang yang dikenal
+func ValidPassengerType(passengerType string) bool {
+	for _, t := range passengerOrder {
+		if t == passengerType {
+			return true
+		}
+	}
+	return false
+}
+
+// function Evaluate menghitung rincian harga dari aturan harga trip. urutannya selalu sama:
+// harga tiket per jenis penumpang, tambahan peak date, potongan early bird, lalu potongan rombongan.
+// persentase dihitung dari total harga tiket (tidak bertumpuk) dan dibulatkan ke bawah.
+// jika beberapa early bird atau tier berlaku, dipilih potongan terbesar (id terkecil jika sama)
+func Evaluate(rules []models.PricingRule, booking Booking) []models.TransactionItem {
+	rules = append([]models.PricingRule{}, rules...)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Id < rules[j].Id })
+
+	var items []models.TransactionItem
+	var fare int
+	quantity := booking.Quantity()
+
+	for _, passengerType := range passengerOrder {
+		count := booking.Passengers[passengerType]
+		if count <= 0 {
+			continue
+		}
+		unit := booking.TripPrice
+		for _, rule := range rules {
+			if rule.Kind == models.RulePassengerType && rule.PassengerType == passengerType {
+				unit = rule.Amount
+				break
+			}
+		}
+		items = append(items, models.TransactionItem{
+			Kind:        models.ItemFare,
+			Code:        passengerType,
+			Description: fmt.Sprintf("%s fare", passengerType),
+			Quantity:    count,
+			UnitAmount:  unit,
+			Amount:      unit * count,
+		})
+		fare += unit * count
+	}
+
+	for _, rule := range rules {
+		if rule.Kind == models.RulePeakSurcharge && inWindow(rule, booking.DepartAt) {
+			items = append(items, ruleItem(models.ItemSurcharge, rule, fare, quantity, 1))
+		}
+	}
+
+	if rule, ok := bestRule(rules, fare, quantity, func(rule models.PricingRule) bool {
+		return rule.Kind == models.RuleEarlyBird && inWindow(rule, booking.BookedAt)
+	}); ok {
+		items = append(items, ruleItem(models.ItemEarlyBird, rule, fare, quantity, -1))
+	}
+
+	if rule, ok := bestRule(rules, fare, quantity, func(rule models.PricingRule) bool {
+		return rule.Kind == models.RuleQuantityTier && rule.MinQuantity > 0 && quantity >= rule.MinQuantity
+	}); ok {
+		items = append(items, ruleItem(models.ItemGroup, rule, fare, quantity, -1))
+	}
+
+	// total potongan tidak boleh membuat harga negatif
+	var total int
+	for _, item := range items {
+		total += item.Amount
+	}
+	if total < 0 {
+		last := &items[len(items)-1]
+		last.Amount -= total
+		last.Quantity = 1
+		last.UnitAmount = last.Amount
+	}
+	return items
+}
+
+func ruleAmount(rule models.PricingRule, fare int, quantity int) int {
+	if rule.Percent > 0 {
+		return fare * rule.Percent / 100
+	}
+	return rule.Amount * quantity
+}
+
+// ruleLabels adalah keterangan default baris harga jika aturan tidak punya Description
+var ruleLabels = map[string]string{
+	models.ItemSurcharge: "Peak date surcharge",
+	models.ItemEarlyBird: "Early bird discount",
+	models.ItemGroup:     "Group discount",
+}
+
+func ruleItem(kind string, rule models.PricingRule, fare int, quantity int, sign int) models.TransactionItem {
+	item := models.TransactionItem{
+		Kind:        kind,
+		Code:        fmt.Sprintf("rule:%d", rule.Id),
+		Description: rule.Description,
+		Quantity:    1,
+		Amount:      sign * ruleAmount(rule, fare, quantity),
+	}
+	item.UnitAmount = item.Amount
+	if rule.Percent == 0 {
+		item.Quantity = quantity
+		item.UnitAmount = sign * rule.Amount
+	}
+
+	if item.Description == "" {
+		item.Description = ruleLabels[kind]
+		if rule.Percent > 0 {
+			item.Description = fmt.Sprintf("%s %d%%", item.Description, rule.Percent)
+		}
+	}
+	return item
+}
+
+func bestRule(rules []models.PricingRule, fare int, quantity int, match func(models.PricingRule) bool) (models.PricingRule, bool) {
+	var best models.PricingRule
+	found := false
+	for _, rule := range rules {
+		if !match(rule) {
+			continue
+		}
+		if !found || ruleAmount(rule, fare, quantity) > ruleAmount(best, fare, quantity) {
+			best = rule
+			found = true
+		}
+	}
+	return best, found
+}
+
+// inWindow memeriksa waktu ada di antara StartsAt (termasuk) dan EndsAt (tidak termasuk), batas kosong berarti tidak dibatasi
+func inWindow(rule models.PricingRule, at time.Time) bool {
+	if rule.StartsAt != nil && at.Before(*rule.StartsAt) {
+		return false
+	}
+	if rule.EndsAt != nil && !at.Before(*rule.EndsAt) {
+		return false
+	}
+	return true
+}
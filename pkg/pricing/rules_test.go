@@ -0,0 +1,162 @@
+package pricing
+
+import (
+	"project/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func at(day int) *time.Time {
+	t := time.Date(2024, 6, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestEvaluate(t *testing.T) {
+	booking := Booking{
+		TripPrice:  1000000,
+		DepartAt:   *at(20),
+		BookedAt:   *at(1),
+		Passengers: map[string]int{models.PassengerAdult: 2},
+	}
+	withPassengers := func(passengers map[string]int) Booking {
+		b := booking
+		b.Passengers = passengers
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		rules   []models.PricingRule
+		booking Booking
+		want    []models.TransactionItem
+	}{
+		{
+			name:    "trip price without rules",
+			booking: booking,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 2, UnitAmount: 1000000, Amount: 2000000},
+			},
+		},
+		{
+			name: "passenger type prices in fixed order",
+			rules: []models.PricingRule{
+				{Id: 1, Kind: models.RulePassengerType, PassengerType: models.PassengerSenior, Amount: 800000},
+				{Id: 2, Kind: models.RulePassengerType, PassengerType: models.PassengerChild, Amount: 500000},
+			},
+			booking: withPassengers(map[string]int{models.PassengerSenior: 1, models.PassengerChild: 2, models.PassengerAdult: 1}),
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 1, UnitAmount: 1000000, Amount: 1000000},
+				{Kind: models.ItemFare, Code: "child", Description: "child fare", Quantity: 2, UnitAmount: 500000, Amount: 1000000},
+				{Kind: models.ItemFare, Code: "senior", Description: "senior fare", Quantity: 1, UnitAmount: 800000, Amount: 800000},
+			},
+		},
+		{
+			name: "peak surcharge inside window, end is exclusive",
+			rules: []models.PricingRule{
+				{Id: 1, Kind: models.RulePeakSurcharge, Percent: 10, StartsAt: at(15), EndsAt: at(25)},
+				{Id: 2, Kind: models.RulePeakSurcharge, Amount: 50000, StartsAt: at(10), EndsAt: at(20)},
+			},
+			booking: booking,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 2, UnitAmount: 1000000, Amount: 2000000},
+				{Kind: models.ItemSurcharge, Code: "rule:1", Description: "Peak date surcharge 10%", Quantity: 1, UnitAmount: 200000, Amount: 200000},
+			},
+		},
+		{
+			name: "largest early bird wins",
+			rules: []models.PricingRule{
+				{Id: 1, Kind: models.RuleEarlyBird, Percent: 5, EndsAt: at(10)},
+				{Id: 2, Kind: models.RuleEarlyBird, Amount: 150000, Description: "Book early", EndsAt: at(10)},
+				{Id: 3, Kind: models.RuleEarlyBird, Percent: 50, StartsAt: at(5)},
+			},
+			booking: booking,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 2, UnitAmount: 1000000, Amount: 2000000},
+				{Kind: models.ItemEarlyBird, Code: "rule:2", Description: "Book early", Quantity: 2, UnitAmount: -150000, Amount: -300000},
+			},
+		},
+		{
+			name: "tie picks the smallest id regardless of order",
+			rules: []models.PricingRule{
+				{Id: 9, Kind: models.RuleEarlyBird, Percent: 10},
+				{Id: 4, Kind: models.RuleEarlyBird, Amount: 100000},
+			},
+			booking: booking,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 2, UnitAmount: 1000000, Amount: 2000000},
+				{Kind: models.ItemEarlyBird, Code: "rule:4", Description: "Early bird discount", Quantity: 2, UnitAmount: -100000, Amount: -200000},
+			},
+		},
+		{
+			name: "highest matching quantity tier",
+			rules: []models.PricingRule{
+				{Id: 1, Kind: models.RuleQuantityTier, MinQuantity: 3, Percent: 5},
+				{Id: 2, Kind: models.RuleQuantityTier, MinQuantity: 5, Percent: 10},
+				{Id: 3, Kind: models.RuleQuantityTier, MinQuantity: 6, Percent: 20},
+			},
+			booking: withPassengers(map[string]int{models.PassengerAdult: 5}),
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 5, UnitAmount: 1000000, Amount: 5000000},
+				{Kind: models.ItemGroup, Code: "rule:2", Description: "Group discount 10%", Quantity: 1, UnitAmount: -500000, Amount: -500000},
+			},
+		},
+		{
+			name:    "quantity tier below minimum",
+			rules:   []models.PricingRule{{Id: 1, Kind: models.RuleQuantityTier, MinQuantity: 3, Percent: 5}},
+			booking: booking,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 2, UnitAmount: 1000000, Amount: 2000000},
+			},
+		},
+		{
+			name:    "percent rounds down",
+			rules:   []models.PricingRule{{Id: 1, Kind: models.RuleEarlyBird, Percent: 10}},
+			booking: Booking{TripPrice: 999, Passengers: map[string]int{models.PassengerAdult: 1}},
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 1, UnitAmount: 999, Amount: 999},
+				{Kind: models.ItemEarlyBird, Code: "rule:1", Description: "Early bird discount 10%", Quantity: 1, UnitAmount: -99, Amount: -99},
+			},
+		},
+		{
+			name: "discounts are clamped so the total is never negative",
+			rules: []models.PricingRule{
+				{Id: 1, Kind: models.RuleEarlyBird, Amount: 600000},
+				{Id: 2, Kind: models.RuleQuantityTier, MinQuantity: 1, Amount: 600000},
+			},
+			booking: booking,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFare, Code: "adult", Description: "adult fare", Quantity: 2, UnitAmount: 1000000, Amount: 2000000},
+				{Kind: models.ItemEarlyBird, Code: "rule:1", Description: "Early bird discount", Quantity: 2, UnitAmount: -600000, Amount: -1200000},
+				{Kind: models.ItemGroup, Code: "rule:2", Description: "Group discount", Quantity: 1, UnitAmount: -800000, Amount: -800000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate(tt.rules, tt.booking)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Evaluate() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+
+			// setiap baris harus konsisten dan totalnya tidak negatif
+			for _, item := range got {
+				if item.UnitAmount*item.Quantity != item.Amount {
+					t.Errorf("item %s: %d x %d != %d", item.Code, item.UnitAmount, item.Quantity, item.Amount)
+				}
+			}
+			if Sum(got) < 0 {
+				t.Errorf("Sum() = %d, want >= 0", Sum(got))
+			}
+		})
+	}
+}
+
+func TestEvaluateDoesNotReorderRules(t *testing.T) {
+	rules := []models.PricingRule{{Id: 2}, {Id: 1}}
+	Evaluate(rules, Booking{TripPrice: 1, Passengers: map[string]int{models.PassengerAdult: 1}})
+	if rules[0].Id != 2 {
+		t.Error("Evaluate() reordered the caller's rules")
+	}
+}
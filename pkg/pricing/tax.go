@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"fmt"
+	"project/models"
+	"sort"
+)
+
+// Charge adalah data booking yang dipakai untuk memilih dan menghitung pajak serta biaya layanan
+type Charge struct {
+	TripType  string
+	CountryId int
+	Quantity  int
+	// harga yang dibayar setelah semua potongan
+	Payable int
+}
+
+// function TaxRuleApplies memeriksa apakah aturan pajak berlaku untuk jenis dan negara trip
+func TaxRuleApplies(rule models.TaxRule, tripType string, countryId int) bool {
+	if !rule.Active {
+		return false
+	}
+	if rule.TripType != "" && rule.TripType != tripType {
+		return false
+	}
+	return rule.CountryId == 0 || rule.CountryId == countryId
+}
+
+// function Charges menghitung baris biaya layanan lalu pajak. biaya layanan dihitung dari harga setelah potongan,
+// pajak dihitung dari harga setelah potongan ditambah biaya layanan. persentase dibulatkan ke rupiah terdekat
+func Charges(rules []models.TaxRule, charge Charge) []models.TransactionItem {
+	rules = append([]models.TaxRule{}, rules...)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Id < rules[j].Id })
+
+	var items []models.TransactionItem
+	var fees int
+	for _, rule := range rules {
+		if rule.Kind == models.ChargeServiceFee && TaxRuleApplies(rule, charge.TripType, charge.CountryId) {
+			item := chargeItem(models.ItemFee, rule, charge.Payable, charge.Quantity)
+			fees += item.Amount
+			items = append(items, item)
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Kind == models.ChargeTax && TaxRuleApplies(rule, charge.TripType, charge.CountryId) {
+			items = append(items, chargeItem(models.ItemTax, rule, charge.Payable+fees, charge.Quantity))
+		}
+	}
+
+	// baris 0 (misal persentase dari harga 0) tidak perlu ditampilkan
+	result := []models.TransactionItem{}
+	for _, item := range items {
+		if item.Amount > 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// function chargeItem membuat satu baris biaya. biaya tetap per orang tanpa persentase ditulis per orang
+// agar Amount tetap sama dengan UnitAmount x Quantity
+func chargeItem(kind string, rule models.TaxRule, base int, quantity int) models.TransactionItem {
+	description := rule.Name
+	if description == "" {
+		description = kind
+	}
+	if rule.RateBps > 0 {
+		description = fmt.Sprintf("%s (%s%%)", description, bpsLabel(rule.RateBps))
+	}
+
+	if rule.RateBps == 0 && rule.PerPerson {
+		return models.TransactionItem{
+			Kind:        kind,
+			Code:        fmt.Sprintf("%s-%d", kind, rule.Id),
+			Description: description,
+			Quantity:    quantity,
+			UnitAmount:  rule.FixedAmount,
+			Amount:      rule.FixedAmount * quantity,
+		}
+	}
+
+	amount := percentOf(base, rule.RateBps)
+	if rule.PerPerson {
+		amount += rule.FixedAmount * quantity
+	} else {
+		amount += rule.FixedAmount
+	}
+	return models.TransactionItem{
+		Kind:        kind,
+		Code:        fmt.Sprintf("%s-%d", kind, rule.Id),
+		Description: description,
+		Quantity:    1,
+		UnitAmount:  amount,
+		Amount:      amount,
+	}
+}
+
+// function percentOf menghitung basis point dari base, dibulatkan setengah ke atas
+func percentOf(base int, bps int) int {
+	if base <= 0 || bps <= 0 {
+		return 0
+	}
+	return (base*bps + 5000) / 10000
+}
+
+// function bpsLabel menampilkan basis point sebagai persen, misal 1100 -> "11", 110 -> "1.1"
+func bpsLabel(bps int) string {
+	label := fmt.Sprintf("%d.%02d", bps/100, bps%100)
+	for label[len(label)-1] == '0' {
+		label = label[:len(label)-1]
+	}
+	if label[len(label)-1] == '.' {
+		label = label[:len(label)-1]
+	}
+	return label
+}
@@ -0,0 +1,141 @@
+package pricing
+
+import (
+	"project/models"
+	"reflect"
+	"testing"
+)
+
+func TestCharges(t *testing.T) {
+	charge := Charge{TripType: "domestic", CountryId: 1, Quantity: 3, Payable: 1000000}
+
+	tests := []struct {
+		name   string
+		rules  []models.TaxRule
+		charge Charge
+		want   []models.TransactionItem
+	}{
+		{
+			name: "fees before tax, tax includes fees",
+			rules: []models.TaxRule{
+				{Id: 1, Name: "PPN", Kind: models.ChargeTax, RateBps: 1100, Active: true},
+				{Id: 2, Name: "Service fee", Kind: models.ChargeServiceFee, RateBps: 500, Active: true},
+			},
+			charge: charge,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFee, Code: "service_fee-2", Description: "Service fee (5%)", Quantity: 1, UnitAmount: 50000, Amount: 50000},
+				{Kind: models.ItemTax, Code: "tax-1", Description: "PPN (11%)", Quantity: 1, UnitAmount: 115500, Amount: 115500},
+			},
+		},
+		{
+			name: "rules of the same kind in id order",
+			rules: []models.TaxRule{
+				{Id: 5, Name: "Insurance levy", Kind: models.ChargeServiceFee, FixedAmount: 2500, Active: true},
+				{Id: 3, Name: "Platform fee", Kind: models.ChargeServiceFee, FixedAmount: 10000, Active: true},
+			},
+			charge: charge,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFee, Code: "service_fee-3", Description: "Platform fee", Quantity: 1, UnitAmount: 10000, Amount: 10000},
+				{Kind: models.ItemFee, Code: "service_fee-5", Description: "Insurance levy", Quantity: 1, UnitAmount: 2500, Amount: 2500},
+			},
+		},
+		{
+			name:   "fixed per person is itemized per person",
+			rules:  []models.TaxRule{{Id: 1, Name: "Port fee", Kind: models.ChargeServiceFee, FixedAmount: 10000, PerPerson: true, Active: true}},
+			charge: charge,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFee, Code: "service_fee-1", Description: "Port fee", Quantity: 3, UnitAmount: 10000, Amount: 30000},
+			},
+		},
+		{
+			name:   "rate plus fixed per person is one line",
+			rules:  []models.TaxRule{{Id: 1, Name: "Handling", Kind: models.ChargeServiceFee, RateBps: 250, FixedAmount: 1000, PerPerson: true, Active: true}},
+			charge: charge,
+			want: []models.TransactionItem{
+				{Kind: models.ItemFee, Code: "service_fee-1", Description: "Handling (2.5%)", Quantity: 1, UnitAmount: 28000, Amount: 28000},
+			},
+		},
+		{
+			name:   "percent rounds half up",
+			rules:  []models.TaxRule{{Id: 1, Name: "PPN", Kind: models.ChargeTax, RateBps: 1100, Active: true}},
+			charge: Charge{Quantity: 1, Payable: 50},
+			want: []models.TransactionItem{
+				{Kind: models.ItemTax, Code: "tax-1", Description: "PPN (11%)", Quantity: 1, UnitAmount: 6, Amount: 6},
+			},
+		},
+		{
+			name: "inactive, other trip type and other country are skipped",
+			rules: []models.TaxRule{
+				{Id: 1, Kind: models.ChargeTax, RateBps: 1100},
+				{Id: 2, Kind: models.ChargeTax, RateBps: 1000, TripType: "international", Active: true},
+				{Id: 3, Kind: models.ChargeTax, RateBps: 700, CountryId: 2, Active: true},
+				{Id: 4, Kind: models.ChargeTax, RateBps: 100, TripType: "domestic", CountryId: 1, Active: true},
+			},
+			charge: charge,
+			want: []models.TransactionItem{
+				{Kind: models.ItemTax, Code: "tax-4", Description: "tax (1%)", Quantity: 1, UnitAmount: 10000, Amount: 10000},
+			},
+		},
+		{
+			name:   "zero lines are dropped",
+			rules:  []models.TaxRule{{Id: 1, Name: "PPN", Kind: models.ChargeTax, RateBps: 1100, Active: true}},
+			charge: Charge{Quantity: 2, Payable: 0},
+			want:   []models.TransactionItem{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Charges(tt.rules, tt.charge)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Charges() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			for _, item := range got {
+				if item.UnitAmount*item.Quantity != item.Amount {
+					t.Errorf("item %s: %d x %d != %d", item.Code, item.UnitAmount, item.Quantity, item.Amount)
+				}
+			}
+		})
+	}
+}
+
+func TestPercentOf(t *testing.T) {
+	tests := []struct {
+		base, bps, want int
+	}{
+		{1000000, 1100, 110000},
+		{999, 1100, 110},
+		{50, 1100, 6},
+		{45, 1100, 5},
+		{1, 4999, 0},
+		{1, 5000, 1},
+		{0, 1100, 0},
+		{-1000, 1100, 0},
+		{1000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := percentOf(tt.base, tt.bps); got != tt.want {
+			t.Errorf("percentOf(%d, %d) = %d, want %d", tt.base, tt.bps, got, tt.want)
+		}
+	}
+}
+
+func TestBpsLabel(t *testing.T) {
+	tests := []struct {
+		bps  int
+		want string
+	}{
+		{1100, "11"},
+		{110, "1.1"},
+		{1250, "12.5"},
+		{5, "0.05"},
+		{10000, "100"},
+	}
+
+	for _, tt := range tests {
+		if got := bpsLabel(tt.bps); got != tt.want {
+			t.Errorf("bpsLabel(%d) = %q, want %q", tt.bps, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter membatasi jumlah request per key (ip, email, dsb) dalam satu jendela waktu (fixed window)
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string]*counter
+}
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+// function New membuat Limiter yang mengizinkan limit request setiap window
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		hits:   map[string]*counter{},
+	}
+}
+
+// Allow mencatat satu request untuk key dan mengembalikan false jika batas sudah terlewati
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.cleanup(now)
+
+	w, ok := l.hits[key]
+	if !ok || now.After(w.resetAt) {
+		w = &counter{resetAt: now.Add(l.window)}
+		l.hits[key] = w
+	}
+
+	w.count++
+	return w.count <= l.limit
+}
+
+// cleanup menghapus key yang jendela waktunya sudah habis agar map tidak terus membesar
+func (l *Limiter) cleanup(now time.Time) {
+	if len(l.hits) < 1024 {
+		return
+	}
+	for key, w := range l.hits {
+		if now.After(w.resetAt) {
+			delete(l.hits, key)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"project/pkg/encryption"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Store menyimpan file terenkripsi di folder privat (bukan /uploads yang bisa diakses publik)
+type Store struct {
+	Dir string
+}
+
+// function FromEnv membuat Store dari env DOCUMENT_STORAGE_DIR (default ./storage/documents)
+func FromEnv() *Store {
+	dir := os.Getenv("DOCUMENT_STORAGE_DIR")
+	if dir == "" {
+		dir = filepath.Join("storage", "documents")
+	}
+	return &Store{Dir: dir}
+}
+
+// Put mengenkripsi data lalu menyimpannya dengan nama acak. storageKey dan wrappedKey harus disimpan di database
+func (s *Store) Put(plaintext []byte) (storageKey string, wrappedKey []byte, err error) {
+	ciphertext, wrappedKey, err := encryption.SealEnvelope(plaintext)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	storageKey = hex.EncodeToString(b)
+
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return "", nil, err
+	}
+	if err := os.WriteFile(s.path(storageKey), ciphertext, 0o600); err != nil {
+		return "", nil, err
+	}
+	return storageKey, wrappedKey, nil
+}
+
+// Get membaca dan mendekripsi file
+func (s *Store) Get(storageKey string, wrappedKey []byte) ([]byte, error) {
+	if !validKey(storageKey) {
+		return nil, ErrInvalidKey
+	}
+	ciphertext, err := os.ReadFile(s.path(storageKey))
+	if err != nil {
+		return nil, err
+	}
+	return encryption.OpenEnvelope(ciphertext, wrappedKey)
+}
+
+// Delete menghapus file, file yang sudah tidak ada tidak dianggap error
+func (s *Store) Delete(storageKey string) error {
+	if !validKey(storageKey) {
+		return ErrInvalidKey
+	}
+	if err := os.Remove(s.path(storageKey)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) path(storageKey string) string {
+	return filepath.Join(s.Dir, storageKey+".bin")
+}
+
+// storage key selalu 32 karakter hex, mencegah path traversal
+func validKey(storageKey string) bool {
+	if len(storageKey) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(storageKey)
+	return err == nil
+}
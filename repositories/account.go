@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"fmt"
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AccountRepository interface {
+	GetUser(Id int) (models.User, error)
+	FindTransactionsByUser(UserId int) ([]models.Transaction, error)
+	FindIdentitiesByUser(UserId int) ([]models.UserIdentity, error)
+	FindAPIKeysByUser(UserId int) ([]models.APIKey, error)
+	FindSessionsByUser(UserId int) ([]models.Session, error)
+	FindTravelersByUser(UserId int) ([]models.Traveler, error)
+	FindDocumentsByUser(UserId int) ([]models.Document, error)
+	FindWishlistsByUser(UserId int) ([]models.Wishlist, error)
+	ScheduleDeletion(UserId int, at *time.Time) error
+	FindDueDeletions(now time.Time) ([]models.User, error)
+	AnonymizeUser(UserId int, hashedPassword string) error
+	RevokeAllSessions(UserId int) error
+	CreateAuditLog(log models.AuditLog) error
+}
+
+func RepositoryAccount(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindIdentitiesByUser(UserId int) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.Where("user_id = ?", UserId).Find(&identities).Error
+
+	return identities, err
+}
+
+// ScheduleDeletion mengisi (atau membatalkan jika nil) jadwal penghapusan akun
+func (r *repository) ScheduleDeletion(UserId int, at *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", UserId).Update("deletion_scheduled_at", at).Error
+}
+
+// FindDueDeletions mengambil user yang masa tunggu penghapusannya sudah lewat dan belum dianonimkan
+func (r *repository) FindDueDeletions(now time.Time) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).Find(&users).Error
+
+	return users, err
+}
+
+// AnonymizeUser menghapus data pribadi user. file dokumen di vault harus dihapus lebih dulu oleh pemanggil. transaksi tidak dihapus karena dibutuhkan untuk pembukuan,
+// tetapi tidak lagi bisa dihubungkan ke orang tersebut
+func (r *repository) AnonymizeUser(UserId int, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.User{}).Where("id = ?", UserId).Updates(map[string]interface{}{
+			"name":                  "Deleted user",
+			"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", UserId),
+			"password":              hashedPassword,
+			"gender":                "",
+			"phone":                 "",
+			"address":               "",
+			"image":                 "",
+			"totp_secret":           "",
+			"totp_enabled":          false,
+			"email_verified_at":     nil,
+			"deletion_scheduled_at": nil,
+			"anonymized_at":         now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// data identitas traveler di booking lama juga dihapus, nama tetap disimpan untuk pembukuan
+		err = tx.Model(&models.TransactionTraveler{}).
+			Where("transaction_id IN (?)", tx.Model(&models.Transaction{}).Select("id").Where("user_id = ?", UserId)).
+			Updates(map[string]interface{}{"date_of_birth": "", "document_number": "", "document_expiry": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		// invoice tetap disimpan untuk pembukuan, hanya email penerima yang dihapus
+		err = tx.Model(&models.Invoice{}).
+			Where("transaction_id IN (?)", tx.Model(&models.Transaction{}).Select("id").Where("user_id = ?", UserId)).
+			Update("billed_email", fmt.Sprintf("deleted-%d@deleted.invalid", UserId)).Error
+		if err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.UserIdentity{}, &models.RecoveryCode{}, &models.PasswordReset{}, &models.Traveler{}, &models.Document{}, &models.Wishlist{}, &models.WaitlistEntry{}} {
+			if err := tx.Where("user_id = ?", UserId).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", UserId).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+
+		return revokeAllSessions(tx, UserId)
+	})
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"project/models"
+
+	"gorm.io/gorm"
+)
+
+type AddOnRepository interface {
+	FindAddOns(TripId int, activeOnly bool) ([]models.AddOn, error)
+	GetAddOn(Id int) (models.AddOn, error)
+	CreateAddOn(addOn models.AddOn) (models.AddOn, error)
+	UpdateAddOn(addOn models.AddOn) (models.AddOn, error)
+	DeleteAddOn(addOn models.AddOn) error
+	GetTrip(ID int) (models.Trip, error)
+}
+
+func RepositoryAddOn(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindAddOns(TripId int, activeOnly bool) ([]models.AddOn, error) {
+	var addOns []models.AddOn
+	query := r.db.Where("trip_id = ?", TripId)
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Order("id").Find(&addOns).Error
+
+	return addOns, err
+}
+
+func (r *repository) GetAddOn(Id int) (models.AddOn, error) {
+	var addOn models.AddOn
+	err := r.db.First(&addOn, Id).Error
+
+	return addOn, err
+}
+
+func (r *repository) CreateAddOn(addOn models.AddOn) (models.AddOn, error) {
+	err := r.db.Create(&addOn).Error
+
+	return addOn, err
+}
+
+// Select("*") dipakai agar nilai 0 dan false ikut tersimpan, jumlah terjual tidak diubah dari sini
+func (r *repository) UpdateAddOn(addOn models.AddOn) (models.AddOn, error) {
+	err := r.db.Model(&addOn).Select("*").Omit("id", "trip_id", "sold", "created_at").Updates(addOn).Error
+
+	return addOn, err
+}
+
+func (r *repository) DeleteAddOn(addOn models.AddOn) error {
+	return r.db.Delete(&addOn).Error
+}
+
+// FindAddOnsByIds mengambil add-on aktif milik trip
+func (r *repository) FindAddOnsByIds(TripId int, ids []int) ([]models.AddOn, error) {
+	var addOns []models.AddOn
+	err := r.db.Where("trip_id = ? AND active = ? AND id IN ?", TripId, true, ids).Find(&addOns).Error
+
+	return addOns, err
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	FindAPIKeysByUser(UserId int) ([]models.APIKey, error)
+	GetAPIKey(Id int) (models.APIKey, error)
+	CreateAPIKey(key models.APIKey) (models.APIKey, error)
+	RevokeAPIKey(Id int) error
+	GetUser(Id int) (models.User, error)
+}
+
+func RepositoryAPIKey(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindAPIKeysByUser(UserId int) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", UserId).Order("created_at desc").Find(&keys).Error
+
+	return keys, err
+}
+
+func (r *repository) GetAPIKey(Id int) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.First(&key, Id).Error
+
+	return key, err
+}
+
+func (r *repository) GetAPIKeyByPrefix(prefix string) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.First(&key, "prefix = ?", prefix).Error
+
+	return key, err
+}
+
+func (r *repository) CreateAPIKey(key models.APIKey) (models.APIKey, error) {
+	err := r.db.Create(&key).Error
+
+	return key, err
+}
+
+func (r *repository) RevokeAPIKey(Id int) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", Id).Update("revoked_at", time.Now()).Error
+}
+
+func (r *repository) TouchAPIKey(Id int, lastUsedAt time.Time, ip string) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", Id).Updates(map[string]interface{}{
+		"last_used_at": lastUsedAt,
+		"last_used_ip": ip,
+	}).Error
+}
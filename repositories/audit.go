@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"project/models"
+)
+
+// CreateAuditLog menambah catatan audit, dipakai oleh semua handler yang perlu mencatat aksi
+func (r *repository) CreateAuditLog(log models.AuditLog) error {
+	return r.db.Create(&log).Error
+}
+
+// FindAuditLogsByUser mengambil aktivitas user, baik yang dilakukan user tersebut maupun yang dilakukan terhadapnya
+func (r *repository) FindAuditLogsByUser(UserId int, offset int, limit int) ([]models.AuditLog, int64, error) {
+	query := r.db.Model(&models.AuditLog{}).Where("actor_id = ? OR subject_user_id = ?", UserId, UserId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AuditLog
+	err := query.Order("created_at desc, id desc").Offset(offset).Limit(limit).Find(&logs).Error
+
+	return logs, total, err
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"project/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -10,6 +11,17 @@ type AuthRepository interface {
 	Register(user models.User) (models.User, error)
 	Login(email string) (models.User, error)
 	Getuser(Id int) (models.User, error)
+	SetEmailVerified(Id int, verifiedAt *time.Time) error
+	SetTotpSecret(Id int, secret string) error
+	EnableTotp(Id int, step int64, codes []models.RecoveryCode) error
+	DisableTotp(Id int) error
+	UseTotpStep(Id int, step int64) error
+	UseRecoveryCode(Id int, codeHash string) error
+	ReplaceRecoveryCodes(Id int, codes []models.RecoveryCode) error
+	CreateSession(session models.Session) (models.Session, error)
+	GetIdentity(provider string, subject string) (models.UserIdentity, error)
+	CreateIdentity(identity models.UserIdentity) (models.UserIdentity, error)
+	CreateAuditLog(log models.AuditLog) error
 }
 
 // membuat function RepositoryAuth. parameter pointer ke gorm, return repository{db}. ini akan dipanggil di routes
@@ -44,3 +56,21 @@ func (r *repository) Getuser(Id int) (models.User, error) {
 
 	return user, err
 }
+
+// SetEmailVerified mengisi (atau mengosongkan jika nil) waktu verifikasi email user
+func (r *repository) SetEmailVerified(Id int, verifiedAt *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", Id).Update("email_verified_at", verifiedAt).Error
+}
+
+func (r *repository) GetIdentity(provider string, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.First(&identity, "provider = ? AND subject = ?", provider, subject).Error
+
+	return identity, err
+}
+
+func (r *repository) CreateIdentity(identity models.UserIdentity) (models.UserIdentity, error) {
+	err := r.db.Create(&identity).Error
+
+	return identity, err
+}
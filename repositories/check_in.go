@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"project/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CheckInRepository interface {
+	GetTrip(ID int) (models.Trip, error)
+	GetTransaction(Id int) (models.Transaction, error)
+	CheckInPassenger(checkIn models.CheckIn) (models.CheckIn, bool, error)
+	FindCheckIns(TripId int) ([]models.CheckIn, error)
+	FindTripBookings(TripId int) ([]models.Transaction, error)
+}
+
+func RepositoryCheckIn(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+// CheckInPassenger menyimpan check-in. jika kursi sudah check-in sebelumnya, data lama dikembalikan dengan created false
+func (r *repository) CheckInPassenger(checkIn models.CheckIn) (models.CheckIn, bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&checkIn)
+	if result.Error != nil {
+		return checkIn, false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return checkIn, true, nil
+	}
+
+	var existing models.CheckIn
+	err := r.db.Where("transaction_id = ? AND seat = ?", checkIn.TransactionId, checkIn.Seat).First(&existing).Error
+
+	return existing, false, err
+}
+
+func (r *repository) FindCheckIns(TripId int) ([]models.CheckIn, error) {
+	var checkIns []models.CheckIn
+	err := r.db.Where("trip_id = ?", TripId).Order("id").Find(&checkIns).Error
+
+	return checkIns, err
+}
+
+// FindTripBookings mengambil booking yang sudah dibayar untuk satu keberangkatan
+func (r *repository) FindTripBookings(TripId int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Preload("User").Preload("Travelers").Where("trip_id = ? AND status = ?", TripId, "success").Order("id").Find(&transactions).Error
+
+	return transactions, err
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DocumentRepository interface {
+	FindDocumentsByUser(UserId int) ([]models.Document, error)
+	GetDocument(Id int) (models.Document, error)
+	CreateDocument(document models.Document) (models.Document, error)
+	DeleteDocument(document models.Document) error
+	FindExpiredDocuments(now time.Time) ([]models.Document, error)
+	CreateDocumentAccessLog(log models.DocumentAccessLog) error
+	FindDocumentAccessLogs(DocumentId int) ([]models.DocumentAccessLog, error)
+	GetTraveler(Id int) (models.Traveler, error)
+}
+
+func RepositoryDocument(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindDocumentsByUser(UserId int) ([]models.Document, error) {
+	var documents []models.Document
+	err := r.db.Where("user_id = ?", UserId).Order("created_at desc").Find(&documents).Error
+
+	return documents, err
+}
+
+func (r *repository) GetDocument(Id int) (models.Document, error) {
+	var document models.Document
+	err := r.db.First(&document, Id).Error
+
+	return document, err
+}
+
+func (r *repository) CreateDocument(document models.Document) (models.Document, error) {
+	err := r.db.Create(&document).Error
+
+	return document, err
+}
+
+func (r *repository) DeleteDocument(document models.Document) error {
+	return r.db.Delete(&document).Error
+}
+
+// FindExpiredDocuments mengambil dokumen yang masa simpannya sudah habis
+func (r *repository) FindExpiredDocuments(now time.Time) ([]models.Document, error) {
+	var documents []models.Document
+	err := r.db.Where("expires_at <= ?", now).Find(&documents).Error
+
+	return documents, err
+}
+
+func (r *repository) CreateDocumentAccessLog(log models.DocumentAccessLog) error {
+	return r.db.Create(&log).Error
+}
+
+func (r *repository) FindDocumentAccessLogs(DocumentId int) ([]models.DocumentAccessLog, error) {
+	var logs []models.DocumentAccessLog
+	err := r.db.Where("document_id = ?", DocumentId).Order("created_at desc").Find(&logs).Error
+
+	return logs, err
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"errors"
+	"project/models"
+
+	"gorm.io/gorm"
+)
+
+type ExchangeRateRepository interface {
+	FindExchangeRates() ([]models.ExchangeRate, error)
+	GetExchangeRate(currency string) (models.ExchangeRate, error)
+	SaveExchangeRates(rates []models.ExchangeRate) ([]models.ExchangeRate, error)
+	DeleteExchangeRate(rate models.ExchangeRate) error
+	CreateAuditLog(log models.AuditLog) error
+}
+
+func RepositoryExchangeRate(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindExchangeRates() ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	err := r.db.Order("currency").Find(&rates).Error
+
+	return rates, err
+}
+
+func (r *repository) GetExchangeRate(currency string) (models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.Where("currency = ?", currency).First(&rate).Error
+
+	return rate, err
+}
+
+// function SaveExchangeRates menyimpan tabel kurs sekaligus, kurs yang sudah ada diperbarui. semua atau tidak sama sekali
+func (r *repository) SaveExchangeRates(rates []models.ExchangeRate) ([]models.ExchangeRate, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i, rate := range rates {
+			var existing models.ExchangeRate
+			err := tx.Where("currency = ?", rate.Currency).First(&existing).Error
+			if err == nil {
+				rate.Id = existing.Id
+				rate.CreatedAt = existing.CreatedAt
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err := tx.Save(&rate).Error; err != nil {
+				return err
+			}
+			rates[i] = rate
+		}
+		return nil
+	})
+
+	return rates, err
+}
+
+func (r *repository) DeleteExchangeRate(rate models.ExchangeRate) error {
+	return r.db.Delete(&rate).Error
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project/models"
+	"project/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IssueInvoice membuat invoice untuk transaction, atau mengembalikan invoice yang sudah ada. nomor diambil dari
+// InvoiceSequence yang dikunci di dalam db transaction yang sama dengan pembuatan invoice, sehingga jika gagal
+// nomornya ikut dibatalkan dan tidak ada nomor yang terlewat
+func (r *repository) IssueInvoice(transaction models.Transaction, issuedAt time.Time) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		sequence, err := lockInvoiceSequence(tx, issuedAt.Year())
+		if err != nil {
+			return err
+		}
+
+		// dicek setelah mengunci agar dua request untuk transaction yang sama tidak membuat dua invoice
+		err = tx.Where("transaction_id = ? AND reschedule_id = 0", transaction.Id).First(&invoice).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		invoice = models.Invoice{
+			Kind:          models.InvoiceStandard,
+			TransactionId: transaction.Id,
+			BilledName:    transaction.User.Name,
+			BilledEmail:   transaction.User.Email,
+			Subtotal:      transaction.Subtotal,
+			Discount:      transaction.Discount,
+			Fees:          transaction.Fees,
+			Tax:           transaction.Tax,
+			Total:         transaction.Total,
+			IssuedAt:      issuedAt,
+		}
+		return createInvoice(tx, sequence, &invoice, transaction.Items)
+	})
+
+	return invoice, err
+}
+
+// IssueRescheduleInvoice membuat adjustment (selisih ditagih) atau credit note (selisih dikembalikan) untuk reschedule,
+// atau mengembalikan yang sudah ada. previous adalah booking sebelum dipindahkan. rinciannya membatalkan baris harga lama
+// lalu menambahkan baris harga baru, sehingga totalnya sama dengan selisih reschedule. nomornya memakai urutan yang sama
+// dengan invoice booking
+func (r *repository) IssueRescheduleInvoice(reschedule models.Reschedule, previous models.Transaction, issuedAt time.Time) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		sequence, err := lockInvoiceSequence(tx, issuedAt.Year())
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("transaction_id = ? AND reschedule_id = ?", reschedule.TransactionId, reschedule.Id).First(&invoice).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var original models.Invoice
+		err = tx.Select("number").Where("transaction_id = ? AND reschedule_id = 0", reschedule.TransactionId).First(&original).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var newItems []models.TransactionItem
+		if err := json.Unmarshal([]byte(reschedule.Items), &newItems); err != nil {
+			return err
+		}
+		items := make([]models.TransactionItem, 0, len(previous.Items)+len(newItems))
+		for _, item := range previous.Items {
+			item.Description = "Reversal: " + item.Description
+			item.UnitAmount = -item.UnitAmount
+			item.Amount = -item.Amount
+			items = append(items, item)
+		}
+		items = append(items, newItems...)
+
+		kind := models.InvoiceAdjustment
+		if reschedule.Difference.Amount < 0 {
+			kind = models.InvoiceCreditNote
+		}
+		invoice = models.Invoice{
+			Kind:            kind,
+			TransactionId:   reschedule.TransactionId,
+			RescheduleId:    reschedule.Id,
+			ReferenceNumber: original.Number,
+			BilledName:      previous.User.Name,
+			BilledEmail:     previous.User.Email,
+			Subtotal:        money.IDR(reschedule.Subtotal.Amount - previous.Subtotal.Amount),
+			Discount:        money.IDR(reschedule.Discount.Amount - previous.Discount.Amount),
+			Fees:            money.IDR(reschedule.Fees.Amount - previous.Fees.Amount),
+			Tax:             money.IDR(reschedule.Tax.Amount - previous.Tax.Amount),
+			Total:           reschedule.Difference,
+			IssuedAt:        issuedAt,
+		}
+		return createInvoice(tx, sequence, &invoice, items)
+	})
+
+	return invoice, err
+}
+
+// lockInvoiceSequence mengunci nomor invoice terakhir tahun ini sampai db transaction selesai
+func lockInvoiceSequence(tx *gorm.DB, year int) (models.InvoiceSequence, error) {
+	var sequence models.InvoiceSequence
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.InvoiceSequence{Year: year}).Error; err != nil {
+		return sequence, err
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sequence, "year = ?", year).Error
+	return sequence, err
+}
+
+// createInvoice memberi invoice nomor berikutnya dari sequence yang sudah dikunci lalu menyimpannya dengan salinan rincian
+func createInvoice(tx *gorm.DB, sequence models.InvoiceSequence, invoice *models.Invoice, items []models.TransactionItem) error {
+	sequence.LastNumber++
+	if err := tx.Model(&sequence).Update("last_number", sequence.LastNumber).Error; err != nil {
+		return err
+	}
+
+	invoice.Number = fmt.Sprintf("INV/%d/%06d", sequence.Year, sequence.LastNumber)
+	invoice.Year = sequence.Year
+	invoice.Sequence = sequence.LastNumber
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	invoice.Items = string(data)
+	return tx.Create(invoice).Error
+}
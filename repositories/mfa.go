@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SetTotpSecret menyimpan secret baru yang belum aktif sampai user mengkonfirmasi kodenya
+func (r *repository) SetTotpSecret(Id int, secret string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", Id).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}).Error
+}
+
+// EnableTotp mengaktifkan 2FA dan mengganti seluruh kode cadangan user
+func (r *repository) EnableTotp(Id int, step int64, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", Id).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, Id, codes)
+	})
+}
+
+// DisableTotp mematikan 2FA dan menghapus secret serta kode cadangan
+func (r *repository) DisableTotp(Id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", Id).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", Id).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// UseTotpStep mencatat langkah waktu kode yang dipakai. kode yang sama (atau lebih lama) menghasilkan gorm.ErrRecordNotFound
+func (r *repository) UseTotpStep(Id int, step int64) error {
+	result := r.db.Model(&models.User{}).Where("id = ? AND totp_last_step < ?", Id, step).Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UseRecoveryCode menandai kode cadangan sudah dipakai. kode yang tidak ada atau sudah dipakai menghasilkan gorm.ErrRecordNotFound
+func (r *repository) UseRecoveryCode(Id int, codeHash string) error {
+	now := time.Now()
+	result := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND code_hash = ? AND used_at IS NULL", Id, codeHash).Update("used_at", &now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repository) ReplaceRecoveryCodes(Id int, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, Id, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, Id int, codes []models.RecoveryCode) error {
+	if err := tx.Where("user_id = ?", Id).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordRepository interface {
+	GetUserByEmail(email string) (models.User, error)
+	CreatePasswordReset(reset models.PasswordReset) (models.PasswordReset, error)
+	GetPasswordResetByHash(tokenHash string) (models.PasswordReset, error)
+	ResetPassword(reset models.PasswordReset, hashedPassword string) error
+	GetUser(Id int) (models.User, error)
+	ChangePassword(UserId int, hashedPassword string, keepTokenId string) error
+	CreateAuditLog(log models.AuditLog) error
+}
+
+func RepositoryPassword(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) GetUserByEmail(email string) (models.User, error) {
+	var user models.User
+	err := r.db.First(&user, "email = ?", email).Error
+
+	return user, err
+}
+
+// CreatePasswordReset menyimpan token baru dan membatalkan token lama user yang belum dipakai
+func (r *repository) CreatePasswordReset(reset models.PasswordReset) (models.PasswordReset, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.PasswordReset{}).Where("user_id = ? AND used_at IS NULL", reset.UserId).Update("used_at", &now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&reset).Error
+	})
+
+	return reset, err
+}
+
+func (r *repository) GetPasswordResetByHash(tokenHash string) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := r.db.First(&reset, "token_hash = ?", tokenHash).Error
+
+	return reset, err
+}
+
+// ResetPassword menandai token sudah dipakai, mengganti password dan mencabut semua sesi user dalam satu transaksi.
+// token yang sudah dipakai oleh request lain menghasilkan gorm.ErrRecordNotFound
+func (r *repository) ResetPassword(reset models.PasswordReset, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.PasswordReset{}).Where("id = ? AND used_at IS NULL", reset.Id).Update("used_at", &now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Model(&models.User{}).Where("id = ?", reset.UserId).Updates(map[string]interface{}{
+			"password":                hashedPassword,
+			"password_reset_required": false,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return revokeAllSessions(tx, reset.UserId)
+	})
+}
+
+// ChangePassword mengganti password lalu mencabut semua sesi lain, sesi keepTokenId (yang dipakai saat ini) tetap aktif
+func (r *repository) ChangePassword(UserId int, hashedPassword string, keepTokenId string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", UserId).Updates(map[string]interface{}{
+			"password":                hashedPassword,
+			"password_reset_required": false,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).Where("user_id = ? AND token_id <> ? AND revoked_at IS NULL", UserId, keepTokenId).Update("revoked_at", time.Now()).Error
+	})
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"project/models"
+
+	"gorm.io/gorm"
+)
+
+type PricingRuleRepository interface {
+	FindPricingRules(TripId int) ([]models.PricingRule, error)
+	GetPricingRule(Id int) (models.PricingRule, error)
+	CreatePricingRule(rule models.PricingRule) (models.PricingRule, error)
+	UpdatePricingRule(rule models.PricingRule) (models.PricingRule, error)
+	DeletePricingRule(rule models.PricingRule) error
+	GetTrip(ID int) (models.Trip, error)
+	FindAddOnsByIds(TripId int, ids []int) ([]models.AddOn, error)
+	GetPromoCodeByCode(code string) (models.PromoCode, error)
+	CountPromoUses(PromoCodeId int, UserId int) (int, error)
+	FindTaxRules(activeOnly bool) ([]models.TaxRule, error)
+	GetExchangeRate(currency string) (models.ExchangeRate, error)
+}
+
+func RepositoryPricingRule(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindPricingRules(TripId int) ([]models.PricingRule, error) {
+	var rules []models.PricingRule
+	err := r.db.Where("trip_id = ?", TripId).Order("id").Find(&rules).Error
+
+	return rules, err
+}
+
+func (r *repository) GetPricingRule(Id int) (models.PricingRule, error) {
+	var rule models.PricingRule
+	err := r.db.First(&rule, Id).Error
+
+	return rule, err
+}
+
+func (r *repository) CreatePricingRule(rule models.PricingRule) (models.PricingRule, error) {
+	err := r.db.Create(&rule).Error
+
+	return rule, err
+}
+
+// Select("*") dipakai agar nilai 0 dan nil ikut tersimpan
+func (r *repository) UpdatePricingRule(rule models.PricingRule) (models.PricingRule, error) {
+	err := r.db.Model(&rule).Select("*").Omit("id", "trip_id", "created_at").Updates(rule).Error
+
+	return rule, err
+}
+
+func (r *repository) DeletePricingRule(rule models.PricingRule) error {
+	return r.db.Delete(&rule).Error
+}
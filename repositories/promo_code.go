@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"project/models"
+
+	"gorm.io/gorm"
+)
+
+type PromoCodeRepository interface {
+	FindPromoCodes(offset int, limit int) ([]models.PromoCode, int64, error)
+	GetPromoCode(Id int) (models.PromoCode, error)
+	GetPromoCodeByCode(code string) (models.PromoCode, error)
+	CreatePromoCode(promo models.PromoCode) (models.PromoCode, error)
+	UpdatePromoCode(promo models.PromoCode) (models.PromoCode, error)
+	DeletePromoCode(promo models.PromoCode) error
+	CreateAuditLog(log models.AuditLog) error
+}
+
+func RepositoryPromoCode(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindPromoCodes(offset int, limit int) ([]models.PromoCode, int64, error) {
+	var promos []models.PromoCode
+	var total int64
+	if err := r.db.Model(&models.PromoCode{}).Count(&total).Error; err != nil {
+		return promos, 0, err
+	}
+	err := r.db.Order("id desc").Offset(offset).Limit(limit).Find(&promos).Error
+
+	return promos, total, err
+}
+
+func (r *repository) GetPromoCode(Id int) (models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.db.First(&promo, Id).Error
+
+	return promo, err
+}
+
+func (r *repository) GetPromoCodeByCode(code string) (models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.db.Where("code = ?", code).First(&promo).Error
+
+	return promo, err
+}
+
+func (r *repository) CreatePromoCode(promo models.PromoCode) (models.PromoCode, error) {
+	err := r.db.Create(&promo).Error
+
+	return promo, err
+}
+
+// Select("*") dipakai agar nilai 0, false dan nil ikut tersimpan, used_count tidak diubah dari sini
+func (r *repository) UpdatePromoCode(promo models.PromoCode) (models.PromoCode, error) {
+	err := r.db.Model(&promo).Select("*").Omit("id", "used_count", "created_at").Updates(promo).Error
+
+	return promo, err
+}
+
+func (r *repository) DeletePromoCode(promo models.PromoCode) error {
+	return r.db.Delete(&promo).Error
+}
+
+// CountPromoUses menghitung booking user yang memakai promo code, booking yang gagal tidak dihitung
+func (r *repository) CountPromoUses(PromoCodeId int, UserId int) (int, error) {
+	var count int64
+	err := r.db.Model(&models.Transaction{}).
+		Where("promo_code_id = ? AND user_id = ? AND status NOT IN ?", PromoCodeId, UserId, []string{"failed", "reject"}).
+		Count(&count).Error
+
+	return int(count), err
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"project/models"
+	"project/pkg/pricing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (r *repository) GetReschedule(Id int) (models.Reschedule, error) {
+	var reschedule models.Reschedule
+	err := r.db.First(&reschedule, Id).Error
+
+	return reschedule, err
+}
+
+// GetPendingReschedule mengambil reschedule booking yang selisih harganya belum dibayar
+func (r *repository) GetPendingReschedule(TransactionId int) (models.Reschedule, error) {
+	var reschedule models.Reschedule
+	err := r.db.Where("transaction_id = ? AND status = ?", TransactionId, models.ReschedulePending).First(&reschedule).Error
+
+	return reschedule, err
+}
+
+func (r *repository) CountCheckIns(TransactionId int) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.CheckIn{}).Where("transaction_id = ?", TransactionId).Count(&count).Error
+
+	return count, err
+}
+
+// StartReschedule menahan kursi di keberangkatan baru dan menyimpan reschedule dalam satu transaksi database.
+// kuota dikurangi dengan kondisi agar booking yang bersamaan tidak melebihi kuota, held adalah kursi yang ditahan waitlist
+func (r *repository) StartReschedule(reschedule models.Reschedule, held int) (models.Reschedule, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Trip{}).Where("id = ? AND quota >= ?", reschedule.ToTripId, reschedule.Seats+held).
+			Update("quota", gorm.Expr("quota - ?", reschedule.Seats))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return pricing.Error("not enough seats left on the new departure")
+		}
+		return tx.Create(&reschedule).Error
+	})
+
+	return reschedule, err
+}
+
+// CompleteReschedule memindahkan booking ke keberangkatan baru: kursi lama dikembalikan ke kuota lalu total dan rincian
+// harga diganti. status diubah dengan kondisi pending agar notifikasi midtrans yang terkirim dua kali tidak diproses ulang,
+// completed false berarti reschedule sudah diproses sebelumnya
+func (r *repository) CompleteReschedule(reschedule models.Reschedule, items []models.TransactionItem, completedAt time.Time) (models.Reschedule, bool, error) {
+	var completed bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Reschedule{}).Where("id = ? AND status = ?", reschedule.Id, models.ReschedulePending).
+			Updates(map[string]interface{}{"status": models.RescheduleCompleted, "completed_at": completedAt})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		// booking yang dibatalkan selama menunggu pembayaran tidak dipindahkan
+		result = tx.Model(&models.Transaction{}).Where("id = ? AND status = ?", reschedule.TransactionId, "success").
+			Updates(map[string]interface{}{
+				"trip_id":         reschedule.ToTripId,
+				"subtotal_amount": reschedule.Subtotal.Amount,
+				"discount_amount": reschedule.Discount.Amount,
+				"fees_amount":     reschedule.Fees.Amount,
+				"tax_amount":      reschedule.Tax.Amount,
+				"total_amount":    reschedule.Total.Amount,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return pricing.Error("booking is no longer paid")
+		}
+
+		err := tx.Model(&models.Trip{}).Where("id = ?", reschedule.FromTripId).Update("quota", gorm.Expr("quota + ?", reschedule.Seats)).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("transaction_id = ?", reschedule.TransactionId).Delete(&models.TransactionItem{}).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].Id = 0
+			items[i].TransactionId = reschedule.TransactionId
+		}
+		if len(items) > 0 {
+			if err := tx.Create(&items).Error; err != nil {
+				return err
+			}
+		}
+
+		completed = true
+		return nil
+	})
+	if completed {
+		reschedule.Status = models.RescheduleCompleted
+		reschedule.CompletedAt = &completedAt
+	}
+
+	return reschedule, completed, err
+}
+
+// FailReschedule membatalkan reschedule yang selisihnya tidak dibayar dan mengembalikan kursi yang ditahan.
+// failed false berarti reschedule sudah diproses sebelumnya
+func (r *repository) FailReschedule(reschedule models.Reschedule) (models.Reschedule, bool, error) {
+	var failed bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Reschedule{}).Where("id = ? AND status = ?", reschedule.Id, models.ReschedulePending).
+			Update("status", models.RescheduleFailed)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		failed = true
+		return tx.Model(&models.Trip{}).Where("id = ?", reschedule.ToTripId).Update("quota", gorm.Expr("quota + ?", reschedule.Seats)).Error
+	})
+	if failed && err == nil {
+		reschedule.Status = models.RescheduleFailed
+	}
+
+	return reschedule, failed && err == nil, err
+}
+
+// FindExpiredReschedules mengambil reschedule yang selisihnya belum dibayar sampai batas waktu pembayaran
+func (r *repository) FindExpiredReschedules(before time.Time) ([]models.Reschedule, error) {
+	var reschedules []models.Reschedule
+	err := r.db.Where("status = ? AND created_at < ?", models.ReschedulePending, before).Find(&reschedules).Error
+
+	return reschedules, err
+}
+
+// UpdateReschedule menyimpan token pembayaran dan status refund
+func (r *repository) UpdateReschedule(reschedule models.Reschedule) (models.Reschedule, error) {
+	err := r.db.Model(&reschedule).Select("token", "refund_status").Updates(reschedule).Error
+
+	return reschedule, err
+}
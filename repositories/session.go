@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	FindSessionsByUser(UserId int) ([]models.Session, error)
+	GetSession(Id int) (models.Session, error)
+	GetSessionByTokenId(tokenId string) (models.Session, error)
+	TouchSession(Id int, lastSeenAt time.Time) error
+	RevokeSession(Id int) error
+	RevokeOtherSessions(UserId int, keepTokenId string) error
+	RevokeAllSessions(UserId int) error
+	GetUser(Id int) (models.User, error)
+}
+
+func RepositorySession(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) CreateSession(session models.Session) (models.Session, error) {
+	err := r.db.Create(&session).Error
+
+	return session, err
+}
+
+// FindSessionsByUser mengambil sesi user yang masih aktif (belum dicabut dan belum expired)
+func (r *repository) FindSessionsByUser(UserId int) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", UserId, time.Now()).Order("last_seen_at desc").Find(&sessions).Error
+
+	return sessions, err
+}
+
+func (r *repository) GetSession(Id int) (models.Session, error) {
+	var session models.Session
+	err := r.db.First(&session, Id).Error
+
+	return session, err
+}
+
+func (r *repository) GetSessionByTokenId(tokenId string) (models.Session, error) {
+	var session models.Session
+	err := r.db.First(&session, "token_id = ?", tokenId).Error
+
+	return session, err
+}
+
+func (r *repository) TouchSession(Id int, lastSeenAt time.Time) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", Id).Update("last_seen_at", lastSeenAt).Error
+}
+
+func (r *repository) RevokeSession(Id int) error {
+	return r.db.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", Id).Update("revoked_at", time.Now()).Error
+}
+
+func (r *repository) RevokeOtherSessions(UserId int, keepTokenId string) error {
+	return r.db.Model(&models.Session{}).Where("user_id = ? AND token_id <> ? AND revoked_at IS NULL", UserId, keepTokenId).Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllSessions mencabut semua sesi user, token_version juga dinaikkan agar token lama tanpa sesi ikut tidak berlaku
+func (r *repository) RevokeAllSessions(UserId int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return revokeAllSessions(tx, UserId)
+	})
+}
+
+func revokeAllSessions(tx *gorm.DB, UserId int) error {
+	err := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", UserId).Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.User{}).Where("id = ?", UserId).Update("token_version", gorm.Expr("token_version + 1")).Error
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"project/models"
+
+	"gorm.io/gorm"
+)
+
+type TaxRuleRepository interface {
+	FindTaxRules(activeOnly bool) ([]models.TaxRule, error)
+	GetTaxRule(Id int) (models.TaxRule, error)
+	CreateTaxRule(rule models.TaxRule) (models.TaxRule, error)
+	UpdateTaxRule(rule models.TaxRule) (models.TaxRule, error)
+	DeleteTaxRule(rule models.TaxRule) error
+	CreateAuditLog(log models.AuditLog) error
+}
+
+func RepositoryTaxRule(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindTaxRules(activeOnly bool) ([]models.TaxRule, error) {
+	var rules []models.TaxRule
+	query := r.db
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Order("id").Find(&rules).Error
+
+	return rules, err
+}
+
+func (r *repository) GetTaxRule(Id int) (models.TaxRule, error) {
+	var rule models.TaxRule
+	err := r.db.First(&rule, Id).Error
+
+	return rule, err
+}
+
+func (r *repository) CreateTaxRule(rule models.TaxRule) (models.TaxRule, error) {
+	err := r.db.Create(&rule).Error
+
+	return rule, err
+}
+
+// Select("*") dipakai agar nilai 0 dan false ikut tersimpan
+func (r *repository) UpdateTaxRule(rule models.TaxRule) (models.TaxRule, error) {
+	err := r.db.Model(&rule).Select("*").Omit("id", "created_at").Updates(rule).Error
+
+	return rule, err
+}
+
+func (r *repository) DeleteTaxRule(rule models.TaxRule) error {
+	return r.db.Delete(&rule).Error
+}
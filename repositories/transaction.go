@@ -1,19 +1,69 @@
 package repositories
 
 import (
+	"encoding/json"
+	"errors"
 	"project/models"
+	"project/pkg/pricing"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository interface {
 	FindTransactions() ([]models.Transaction, error)
 	FindTransactionsByUser(UserId int) ([]models.Transaction, error)
 	GetTransaction(Id int) (models.Transaction, error)
-	CreateTransaction(transaction models.Transaction) (models.Transaction, error)
-	UpdateTransaction(status string, Id int) (models.Transaction, error)
+	CreateTransaction(transaction models.Transaction, held int) (models.Transaction, error)
+	UpdateTransaction(status string, Id int) (models.Transaction, bool, error)
 	UpdateTokenTransaction(token string, Id int) (models.Transaction, error)
+	FindExpiredBookings(before time.Time) ([]models.Transaction, error)
+	ExpireBooking(transaction models.Transaction) (bool, error)
 	DeleteTransaction(transaction models.Transaction) (models.Transaction, error)
+	GetUser(Id int) (models.User, error)
+	FindTravelersByIds(UserId int, ids []int) ([]models.Traveler, error)
+	GetTrip(ID int) (models.Trip, error)
+	HeldWaitlistSeats(TripId int, ExceptUserId int) (int, error)
+	MarkWaitlistBooked(UserId int, TripId int, TransactionId int) error
+	FindPricingRules(TripId int) ([]models.PricingRule, error)
+	FindAddOnsByIds(TripId int, ids []int) ([]models.AddOn, error)
+	GetPromoCodeByCode(code string) (models.PromoCode, error)
+	CountPromoUses(PromoCodeId int, UserId int) (int, error)
+	FindTaxRules(activeOnly bool) ([]models.TaxRule, error)
+	SumTransactions(from time.Time, to time.Time) (TransactionTotals, error)
+	SumTransactionCharges(from time.Time, to time.Time) ([]ChargeTotal, error)
+	IssueInvoice(transaction models.Transaction, issuedAt time.Time) (models.Invoice, error)
+	IssueRescheduleInvoice(reschedule models.Reschedule, previous models.Transaction, issuedAt time.Time) (models.Invoice, error)
+	GetReschedule(Id int) (models.Reschedule, error)
+	GetPendingReschedule(TransactionId int) (models.Reschedule, error)
+	CountCheckIns(TransactionId int) (int64, error)
+	StartReschedule(reschedule models.Reschedule, held int) (models.Reschedule, error)
+	CompleteReschedule(reschedule models.Reschedule, items []models.TransactionItem, completedAt time.Time) (models.Reschedule, bool, error)
+	FailReschedule(reschedule models.Reschedule) (models.Reschedule, bool, error)
+	FindExpiredReschedules(before time.Time) ([]models.Reschedule, error)
+	UpdateReschedule(reschedule models.Reschedule) (models.Reschedule, error)
+	CreateAuditLog(log models.AuditLog) error
+	CreateTransactionEvent(event models.TransactionEvent) error
+	FindTransactionEvents(TransactionId int) ([]models.TransactionEvent, error)
+}
+
+// TransactionTotals adalah jumlah rincian total transaksi yang berhasil dibayar, diambil dari nilai yang tersimpan saat booking
+type TransactionTotals struct {
+	Count    int64
+	Subtotal int64
+	Discount int64
+	Fees     int64
+	Tax      int64
+	Total    int64
+}
+
+// ChargeTotal adalah jumlah satu baris pajak atau biaya layanan (per aturan) dari transaksi yang berhasil dibayar
+type ChargeTotal struct {
+	Kind        string
+	Code        string
+	Description string
+	Amount      int64
 }
 
 func RepositoryTransaction(db *gorm.DB) *repository {
@@ -22,55 +72,286 @@ func RepositoryTransaction(db *gorm.DB) *repository {
 
 func (r *repository) FindTransactions() ([]models.Transaction, error) {
 	var transaction []models.Transaction
-	err := r.db.Preload("Trip").Preload("Trip.Country").Preload("User").Find(&transaction).Error
+	err := r.db.Preload("Trip").Preload("Trip.Country").Preload("User").Preload("Items").Find(&transaction).Error
 
 	return transaction, err
 }
 
 func (r *repository) FindTransactionsByUser(UserId int) ([]models.Transaction, error) {
 	var transaction []models.Transaction
-	err := r.db.Preload("Trip").Preload("Trip.Country").Preload("User").Where("user_id = ?", UserId).Order("booking_date desc").Find(&transaction).Error
+	err := r.db.Preload("Trip").Preload("Trip.Country").Preload("User").Preload("Travelers").Preload("Items").Where("user_id = ?", UserId).Order("booking_date desc").Find(&transaction).Error
 
 	return transaction, err
 }
 
 func (r *repository) GetTransaction(Id int) (models.Transaction, error) {
 	var transaction models.Transaction
-	err := r.db.Preload("Trip.Country").Preload("Trip").Preload("User").First(&transaction, "id = ?", Id).Error
+	err := r.db.Preload("Trip.Country").Preload("Trip").Preload("User").Preload("Travelers").Preload("Items").First(&transaction, "id = ?", Id).Error
 
 	return transaction, err
 }
 
-func (r *repository) CreateTransaction(transaction models.Transaction) (models.Transaction, error) {
-	err := r.db.Create(&transaction).Error
+// CreateTransaction juga menyimpan transaction.Travelers (salinan data traveler) dan rincian harga dalam satu transaksi database.
+// kursi, pemakaian promo code dan stok add-on dihitung di sini agar batasnya tidak terlewati oleh booking yang bersamaan.
+// held adalah kursi yang sedang ditahan untuk penawaran waitlist user lain
+func (r *repository) CreateTransaction(transaction models.Transaction, held int) (models.Transaction, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Trip{}).Where("id = ? AND quota >= ?", transaction.TripId, transaction.CounterQty+held).
+			Update("quota", gorm.Expr("quota - ?", transaction.CounterQty))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return pricing.Error("not enough seats left, join the waitlist to be notified when seats free up")
+		}
+		transaction.SeatsReserved = true
+
+		if transaction.PromoCodeId != 0 {
+			result := tx.Model(&models.PromoCode{}).
+				Where("id = ? AND (usage_limit = 0 OR used_count < usage_limit)", transaction.PromoCodeId).
+				Update("used_count", gorm.Expr("used_count + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return pricing.ErrPromoUsedUp
+			}
+
+			// baris promo code sudah terkunci oleh update di atas, jadi booking bersamaan dengan promo yang sama
+			// menunggu di sini dan menghitung pemakaian user setelah booking sebelumnya tersimpan
+			var promo models.PromoCode
+			if err := tx.Select("id", "per_user_limit").First(&promo, transaction.PromoCodeId).Error; err != nil {
+				return err
+			}
+			if promo.PerUserLimit > 0 {
+				var uses int64
+				err := tx.Model(&models.Transaction{}).Clauses(clause.Locking{Strength: "SHARE"}).
+					Where("promo_code_id = ? AND user_id = ? AND status NOT IN ?", transaction.PromoCodeId, transaction.UserId, []string{"failed", "reject"}).
+					Count(&uses).Error
+				if err != nil {
+					return err
+				}
+				if int(uses) >= promo.PerUserLimit {
+					return pricing.ErrPromoUserLimit
+				}
+			}
+		}
+		if err := takeAddOns(tx, transaction.Items); err != nil {
+			return err
+		}
+		return tx.Create(&transaction).Error
+	})
 
 	return transaction, err
 }
 
-func (r *repository) UpdateTransaction(status string, Id int) (models.Transaction, error) {
-	var transaction models.Transaction
-	r.db.Preload("Trip.Country").Preload("Trip").Preload("User").First(&transaction, "id = ?", Id)
-	// If is different & Status is "success" decrement available quota on data trip
-	if status != transaction.Status && status == "success" {
-		var trip models.Trip
-		r.db.First(&trip, transaction.TripId)
-		trip.Quota = trip.Quota - transaction.CounterQty
-		r.db.Model(&trip).Updates(trip)
+// takeAddOns menambah jumlah add-on terjual, pricing.Error jika stok add-on tidak cukup
+func takeAddOns(db *gorm.DB, items []models.TransactionItem) error {
+	for _, item := range items {
+		if item.AddOnId == 0 {
+			continue
+		}
+		result := db.Model(&models.AddOn{}).
+			Where("id = ? AND (stock = 0 OR sold + ? <= stock)", item.AddOnId, item.Quantity).
+			Update("sold", gorm.Expr("sold + ?", item.Quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return pricing.Error(item.Description + " is sold out")
+		}
 	}
+	return nil
+}
 
-	// If is different & Status is "reject" decrement available quota on data trip
-	if status != transaction.Status && status == "reject" {
-		var trip models.Trip
-		r.db.First(&trip, transaction.TripId)
-		trip.Quota = trip.Quota + transaction.CounterQty
-		r.db.Model(&trip).Updates(trip)
+// releaseBooking mengembalikan kursi, kuota promo code dan stok add-on dari booking yang gagal atau dihapus
+func releaseBooking(db *gorm.DB, transaction models.Transaction) error {
+	if transaction.SeatsReserved {
+		err := db.Model(&models.Trip{}).Where("id = ?", transaction.TripId).Update("quota", gorm.Expr("quota + ?", transaction.CounterQty)).Error
+		if err == nil {
+			err = db.Model(&models.Transaction{}).Where("id = ?", transaction.Id).Update("seats_reserved", false).Error
+		}
+		if err != nil {
+			return err
+		}
 	}
 
-	// change transaction status
-	transaction.Status = status
-	err := r.db.Model(&transaction).Updates(transaction).Error
+	if transaction.Status == "failed" || transaction.Status == "reject" {
+		return nil
+	}
+	if transaction.PromoCodeId != 0 {
+		err := db.Model(&models.PromoCode{}).Where("id = ? AND used_count > 0", transaction.PromoCodeId).
+			Update("used_count", gorm.Expr("used_count - 1")).Error
+		if err != nil {
+			return err
+		}
+	}
 
-	return transaction, err
+	var items []models.TransactionItem
+	if err := db.Where("transaction_id = ? AND add_on_id <> 0", transaction.Id).Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		err := db.Model(&models.AddOn{}).Where("id = ?", item.AddOnId).
+			Update("sold", gorm.Expr("GREATEST(sold - ?, 0)", item.Quantity)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository) SumTransactions(from time.Time, to time.Time) (TransactionTotals, error) {
+	var totals TransactionTotals
+	err := r.db.Model(&models.Transaction{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(subtotal_amount), 0) AS subtotal, COALESCE(SUM(discount_amount), 0) AS discount, "+
+			"COALESCE(SUM(fees_amount), 0) AS fees, COALESCE(SUM(tax_amount), 0) AS tax, COALESCE(SUM(total_amount), 0) AS total").
+		Where("status = ? AND booking_date >= ? AND booking_date < ?", "success", from, to).
+		Scan(&totals).Error
+
+	return totals, err
+}
+
+func (r *repository) SumTransactionCharges(from time.Time, to time.Time) ([]ChargeTotal, error) {
+	var charges []ChargeTotal
+	err := r.db.Model(&models.TransactionItem{}).
+		Select("transaction_items.kind, transaction_items.code, transaction_items.description, SUM(transaction_items.amount) AS amount").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Where("transaction_items.kind IN ? AND transactions.status = ? AND transactions.booking_date >= ? AND transactions.booking_date < ?",
+			[]string{models.ItemFee, models.ItemTax}, "success", from, to).
+		Group("transaction_items.kind, transaction_items.code, transaction_items.description").
+		Order("transaction_items.kind, transaction_items.code").
+		Scan(&charges).Error
+
+	return charges, err
+}
+
+// UpdateTransaction mengubah status booking dari notifikasi midtrans. baris booking dikunci agar notifikasi dan job
+// kadaluarsa tidak memproses booking yang sama bersamaan. confirmed false berarti pembayaran datang setelah booking gagal
+// dan kursi atau add-on sudah habis, booking tetap gagal dan ditandai untuk refund manual
+func (r *repository) UpdateTransaction(status string, Id int) (models.Transaction, bool, error) {
+	confirmed := true
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, "id = ?", Id).Error; err != nil {
+			return err
+		}
+
+		// kursi booking sudah ditahan sejak dibuat. booking lama atau booking gagal yang ternyata dibayar menahan kursi saat lunas.
+		// jika gagal di tengah jalan savepoint dibatalkan sehingga kuota, promo dan add-on tidak berubah
+		if status == "success" && !transaction.SeatsReserved {
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				return retakeBooking(tx, transaction)
+			})
+			var soldOut pricing.Error
+			if errors.As(err, &soldOut) {
+				confirmed = false
+				return refundLatePayment(tx, transaction, soldOut)
+			}
+			if err != nil {
+				return err
+			}
+			transaction.SeatsReserved = true
+		}
+
+		// kursi booking yang gagal atau dibatalkan dikembalikan ke kuota, lalu bisa ditawarkan ke waitlist
+		if status == "failed" || status == "reject" {
+			if err := releaseBooking(tx, transaction); err != nil {
+				return err
+			}
+			transaction.SeatsReserved = false
+		}
+
+		// change transaction status
+		return tx.Model(&transaction).Updates(map[string]interface{}{"status": status, "seats_reserved": transaction.SeatsReserved}).Error
+	})
+	if err != nil {
+		return models.Transaction{}, false, err
+	}
+
+	transaction, err := r.GetTransaction(Id)
+	return transaction, confirmed, err
+}
+
+// retakeBooking menahan kembali kursi booking yang dibayar setelah gagal, termasuk kursi yang sedang ditawarkan ke waitlist.
+// booking gagal sudah melepas promo code dan add-on-nya sehingga keduanya ikut diambil lagi. kuota diubah lewat expression
+// agar nilai 0 ikut tersimpan (Updates dengan struct melewati nilai 0)
+func retakeBooking(db *gorm.DB, transaction models.Transaction) error {
+	held, err := heldWaitlistSeats(db, transaction.TripId, transaction.UserId)
+	if err != nil {
+		return err
+	}
+	result := db.Model(&models.Trip{}).Where("id = ? AND quota >= ?", transaction.TripId, transaction.CounterQty+held).
+		Update("quota", gorm.Expr("quota - ?", transaction.CounterQty))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return pricing.Error("not enough seats left")
+	}
+
+	if transaction.Status != "failed" && transaction.Status != "reject" {
+		return nil
+	}
+	// user sudah membayar harga diskon, pemakaian promo tetap dihitung walaupun batasnya sudah tercapai
+	if transaction.PromoCodeId != 0 {
+		err := db.Model(&models.PromoCode{}).Where("id = ?", transaction.PromoCodeId).Update("used_count", gorm.Expr("used_count + 1")).Error
+		if err != nil {
+			return err
+		}
+	}
+	var items []models.TransactionItem
+	if err := db.Where("transaction_id = ? AND add_on_id <> 0", transaction.Id).Find(&items).Error; err != nil {
+		return err
+	}
+	return takeAddOns(db, items)
+}
+
+// refundLatePayment menandai booking yang dibayar setelah kursi atau add-on-nya habis untuk refund manual oleh admin
+// lalu mencatatnya di riwayat booking
+func refundLatePayment(db *gorm.DB, transaction models.Transaction, reason error) error {
+	err := db.Model(&models.Transaction{}).Where("id = ?", transaction.Id).Update("refund_status", models.RefundManual).Error
+	if err != nil {
+		return err
+	}
+
+	detail, _ := json.Marshal(map[string]interface{}{
+		"reason":      reason.Error(),
+		"trip_id":     transaction.TripId,
+		"counter_qty": transaction.CounterQty,
+		"total":       transaction.Total,
+	})
+	return db.Create(&models.TransactionEvent{
+		TransactionId: transaction.Id,
+		UserId:        transaction.UserId,
+		Type:          models.EventRefundRequired,
+		ActorType:     models.ActorSystem,
+		Detail:        string(detail),
+	}).Error
+}
+
+// FindExpiredBookings mengambil booking yang belum dibayar sampai batas waktu pembayaran
+func (r *repository) FindExpiredBookings(before time.Time) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Where("status = ? AND booking_date < ?", "pending", before).Find(&transactions).Error
+
+	return transactions, err
+}
+
+// ExpireBooking menggagalkan booking yang masih pending lalu melepas kursinya dalam satu transaksi database.
+// expired false jika status booking sudah berubah (misal pembayaran masuk bersamaan)
+func (r *repository) ExpireBooking(transaction models.Transaction) (bool, error) {
+	var expired bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Transaction{}).Where("id = ? AND status = ?", transaction.Id, "pending").Update("status", "failed")
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		expired = true
+		return releaseBooking(tx, transaction)
+	})
+
+	return expired, err
 }
 
 func (r *repository) UpdateTokenTransaction(token string, Id int) (models.Transaction, error) {
@@ -86,7 +367,12 @@ func (r *repository) UpdateTokenTransaction(token string, Id int) (models.Transa
 }
 
 func (r *repository) DeleteTransaction(transaction models.Transaction) (models.Transaction, error) {
-	err := r.db.Preload("Trip").Delete(&transaction).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := releaseBooking(tx, transaction); err != nil {
+			return err
+		}
+		return tx.Select("Travelers", "Items").Delete(&transaction).Error
+	})
 
 	return transaction, err
 }
@@ -0,0 +1,15 @@
+package repositories
+
+import "project/models"
+
+// riwayat booking hanya bisa ditambah, tidak ada method untuk mengubah atau menghapus
+func (r *repository) CreateTransactionEvent(event models.TransactionEvent) error {
+	return r.db.Create(&event).Error
+}
+
+func (r *repository) FindTransactionEvents(TransactionId int) ([]models.TransactionEvent, error) {
+	var events []models.TransactionEvent
+	err := r.db.Where("transaction_id = ?", TransactionId).Order("created_at, id").Find(&events).Error
+
+	return events, err
+}
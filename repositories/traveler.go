@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"project/models"
+
+	"gorm.io/gorm"
+)
+
+type TravelerRepository interface {
+	FindTravelersByUser(UserId int) ([]models.Traveler, error)
+	GetTraveler(Id int) (models.Traveler, error)
+	CreateTraveler(traveler models.Traveler) (models.Traveler, error)
+	UpdateTraveler(traveler models.Traveler) (models.Traveler, error)
+	DeleteTraveler(traveler models.Traveler) (models.Traveler, error)
+}
+
+func RepositoryTraveler(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindTravelersByUser(UserId int) ([]models.Traveler, error) {
+	var travelers []models.Traveler
+	err := r.db.Where("user_id = ?", UserId).Order("full_name").Find(&travelers).Error
+
+	return travelers, err
+}
+
+func (r *repository) GetTraveler(Id int) (models.Traveler, error) {
+	var traveler models.Traveler
+	err := r.db.First(&traveler, Id).Error
+
+	return traveler, err
+}
+
+func (r *repository) CreateTraveler(traveler models.Traveler) (models.Traveler, error) {
+	err := r.db.Create(&traveler).Error
+
+	return traveler, err
+}
+
+// UpdateTraveler menyimpan semua kolom, kolom terenkripsi ikut dienkripsi ulang
+func (r *repository) UpdateTraveler(traveler models.Traveler) (models.Traveler, error) {
+	err := r.db.Save(&traveler).Error
+
+	return traveler, err
+}
+
+func (r *repository) DeleteTraveler(traveler models.Traveler) (models.Traveler, error) {
+	err := r.db.Delete(&traveler).Error
+
+	return traveler, err
+}
+
+// FindTravelersByIds mengambil traveler milik user dengan id tertentu, dipakai saat membuat booking
+func (r *repository) FindTravelersByIds(UserId int, ids []int) ([]models.Traveler, error) {
+	var travelers []models.Traveler
+	err := r.db.Where("user_id = ? AND id IN ?", UserId, ids).Find(&travelers).Error
+
+	return travelers, err
+}
@@ -13,6 +13,7 @@ type TripRepository interface {
 	CreateTrip(trip models.Trip) (models.Trip, error)
 	UpdateTrip(trip models.Trip) (models.Trip, error)
 	DeleteTrip(trip models.Trip) (models.Trip, error)
+	GetExchangeRate(currency string) (models.ExchangeRate, error)
 }
 
 // membuat function RepositoryTrip. parameter pointer ke gorm, return repository{db}. ini akan dipanggil di routes
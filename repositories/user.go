@@ -2,26 +2,86 @@ package repositories
 
 import (
 	"project/models"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	FindUsers() ([]models.User, error)
+	FindUsers(filter UserFilter) ([]models.User, int64, error)
 	GetUser(Id int) (models.User, error)
+	GetUserByEmail(email string) (models.User, error)
 	UpdateUser(user models.User) (models.User, error)
 	DeleteUser(user models.User) (models.User, error)
+	SetEmailVerified(Id int, verifiedAt *time.Time) error
+	SuspendUser(Id int, reason string) error
+	ReactivateUser(Id int) error
+	UpdateRole(Id int, role string) error
+	RequirePasswordReset(Id int) error
+	CreatePasswordReset(reset models.PasswordReset) (models.PasswordReset, error)
+	FindTransactionsByUser(UserId int) ([]models.Transaction, error)
+	FindAuditLogsByUser(UserId int, offset int, limit int) ([]models.AuditLog, int64, error)
+	CreateAuditLog(log models.AuditLog) error
+}
+
+// status user yang bisa dipakai sebagai filter
+const (
+	UserStatusActive          = "active"
+	UserStatusSuspended       = "suspended"
+	UserStatusUnverified      = "unverified"
+	UserStatusPendingDeletion = "pending_deletion"
+	UserStatusDeleted         = "deleted"
+)
+
+// UserFilter adalah filter pencarian user untuk admin. Limit 0 berarti tanpa pagination
+type UserFilter struct {
+	Query  string
+	Role   string
+	Status string
+	Offset int
+	Limit  int
 }
 
 func RepositoryUser(db *gorm.DB) *repository {
 	return &repository{db}
 }
 
-func (r *repository) FindUsers() ([]models.User, error) {
+// FindUsers mencari user berdasarkan nama, email atau nomor telepon, lalu difilter role dan status
+func (r *repository) FindUsers(filter UserFilter) ([]models.User, int64, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("name LIKE ? OR email LIKE ? OR phone LIKE ?", like, like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	switch filter.Status {
+	case UserStatusActive:
+		query = query.Where("suspended_at IS NULL AND anonymized_at IS NULL")
+	case UserStatusSuspended:
+		query = query.Where("suspended_at IS NOT NULL")
+	case UserStatusUnverified:
+		query = query.Where("email_verified_at IS NULL AND anonymized_at IS NULL")
+	case UserStatusPendingDeletion:
+		query = query.Where("deletion_scheduled_at IS NOT NULL")
+	case UserStatusDeleted:
+		query = query.Where("anonymized_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit > 0 {
+		query = query.Offset(filter.Offset).Limit(filter.Limit)
+	}
+
 	var users []models.User
-	err := r.db.Find(&users).Error
+	err := query.Order("id").Find(&users).Error
 
-	return users, err
+	return users, total, err
 }
 
 func (r *repository) GetUser(Id int) (models.User, error) {
@@ -42,3 +102,44 @@ func (r *repository) DeleteUser(user models.User) (models.User, error) {
 
 	return user, err
 }
+
+// SuspendUser menonaktifkan akun dan mencabut semua sesinya
+func (r *repository) SuspendUser(Id int, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", Id).Updates(map[string]interface{}{
+			"suspended_at":     time.Now(),
+			"suspended_reason": reason,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return revokeAllSessions(tx, Id)
+	})
+}
+
+func (r *repository) ReactivateUser(Id int) error {
+	return r.db.Model(&models.User{}).Where("id = ?", Id).Updates(map[string]interface{}{
+		"suspended_at":     nil,
+		"suspended_reason": "",
+	}).Error
+}
+
+// UpdateRole mengganti role user. sesi dicabut karena role tersimpan di dalam token
+func (r *repository) UpdateRole(Id int, role string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", Id).Update("role", role).Error; err != nil {
+			return err
+		}
+		return revokeAllSessions(tx, Id)
+	})
+}
+
+// RequirePasswordReset mewajibkan user mengganti password sebelum login lagi dan mencabut semua sesinya
+func (r *repository) RequirePasswordReset(Id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", Id).Update("password_reset_required", true).Error; err != nil {
+			return err
+		}
+		return revokeAllSessions(tx, Id)
+	})
+}
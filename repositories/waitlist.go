@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WaitlistRepository interface {
+	FindWaitlistByUser(UserId int) ([]models.WaitlistEntry, error)
+	FindWaitlistByTrip(TripId int) ([]models.WaitlistEntry, error)
+	GetActiveWaitlistEntry(UserId int, TripId int) (models.WaitlistEntry, error)
+	CreateWaitlistEntry(entry models.WaitlistEntry) (models.WaitlistEntry, error)
+	UpdateWaitlistEntry(entry models.WaitlistEntry) (models.WaitlistEntry, error)
+	HeldWaitlistSeats(TripId int, ExceptUserId int) (int, error)
+	ExpireWaitlistOffers(now time.Time) ([]models.WaitlistEntry, error)
+	FindWaitlistedTripIds() ([]int, error)
+	FindWaitingEntries(TripId int) ([]models.WaitlistEntry, error)
+	WaitlistPosition(entry models.WaitlistEntry) (int, error)
+	GetTrip(ID int) (models.Trip, error)
+}
+
+func RepositoryWaitlist(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindWaitlistByUser(UserId int) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.db.Preload("Trip.Country").Preload("Trip").Where("user_id = ?", UserId).Order("created_at desc").Find(&entries).Error
+
+	return entries, err
+}
+
+// FindWaitlistByTrip menampilkan antrean yang masih aktif sesuai urutan
+func (r *repository) FindWaitlistByTrip(TripId int) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.db.Preload("User").Where("trip_id = ? AND status IN ?", TripId, []string{models.WaitlistWaiting, models.WaitlistOffered}).
+		Order("id").Find(&entries).Error
+
+	return entries, err
+}
+
+// GetActiveWaitlistEntry mengambil antrean user yang masih menunggu atau sedang mendapat penawaran
+func (r *repository) GetActiveWaitlistEntry(UserId int, TripId int) (models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.db.Preload("Trip.Country").Preload("Trip").
+		Where("user_id = ? AND trip_id = ? AND status IN ?", UserId, TripId, []string{models.WaitlistWaiting, models.WaitlistOffered}).
+		First(&entry).Error
+
+	return entry, err
+}
+
+func (r *repository) CreateWaitlistEntry(entry models.WaitlistEntry) (models.WaitlistEntry, error) {
+	err := r.db.Create(&entry).Error
+
+	return entry, err
+}
+
+func (r *repository) UpdateWaitlistEntry(entry models.WaitlistEntry) (models.WaitlistEntry, error) {
+	err := r.db.Model(&entry).Select("seats", "status", "offered_at", "offer_expires_at", "transaction_id").Updates(entry).Error
+
+	return entry, err
+}
+
+// HeldWaitlistSeats menghitung kursi yang sedang ditahan untuk penawaran waitlist yang belum lewat,
+// kursi milik ExceptUserId tidak dihitung
+func (r *repository) HeldWaitlistSeats(TripId int, ExceptUserId int) (int, error) {
+	return heldWaitlistSeats(r.db, TripId, ExceptUserId)
+}
+
+func heldWaitlistSeats(db *gorm.DB, TripId int, ExceptUserId int) (int, error) {
+	var seats int
+	err := db.Model(&models.WaitlistEntry{}).Select("COALESCE(SUM(seats), 0)").
+		Where("trip_id = ? AND status = ? AND offer_expires_at > ? AND user_id <> ?", TripId, models.WaitlistOffered, time.Now(), ExceptUserId).
+		Scan(&seats).Error
+
+	return seats, err
+}
+
+// ExpireWaitlistOffers menandai penawaran yang sudah lewat batas waktu dan mengembalikan datanya untuk dikirimi email
+func (r *repository) ExpireWaitlistOffers(now time.Time) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("User").Preload("Trip").Where("status = ? AND offer_expires_at <= ?", models.WaitlistOffered, now).Find(&entries).Error; err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := tx.Model(&entry).Update("status", models.WaitlistExpired).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return entries, err
+}
+
+func (r *repository) FindWaitlistedTripIds() ([]int, error) {
+	var ids []int
+	err := r.db.Model(&models.WaitlistEntry{}).Distinct("trip_id").Where("status = ?", models.WaitlistWaiting).Pluck("trip_id", &ids).Error
+
+	return ids, err
+}
+
+func (r *repository) FindWaitingEntries(TripId int) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.db.Preload("User").Preload("Trip").Where("trip_id = ? AND status = ?", TripId, models.WaitlistWaiting).Order("id").Find(&entries).Error
+
+	return entries, err
+}
+
+// WaitlistPosition menghitung urutan antrean yang masih menunggu, dimulai dari 1
+func (r *repository) WaitlistPosition(entry models.WaitlistEntry) (int, error) {
+	var ahead int64
+	err := r.db.Model(&models.WaitlistEntry{}).Where("trip_id = ? AND status = ? AND id < ?", entry.TripId, models.WaitlistWaiting, entry.Id).Count(&ahead).Error
+
+	return int(ahead) + 1, err
+}
+
+// MarkWaitlistBooked menandai antrean user untuk trip sebagai sudah booking, dipanggil setelah transaction dibuat
+func (r *repository) MarkWaitlistBooked(UserId int, TripId int, TransactionId int) error {
+	return r.db.Model(&models.WaitlistEntry{}).
+		Where("user_id = ? AND trip_id = ? AND status IN ?", UserId, TripId, []string{models.WaitlistWaiting, models.WaitlistOffered}).
+		Updates(map[string]interface{}{"status": models.WaitlistBooked, "transaction_id": TransactionId}).Error
+}
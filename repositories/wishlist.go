@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WishlistRepository interface {
+	FindWishlistsByUser(UserId int) ([]models.Wishlist, error)
+	GetWishlist(UserId int, TripId int) (models.Wishlist, error)
+	CreateWishlist(wishlist models.Wishlist) (models.Wishlist, error)
+	UpdateWishlist(wishlist models.Wishlist) (models.Wishlist, error)
+	DeleteWishlist(wishlist models.Wishlist) error
+	FindWishlistAlerts() ([]models.Wishlist, error)
+	SetWishlistPriceBaseline(Id int, price int) error
+	SetWishlistSellOutNotified(Id int, notifiedAt *time.Time) error
+	GetTrip(ID int) (models.Trip, error)
+}
+
+func RepositoryWishlist(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+// trip yang sudah dihapus tidak ikut ditampilkan
+func (r *repository) FindWishlistsByUser(UserId int) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := r.db.Preload("Trip.Country").Preload("Trip").
+		Where("user_id = ? AND trip_id IN (?)", UserId, r.db.Model(&models.Trip{}).Select("id")).
+		Order("created_at desc").Find(&wishlists).Error
+
+	return wishlists, err
+}
+
+func (r *repository) GetWishlist(UserId int, TripId int) (models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.db.Preload("Trip.Country").Preload("Trip").Where("user_id = ? AND trip_id = ?", UserId, TripId).First(&wishlist).Error
+
+	return wishlist, err
+}
+
+func (r *repository) CreateWishlist(wishlist models.Wishlist) (models.Wishlist, error) {
+	err := r.db.Create(&wishlist).Error
+
+	return wishlist, err
+}
+
+// Select dipakai agar nilai false pada pilihan notifikasi ikut tersimpan
+func (r *repository) UpdateWishlist(wishlist models.Wishlist) (models.Wishlist, error) {
+	err := r.db.Model(&wishlist).Select("notify_price_drop", "notify_sell_out", "price_baseline").Updates(wishlist).Error
+
+	return wishlist, err
+}
+
+func (r *repository) DeleteWishlist(wishlist models.Wishlist) error {
+	return r.db.Delete(&wishlist).Error
+}
+
+// FindWishlistAlerts mengambil wishlist yang meminta notifikasi, user yang disuspend atau dianonimkan dilewati
+func (r *repository) FindWishlistAlerts() ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := r.db.Preload("Trip").Preload("User").
+		Where("(notify_price_drop = ? OR notify_sell_out = ?)", true, true).
+		Where("trip_id IN (?)", r.db.Model(&models.Trip{}).Select("id")).
+		Where("user_id IN (?)", r.db.Model(&models.User{}).Select("id").Where("suspended_at IS NULL AND anonymized_at IS NULL")).
+		Find(&wishlists).Error
+
+	return wishlists, err
+}
+
+func (r *repository) SetWishlistPriceBaseline(Id int, price int) error {
+	return r.db.Model(&models.Wishlist{}).Where("id = ?", Id).Update("price_baseline", price).Error
+}
+
+func (r *repository) SetWishlistSellOutNotified(Id int, notifiedAt *time.Time) error {
+	return r.db.Model(&models.Wishlist{}).Where("id = ?", Id).Update("sell_out_notified_at", notifiedAt).Error
+}
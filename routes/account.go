@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func AccountRoutes(r *mux.Router) {
+	accountRoutes(r, repositories.RepositoryAccount(mysql.DB))
+}
+
+// function accountRoutes mendaftarkan route akun dengan repository yang diberikan, test memakai repository palsu
+func accountRoutes(r *mux.Router, accountRepository repositories.AccountRepository) {
+	h := handlers.HandlerAccount(accountRepository)
+
+	r.HandleFunc("/me/export", middleware.Auth(h.ExportAccount)).Methods("GET")
+	r.HandleFunc("/me", middleware.Auth(h.DeleteAccount)).Methods("DELETE")
+	r.HandleFunc("/me/deletion/cancel", middleware.Auth(h.CancelDeletion)).Methods("POST")
+}
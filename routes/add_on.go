@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func AddOnRoutes(r *mux.Router) {
+	addOnRepository := repositories.RepositoryAddOn(mysql.DB)
+	h := handlers.HandlerAddOn(addOnRepository)
+
+	r.HandleFunc("/trip/{id}/add_ons", h.FindAddOns).Methods("GET")
+	r.HandleFunc("/trip/{id}/add_on", middleware.Auth(middleware.Can(policy.TripWrite, h.CreateAddOn))).Methods("POST")
+	r.HandleFunc("/add_on/{id}", middleware.Auth(middleware.Can(policy.TripWrite, h.UpdateAddOn))).Methods("PATCH")
+	r.HandleFunc("/add_on/{id}", middleware.Auth(middleware.Can(policy.TripWrite, h.DeleteAddOn))).Methods("DELETE")
+}
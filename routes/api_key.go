@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+// route api key tidak terdaftar di policy.RouteScope, sehingga hanya bisa dipanggil dengan token login (bukan api key)
+func APIKeyRoutes(r *mux.Router) {
+	apiKeyRepository := repositories.RepositoryAPIKey(mysql.DB)
+	h := handlers.HandlerAPIKey(apiKeyRepository)
+
+	r.HandleFunc("/api_keys", middleware.Auth(h.FindAPIKeys)).Methods("GET")
+	r.HandleFunc("/api_keys", middleware.Auth(h.CreateAPIKey)).Methods("POST")
+	r.HandleFunc("/api_key/{id}", middleware.Auth(h.RevokeAPIKey)).Methods("DELETE")
+}
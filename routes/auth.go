@@ -1,20 +1,58 @@
 package routes
 
 import (
+	"os"
 	"project/handlers"
+	jwtToken "project/pkg/jwt"
+	"project/pkg/loginguard"
 	"project/pkg/middleware"
 	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/pkg/ratelimit"
 	"project/repositories"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 func AuthRoutes(r *mux.Router) {
-	authRepository := repositories.RepositoryAuth(mysql.DB)
-	h := handlers.HandlerAuth(authRepository)
+	authRoutes(r, repositories.RepositoryAuth(mysql.DB), newLoginGuard())
+}
+
+// function authRoutes mendaftarkan route auth dengan repository yang diberikan, test memakai repository palsu
+func authRoutes(r *mux.Router, authRepository repositories.AuthRepository, guard *loginguard.Guard) {
+	h := handlers.HandlerAuth(authRepository, guard)
+
+	// batas percobaan kode 2FA per ip
+	mfaLimiter := ratelimit.New(20, time.Minute*5)
 
 	r.HandleFunc("/register", h.Register).Methods("POST")
-	r.HandleFunc("/register_admin", h.RegisterAdmin).Methods("POST")
+	// admin baru hanya bisa didaftarkan oleh admin yang boleh mengatur role user
+	r.HandleFunc("/register_admin", middleware.Auth(middleware.Can(policy.UserRoleAny, h.RegisterAdmin))).Methods("POST")
 	r.HandleFunc("/login", h.Login).Methods("POST")
+	r.HandleFunc("/login/2fa", middleware.RateLimit(mfaLimiter, h.LoginMFA)).Methods("POST")
 	r.HandleFunc("/check_auth", middleware.Auth(h.CheckAuth)).Methods("GET")
+	r.HandleFunc("/verify_email", h.VerifyEmail).Methods("GET")
+	r.HandleFunc("/resend_verification", middleware.Auth(h.ResendVerification)).Methods("POST")
+
+	// two-factor authentication, setup dan enable juga bisa dipanggil dengan mfa_token dari login (role wajib 2FA)
+	r.HandleFunc("/2fa/setup", middleware.AuthOrPurpose(jwtToken.PurposeMFAEnroll, h.SetupTotp)).Methods("POST")
+	r.HandleFunc("/2fa/enable", middleware.AuthOrPurpose(jwtToken.PurposeMFAEnroll, h.EnableTotp)).Methods("POST")
+	r.HandleFunc("/2fa/disable", middleware.Auth(h.DisableTotp)).Methods("POST")
+	r.HandleFunc("/2fa/recovery_codes", middleware.Auth(h.RegenerateRecoveryCodes)).Methods("POST")
+
+	// login dengan OpenID Connect, provider dikonfigurasi lewat env OIDC_<PROVIDER>_*
+	r.HandleFunc("/oidc/{provider}/login", h.OIDCLogin).Methods("GET")
+	r.HandleFunc("/oidc/{provider}/callback", h.OIDCCallback).Methods("GET")
+
+	r.HandleFunc("/user/{id}/unlock", middleware.Auth(middleware.Can(policy.UserUpdateAny, h.UnlockUser))).Methods("POST")
+	r.HandleFunc("/user/{id}/impersonate", middleware.Auth(middleware.Can(policy.UserImpersonate, h.Impersonate))).Methods("POST")
+}
+
+// catatan gagal login disimpan di memory (default, satu server) atau database jika LOGIN_GUARD_STORE=database (beberapa server)
+func newLoginGuard() *loginguard.Guard {
+	if os.Getenv("LOGIN_GUARD_STORE") == "database" {
+		return loginguard.New(loginguard.NewDatabaseStore(mysql.DB))
+	}
+	return loginguard.New(loginguard.NewMemoryStore())
 }
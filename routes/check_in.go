@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func CheckInRoutes(r *mux.Router) {
+	checkInRoutes(r, repositories.RepositoryCheckIn(mysql.DB))
+}
+
+// function checkInRoutes mendaftarkan route check-in dengan repository yang diberikan, test memakai repository palsu
+func checkInRoutes(r *mux.Router, checkInRepository repositories.CheckInRepository) {
+	h := handlers.HandlerCheckIn(checkInRepository)
+
+	r.HandleFunc("/trip/{id}/check_in", middleware.Auth(middleware.Can(policy.CheckInWrite, h.CheckIn))).Methods("POST")
+	r.HandleFunc("/trip/{id}/check_ins", middleware.Auth(middleware.Can(policy.CheckInWrite, h.CheckInBatch))).Methods("POST")
+	r.HandleFunc("/trip/{id}/roster", middleware.Auth(middleware.Can(policy.RosterRead, h.GetRoster))).Methods("GET")
+}
@@ -4,6 +4,7 @@ import (
 	"project/handlers"
 	"project/pkg/middleware"
 	"project/pkg/mysql"
+	"project/pkg/policy"
 	"project/repositories"
 
 	"github.com/gorilla/mux"
@@ -15,7 +16,7 @@ func CountryRoutes(r *mux.Router) {
 
 	r.HandleFunc("/countries", h.FindCountries).Methods("GET")
 	r.HandleFunc("/country/{id}", h.GetCountry).Methods("GET")
-	r.HandleFunc("/country", middleware.AuthAdmin(h.CreateCountry)).Methods("POST")
-	r.HandleFunc("/country/{id}", middleware.AuthAdmin(h.UpdateCountry)).Methods("PATCH")
-	r.HandleFunc("/country/{id}", middleware.AuthAdmin(h.DeleteCountry)).Methods("DELETE")
+	r.HandleFunc("/country", middleware.Auth(middleware.Can(policy.CountryWrite, h.CreateCountry))).Methods("POST")
+	r.HandleFunc("/country/{id}", middleware.Auth(middleware.Can(policy.CountryWrite, h.UpdateCountry))).Methods("PATCH")
+	r.HandleFunc("/country/{id}", middleware.Auth(middleware.Can(policy.CountryWrite, h.DeleteCountry))).Methods("DELETE")
 }
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/pkg/vault"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func DocumentRoutes(r *mux.Router) {
+	documentRoutes(r, repositories.RepositoryDocument(mysql.DB))
+}
+
+// function documentRoutes mendaftarkan route dokumen dengan repository yang diberikan, test memakai repository palsu
+func documentRoutes(r *mux.Router, documentRepository repositories.DocumentRepository) {
+	h := handlers.HandlerDocument(documentRepository, vault.FromEnv())
+
+	r.HandleFunc("/documents", middleware.Auth(h.FindDocuments)).Methods("GET")
+	r.HandleFunc("/documents", middleware.Auth(h.UploadDocument)).Methods("POST")
+	r.HandleFunc("/document/{id}/url", middleware.Auth(h.CreateDocumentURL)).Methods("POST")
+	r.HandleFunc("/document/{id}/access_logs", middleware.Auth(h.FindDocumentAccessLogs)).Methods("GET")
+	r.HandleFunc("/document/{id}", middleware.Auth(h.DeleteDocument)).Methods("DELETE")
+	r.HandleFunc("/user/{id}/documents", middleware.Auth(middleware.Can(policy.DocumentReadAny, h.FindUserDocuments))).Methods("GET")
+
+	// dipanggil dari link yang dibuat /document/{id}/url, tanpa token login
+	r.HandleFunc("/document/{id}/download", h.DownloadDocument).Methods("GET")
+}
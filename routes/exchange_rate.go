@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func ExchangeRateRoutes(r *mux.Router) {
+	exchangeRateRepository := repositories.RepositoryExchangeRate(mysql.DB)
+	h := handlers.HandlerExchangeRate(exchangeRateRepository)
+
+	r.HandleFunc("/exchange_rates", h.FindExchangeRates).Methods("GET")
+	r.HandleFunc("/exchange_rates", middleware.Auth(middleware.Can(policy.ExchangeRateManage, h.UpdateExchangeRates))).Methods("PUT")
+	r.HandleFunc("/exchange_rate/{currency}", middleware.Auth(middleware.Can(policy.ExchangeRateManage, h.DeleteExchangeRate))).Methods("DELETE")
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/ratelimit"
+	"project/repositories"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func PasswordRoutes(r *mux.Router) {
+	passwordRepository := repositories.RepositoryPassword(mysql.DB)
+	h := handlers.HandlerPassword(passwordRepository)
+
+	// batas request per ip
+	forgotLimiter := ratelimit.New(5, time.Minute*15)
+	resetLimiter := ratelimit.New(10, time.Minute*15)
+
+	r.HandleFunc("/password/forgot", middleware.RateLimit(forgotLimiter, h.ForgotPassword)).Methods("POST")
+	r.HandleFunc("/password/reset", middleware.RateLimit(resetLimiter, h.ResetPassword)).Methods("POST")
+	r.HandleFunc("/password/change", middleware.Auth(h.ChangePassword)).Methods("POST")
+}
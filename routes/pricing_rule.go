@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func PricingRuleRoutes(r *mux.Router) {
+	pricingRuleRepository := repositories.RepositoryPricingRule(mysql.DB)
+	h := handlers.HandlerPricingRule(pricingRuleRepository)
+
+	r.HandleFunc("/trip/{id}/quote", middleware.Auth(h.Quote)).Methods("POST")
+	r.HandleFunc("/trip/{id}/pricing_rules", middleware.Auth(middleware.Can(policy.TripWrite, h.FindPricingRules))).Methods("GET")
+	r.HandleFunc("/trip/{id}/pricing_rule", middleware.Auth(middleware.Can(policy.TripWrite, h.CreatePricingRule))).Methods("POST")
+	r.HandleFunc("/pricing_rule/{id}", middleware.Auth(middleware.Can(policy.TripWrite, h.UpdatePricingRule))).Methods("PATCH")
+	r.HandleFunc("/pricing_rule/{id}", middleware.Auth(middleware.Can(policy.TripWrite, h.DeletePricingRule))).Methods("DELETE")
+}
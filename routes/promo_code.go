@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func PromoCodeRoutes(r *mux.Router) {
+	promoCodeRepository := repositories.RepositoryPromoCode(mysql.DB)
+	h := handlers.HandlerPromoCode(promoCodeRepository)
+
+	r.HandleFunc("/promo_codes", middleware.Auth(middleware.Can(policy.PromoCodeManage, h.FindPromoCodes))).Methods("GET")
+	r.HandleFunc("/promo_code/{id}", middleware.Auth(middleware.Can(policy.PromoCodeManage, h.GetPromoCode))).Methods("GET")
+	r.HandleFunc("/promo_code", middleware.Auth(middleware.Can(policy.PromoCodeManage, h.CreatePromoCode))).Methods("POST")
+	r.HandleFunc("/promo_code/{id}", middleware.Auth(middleware.Can(policy.PromoCodeManage, h.UpdatePromoCode))).Methods("PATCH")
+	r.HandleFunc("/promo_code/{id}", middleware.Auth(middleware.Can(policy.PromoCodeManage, h.DeletePromoCode))).Methods("DELETE")
+}
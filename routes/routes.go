@@ -7,8 +7,22 @@ import (
 // membuat function RouteInit untuk membuat route ke masing-masing route
 func RouteInit(r *mux.Router) {
 	AuthRoutes(r)
+	PasswordRoutes(r)
+	SessionRoutes(r)
+	APIKeyRoutes(r)
+	AccountRoutes(r)
 	UserRoutes(r)
+	TravelerRoutes(r)
+	DocumentRoutes(r)
 	CountryRoutes(r)
 	TripRoutes(r)
+	PricingRuleRoutes(r)
+	AddOnRoutes(r)
+	WishlistRoutes(r)
+	WaitlistRoutes(r)
+	CheckInRoutes(r)
 	TransactionRoutes(r)
+	PromoCodeRoutes(r)
+	ExchangeRateRoutes(r)
+	TaxRuleRoutes(r)
 }
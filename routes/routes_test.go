@@ -0,0 +1,235 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"project/models"
+	jwtToken "project/pkg/jwt"
+	"project/pkg/loginguard"
+	"project/pkg/middleware"
+	"project/repositories"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// transaction 1 dan dokumen 1 milik user 2 (owner)
+type fakeTransactionRepository struct {
+	repositories.TransactionRepository
+}
+
+func (fakeTransactionRepository) GetTransaction(id int) (models.Transaction, error) {
+	if id != 1 {
+		return models.Transaction{}, gorm.ErrRecordNotFound
+	}
+	return models.Transaction{Id: 1, UserId: 2, Status: "success"}, nil
+}
+
+type fakeDocumentRepository struct {
+	repositories.DocumentRepository
+}
+
+func (fakeDocumentRepository) GetDocument(id int) (models.Document, error) {
+	if id != 1 {
+		return models.Document{}, gorm.ErrRecordNotFound
+	}
+	return models.Document{Id: 1, UserId: 2}, nil
+}
+
+// function testRouter mendaftarkan route asli dengan repository palsu. repository palsu hanya mengisi data yang dibutuhkan
+// untuk keputusan akses (pemilik transaction dan dokumen), method lain panic karena interface-nya nil. panic berarti
+// request sudah lolos pengecekan akses dan sampai ke handler
+func testRouter() *mux.Router {
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api/v1").Subrouter()
+
+	authRoutes(api, struct{ repositories.AuthRepository }{}, loginguard.New(loginguard.NewMemoryStore()))
+	accountRoutes(api, struct{ repositories.AccountRepository }{})
+	userRoutes(api, struct{ repositories.UserRepository }{})
+	documentRoutes(api, fakeDocumentRepository{})
+	tripRoutes(api, struct{ repositories.TripRepository }{})
+	checkInRoutes(api, struct{ repositories.CheckInRepository }{})
+	transactionRoutes(api, fakeTransactionRepository{})
+	return r
+}
+
+// reached menandai request yang sampai ke handler (lolos Auth dan pengecekan akses)
+const reached = -1
+
+func serve(router http.Handler, req *http.Request) (code int) {
+	w := httptest.NewRecorder()
+	defer func() {
+		if recover() != nil {
+			code = reached
+		}
+	}()
+	router.ServeHTTP(w, req)
+	if w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden {
+		return w.Code
+	}
+	return reached
+}
+
+func loginToken(t *testing.T, id int, role string) string {
+	token, err := jwtToken.GenerateToken(&jwt.MapClaims{"id": id, "role": role, "exp": time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRouteAuthorization(t *testing.T) {
+	// sesi tidak dicek ke database, token yang valid dianggap masih aktif
+	sessionCheck := middleware.SessionCheck
+	middleware.SessionCheck = func(jwt.MapClaims) error { return nil }
+	t.Cleanup(func() { middleware.SessionCheck = sessionCheck })
+
+	type expect struct{ anonymous, owner, other, guide, admin int }
+	const (
+		unauthorized = http.StatusUnauthorized
+		forbidden    = http.StatusForbidden
+		allowed      = reached
+	)
+	var (
+		anyUser   = expect{unauthorized, allowed, allowed, allowed, allowed}
+		adminOnly = expect{unauthorized, forbidden, forbidden, forbidden, allowed}
+		ownerOnly = expect{unauthorized, allowed, forbidden, forbidden, allowed}
+		staff     = expect{unauthorized, forbidden, forbidden, allowed, allowed}
+	)
+
+	tests := []struct {
+		method string
+		path   string
+		want   expect
+	}{
+		// user
+		{"POST", "/register_admin", adminOnly},
+		{"GET", "/check_auth", anyUser},
+		{"POST", "/resend_verification", anyUser},
+		{"GET", "/users", adminOnly},
+		{"GET", "/user", anyUser},
+		{"PATCH", "/user/2", ownerOnly},
+		{"DELETE", "/user/2", adminOnly},
+		{"POST", "/user/2/suspend", adminOnly},
+		{"POST", "/user/2/reactivate", adminOnly},
+		{"PATCH", "/user/2/role", adminOnly},
+		{"POST", "/user/2/password_reset", adminOnly},
+		{"POST", "/user/2/unlock", adminOnly},
+		{"POST", "/user/2/impersonate", adminOnly},
+		{"GET", "/user/2/transactions", adminOnly},
+		{"GET", "/user/2/activity", adminOnly},
+		{"GET", "/user/2/documents", adminOnly},
+		{"POST", "/2fa/setup", anyUser},
+		{"POST", "/2fa/enable", anyUser},
+		{"POST", "/2fa/disable", anyUser},
+		{"POST", "/2fa/recovery_codes", anyUser},
+		{"GET", "/me/export", anyUser},
+		{"DELETE", "/me", anyUser},
+		{"POST", "/me/deletion/cancel", anyUser},
+
+		// transaction
+		{"GET", "/transactions", adminOnly},
+		{"GET", "/transactions/report", adminOnly},
+		{"GET", "/transactionsbyuser", anyUser},
+		{"POST", "/transaction", anyUser},
+		{"GET", "/transaction/1", ownerOnly},
+		{"PATCH", "/transaction/1", ownerOnly},
+		{"DELETE", "/transaction/1", ownerOnly},
+		{"GET", "/transaction/1/history", ownerOnly},
+		{"GET", "/transaction/1/invoice", ownerOnly},
+		{"GET", "/transaction/1/ticket", ownerOnly},
+		{"POST", "/transaction/1/reschedule", ownerOnly},
+
+		// upload dan dokumen
+		{"POST", "/trip", adminOnly},
+		{"PATCH", "/trip/1", adminOnly},
+		{"DELETE", "/trip/1", adminOnly},
+		{"GET", "/documents", anyUser},
+		{"POST", "/documents", anyUser},
+		{"POST", "/document/1/url", ownerOnly},
+		{"GET", "/document/1/access_logs", ownerOnly},
+		{"DELETE", "/document/1", ownerOnly},
+
+		// check-in
+		{"POST", "/trip/1/check_in", staff},
+		{"POST", "/trip/1/check_ins", staff},
+		{"GET", "/trip/1/roster", staff},
+	}
+
+	callers := []struct {
+		name  string
+		token string
+		want  func(expect) int
+	}{
+		{"anonymous", "", func(e expect) int { return e.anonymous }},
+		{"owner", loginToken(t, 2, "user"), func(e expect) int { return e.owner }},
+		{"other user", loginToken(t, 3, "user"), func(e expect) int { return e.other }},
+		{"guide", loginToken(t, 4, "guide"), func(e expect) int { return e.guide }},
+		{"admin", loginToken(t, 1, "admin"), func(e expect) int { return e.admin }},
+	}
+
+	router := testRouter()
+	for _, tt := range tests {
+		for _, caller := range callers {
+			t.Run(tt.method+" "+tt.path+" as "+caller.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, "/api/v1"+tt.path, nil)
+				if caller.token != "" {
+					req.Header.Set("Authorization", "Bearer "+caller.token)
+				}
+
+				got := serve(router, req)
+				if want := caller.want(tt.want); got != want {
+					t.Errorf("status = %d, want %d (-1 means the request reached the handler)", got, want)
+				}
+			})
+		}
+	}
+}
+
+// setiap route user, transaction dan upload yang membutuhkan login harus ada di matrix di atas
+func TestRouteAuthorizationCoversRoutes(t *testing.T) {
+	covered := map[string]bool{}
+	for _, route := range []string{
+		"POST /register_admin", "GET /check_auth", "POST /resend_verification", "GET /users", "GET /user",
+		"PATCH /user/{id}", "DELETE /user/{id}", "POST /user/{id}/suspend", "POST /user/{id}/reactivate",
+		"PATCH /user/{id}/role", "POST /user/{id}/password_reset", "POST /user/{id}/unlock", "POST /user/{id}/impersonate",
+		"GET /user/{id}/transactions", "GET /user/{id}/activity", "GET /user/{id}/documents", "POST /2fa/setup",
+		"POST /2fa/enable", "POST /2fa/disable", "POST /2fa/recovery_codes", "GET /me/export", "DELETE /me",
+		"POST /me/deletion/cancel", "GET /transactions", "GET /transactions/report", "GET /transactionsbyuser",
+		"POST /transaction", "GET /transaction/{id}", "PATCH /transaction/{id_transaction}", "DELETE /transaction/{id}",
+		"GET /transaction/{id}/history", "GET /transaction/{id}/invoice", "GET /transaction/{id}/ticket",
+		"POST /transaction/{id}/reschedule", "POST /trip", "PATCH /trip/{id}", "DELETE /trip/{id}", "GET /documents",
+		"POST /documents", "POST /document/{id}/url", "GET /document/{id}/access_logs", "DELETE /document/{id}",
+		"POST /trip/{id}/check_in", "POST /trip/{id}/check_ins", "GET /trip/{id}/roster",
+	} {
+		covered[route] = true
+	}
+
+	// route tanpa login (login, callback, notifikasi midtrans, katalog trip) tidak perlu ada di matrix
+	public := map[string]bool{
+		"POST /register": true, "POST /login": true, "POST /login/2fa": true, "GET /verify_email": true,
+		"GET /oidc/{provider}/login": true, "GET /oidc/{provider}/callback": true, "POST /notification": true,
+		"GET /trips": true, "GET /trip/{id}": true, "GET /document/{id}/download": true,
+	}
+
+	testRouter().Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			key := method + " " + template[len("/api/v1"):]
+			if !covered[key] && !public[key] {
+				t.Errorf("route %s is not in the authorization matrix", key)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func SessionRoutes(r *mux.Router) {
+	sessionRepository := repositories.RepositorySession(mysql.DB)
+	h := handlers.HandlerSession(sessionRepository)
+
+	r.HandleFunc("/sessions", middleware.Auth(h.FindSessions)).Methods("GET")
+	r.HandleFunc("/sessions/others", middleware.Auth(h.RevokeOtherSessions)).Methods("DELETE")
+	r.HandleFunc("/session/{id}", middleware.Auth(h.RevokeSession)).Methods("DELETE")
+	r.HandleFunc("/user/{id}/sessions", middleware.Auth(middleware.Can(policy.SessionRevokeAny, h.RevokeUserSessions))).Methods("DELETE")
+}
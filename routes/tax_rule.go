@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func TaxRuleRoutes(r *mux.Router) {
+	taxRuleRepository := repositories.RepositoryTaxRule(mysql.DB)
+	h := handlers.HandlerTaxRule(taxRuleRepository)
+
+	r.HandleFunc("/tax_rules", middleware.Auth(middleware.Can(policy.TaxRuleManage, h.FindTaxRules))).Methods("GET")
+	r.HandleFunc("/tax_rule", middleware.Auth(middleware.Can(policy.TaxRuleManage, h.CreateTaxRule))).Methods("POST")
+	r.HandleFunc("/tax_rule/{id}", middleware.Auth(middleware.Can(policy.TaxRuleManage, h.UpdateTaxRule))).Methods("PATCH")
+	r.HandleFunc("/tax_rule/{id}", middleware.Auth(middleware.Can(policy.TaxRuleManage, h.DeleteTaxRule))).Methods("DELETE")
+}
@@ -4,18 +4,28 @@ import (
 	"project/handlers"
 	"project/pkg/middleware"
 	"project/pkg/mysql"
+	"project/pkg/policy"
 	"project/repositories"
 
 	"github.com/gorilla/mux"
 )
 
 func TransactionRoutes(r *mux.Router) {
-	transactionRepository := repositories.RepositoryTransaction(mysql.DB)
+	transactionRoutes(r, repositories.RepositoryTransaction(mysql.DB))
+}
+
+// function transactionRoutes mendaftarkan route transaction dengan repository yang diberikan, test memakai repository palsu
+func transactionRoutes(r *mux.Router, transactionRepository repositories.TransactionRepository) {
 	h := handlers.HandlerTransaction(transactionRepository)
 
-	r.HandleFunc("/transactions", h.FindTransactions).Methods("GET")
+	r.HandleFunc("/transactions", middleware.Auth(middleware.Can(policy.TransactionReadAny, h.FindTransactions))).Methods("GET")
+	r.HandleFunc("/transactions/report", middleware.Auth(middleware.Can(policy.TransactionReadAny, h.TransactionReport))).Methods("GET")
 	r.HandleFunc("/transactionsbyuser", middleware.Auth(h.GetAllTransactionByUser)).Methods("GET")
-	r.HandleFunc("/transaction/{id}", h.GetTransaction).Methods("GET")
+	r.HandleFunc("/transaction/{id}", middleware.Auth(h.GetTransaction)).Methods("GET")
+	r.HandleFunc("/transaction/{id}/history", middleware.Auth(h.GetTransactionHistory)).Methods("GET")
+	r.HandleFunc("/transaction/{id}/invoice", middleware.Auth(h.GetInvoice)).Methods("GET")
+	r.HandleFunc("/transaction/{id}/ticket", middleware.Auth(h.GetTicket)).Methods("GET")
+	r.HandleFunc("/transaction/{id}/reschedule", middleware.Auth(h.RescheduleTransaction)).Methods("POST")
 	r.HandleFunc("/transaction", middleware.Auth(h.CreateTransaction)).Methods("POST")
 	r.HandleFunc("/notification", h.Notification).Methods("POST")
 	r.HandleFunc("/transaction/{id_transaction}", middleware.Auth(h.UpdateTransaction)).Methods("PATCH")
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func TravelerRoutes(r *mux.Router) {
+	travelerRepository := repositories.RepositoryTraveler(mysql.DB)
+	h := handlers.HandlerTraveler(travelerRepository)
+
+	r.HandleFunc("/travelers", middleware.Auth(h.FindTravelers)).Methods("GET")
+	r.HandleFunc("/traveler/{id}", middleware.Auth(h.GetTraveler)).Methods("GET")
+	r.HandleFunc("/traveler", middleware.Auth(h.CreateTraveler)).Methods("POST")
+	r.HandleFunc("/traveler/{id}", middleware.Auth(h.UpdateTraveler)).Methods("PATCH")
+	r.HandleFunc("/traveler/{id}", middleware.Auth(h.DeleteTraveler)).Methods("DELETE")
+}
@@ -4,6 +4,7 @@ import (
 	"project/handlers"
 	"project/pkg/middleware"
 	"project/pkg/mysql"
+	"project/pkg/policy"
 	"project/repositories"
 
 	"github.com/gorilla/mux"
@@ -13,12 +14,17 @@ func TripRoutes(r *mux.Router) {
 	// panggil repositoryTrip isikan parameter mywal.DB dan simpan ke dalam variabel
 	TripRepository := repositories.RepositoriyTrip(mysql.DB)
 
+	tripRoutes(r, TripRepository)
+}
+
+// function tripRoutes mendaftarkan route trip dengan repository yang diberikan, test memakai repository palsu
+func tripRoutes(r *mux.Router, TripRepository repositories.TripRepository) {
 	// panggil HandlerTrip dari (handlers/trip)
 	h := handlers.HandlerTrip(TripRepository)
 
 	r.HandleFunc("/trips", h.FindTrips).Methods("GET")
 	r.HandleFunc("/trip/{id}", h.GetTrip).Methods("GET")
-	r.HandleFunc("/trip", middleware.Auth(middleware.UploadFile(h.CreateTrip))).Methods("POST")
-	r.HandleFunc("/trip/{id}", middleware.AuthAdmin(middleware.UploadFile(h.UpdateTrip))).Methods("PATCH")
-	r.HandleFunc("/trip/{id}", middleware.AuthAdmin(h.DeleteTrip)).Methods("DELETE")
+	r.HandleFunc("/trip", middleware.Auth(middleware.Can(policy.TripWrite, middleware.UploadFile(h.CreateTrip)))).Methods("POST")
+	r.HandleFunc("/trip/{id}", middleware.Auth(middleware.Can(policy.TripWrite, middleware.UploadFile(h.UpdateTrip)))).Methods("PATCH")
+	r.HandleFunc("/trip/{id}", middleware.Auth(middleware.Can(policy.TripWrite, h.DeleteTrip))).Methods("DELETE")
 }
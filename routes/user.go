@@ -4,17 +4,30 @@ import (
 	"project/handlers"
 	"project/pkg/middleware"
 	"project/pkg/mysql"
+	"project/pkg/policy"
 	"project/repositories"
 
 	"github.com/gorilla/mux"
 )
 
 func UserRoutes(r *mux.Router) {
-	userRepository := repositories.RepositoryUser(mysql.DB)
+	userRoutes(r, repositories.RepositoryUser(mysql.DB))
+}
+
+// function userRoutes mendaftarkan route user dengan repository yang diberikan, test memakai repository palsu
+func userRoutes(r *mux.Router, userRepository repositories.UserRepository) {
 	h := handlers.HandlerUser(userRepository)
 
-	r.HandleFunc("/users", h.FindUsers).Methods("GET")
+	r.HandleFunc("/users", middleware.Auth(middleware.Can(policy.UserReadAny, h.FindUsers))).Methods("GET")
 	r.HandleFunc("/user", middleware.Auth(h.GetUser)).Methods("GET")
-	r.HandleFunc("/user/{id}", middleware.Auth(middleware.UploadFile(h.UpdateUser))).Methods("PATCH")
-	r.HandleFunc("/user/{id}", middleware.AuthAdmin(h.DeleteUser)).Methods("DELETE")
+	r.HandleFunc("/user/{id}", middleware.Auth(middleware.OwnerOr(policy.UserUpdateAny, "id", middleware.UploadFile(h.UpdateUser)))).Methods("PATCH")
+	r.HandleFunc("/user/{id}", middleware.Auth(middleware.Can(policy.UserDeleteAny, h.DeleteUser))).Methods("DELETE")
+
+	// manajemen user oleh admin
+	r.HandleFunc("/user/{id}/suspend", middleware.Auth(middleware.Can(policy.UserUpdateAny, h.SuspendUser))).Methods("POST")
+	r.HandleFunc("/user/{id}/reactivate", middleware.Auth(middleware.Can(policy.UserUpdateAny, h.ReactivateUser))).Methods("POST")
+	r.HandleFunc("/user/{id}/role", middleware.Auth(middleware.Can(policy.UserRoleAny, h.UpdateRole))).Methods("PATCH")
+	r.HandleFunc("/user/{id}/password_reset", middleware.Auth(middleware.Can(policy.UserUpdateAny, h.ForcePasswordReset))).Methods("POST")
+	r.HandleFunc("/user/{id}/transactions", middleware.Auth(middleware.Can(policy.TransactionReadAny, h.FindUserTransactions))).Methods("GET")
+	r.HandleFunc("/user/{id}/activity", middleware.Auth(middleware.Can(policy.AuditReadAny, h.FindUserActivity))).Methods("GET")
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/pkg/policy"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func WaitlistRoutes(r *mux.Router) {
+	waitlistRepository := repositories.RepositoryWaitlist(mysql.DB)
+	h := handlers.HandlerWaitlist(waitlistRepository)
+
+	r.HandleFunc("/waitlist", middleware.Auth(h.FindWaitlist)).Methods("GET")
+	r.HandleFunc("/trip/{id}/waitlist", middleware.Auth(h.JoinWaitlist)).Methods("POST")
+	r.HandleFunc("/trip/{id}/waitlist", middleware.Auth(h.LeaveWaitlist)).Methods("DELETE")
+	r.HandleFunc("/trip/{id}/waitlist", middleware.Auth(middleware.Can(policy.WaitlistReadAny, h.FindTripWaitlist))).Methods("GET")
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"project/handlers"
+	"project/pkg/middleware"
+	"project/pkg/mysql"
+	"project/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func WishlistRoutes(r *mux.Router) {
+	wishlistRepository := repositories.RepositoryWishlist(mysql.DB)
+	h := handlers.HandlerWishlist(wishlistRepository)
+
+	r.HandleFunc("/wishlist", middleware.Auth(h.FindWishlist)).Methods("GET")
+	r.HandleFunc("/wishlist", middleware.Auth(h.AddWishlist)).Methods("POST")
+	r.HandleFunc("/wishlist/{trip_id}", middleware.Auth(h.UpdateWishlist)).Methods("PATCH")
+	r.HandleFunc("/wishlist/{trip_id}", middleware.Auth(h.DeleteWishlist)).Methods("DELETE")
+}